@@ -0,0 +1,184 @@
+// Package bridge forwards topics from one broker to another.
+//
+// It's meant for running two brokers side by side e.g. while migrating from
+// one to the other. Messages are only acked on the source once they've been
+// published to the target so a failed publish is redelivered by the source.
+package bridge
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/util/backoff"
+)
+
+var (
+	DefaultName    = "go.micro.bridge"
+	DefaultRetries = 3
+
+	// BridgeHeader lists the bridges a message was forwarded by
+	BridgeHeader = "Micro-Bridge"
+
+	// ErrLoop is returned for messages already forwarded by the bridge
+	ErrLoop = errors.New("message already forwarded by the bridge")
+)
+
+// Bridge forwards messages from the source broker to the target broker
+type Bridge struct {
+	opts   Options
+	source broker.Broker
+	target broker.Broker
+
+	sync.Mutex
+	subs []broker.Subscriber
+	// sleep between retries, replaced in tests
+	backoff func(attempt int) time.Duration
+}
+
+// NewBridge returns a bridge forwarding the routes from source to target
+func NewBridge(source, target broker.Broker, opts ...Option) *Bridge {
+	options := Options{
+		Name:    DefaultName,
+		Retries: DefaultRetries,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Queue) == 0 {
+		options.Queue = options.Name
+	}
+
+	return &Bridge{
+		opts:    options,
+		source:  source,
+		target:  target,
+		backoff: backoff.Do,
+	}
+}
+
+// Options returns the options of the bridge
+func (b *Bridge) Options() Options {
+	return b.opts
+}
+
+// Start connects the brokers and subscribes to the source topics
+func (b *Bridge) Start() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.subs) > 0 {
+		return nil
+	}
+	if err := b.target.Connect(); err != nil {
+		return err
+	}
+	if err := b.source.Connect(); err != nil {
+		return err
+	}
+
+	for _, r := range b.opts.Routes {
+		target := r.Target
+		if len(target) == 0 {
+			target = r.Source
+		}
+
+		sub, err := b.source.Subscribe(r.Source, b.handler(target),
+			broker.Queue(b.opts.Queue),
+			broker.DisableAutoAck(),
+		)
+		if err != nil {
+			for _, s := range b.subs {
+				s.Unsubscribe()
+			}
+			b.subs = nil
+			return err
+		}
+		b.subs = append(b.subs, sub)
+	}
+
+	return nil
+}
+
+// Stop unsubscribes from the source topics. The brokers are left connected.
+func (b *Bridge) Stop() error {
+	b.Lock()
+	defer b.Unlock()
+
+	var err error
+	for _, s := range b.subs {
+		if e := s.Unsubscribe(); e != nil {
+			err = e
+		}
+	}
+	b.subs = nil
+	return err
+}
+
+func (b *Bridge) handler(target string) broker.Handler {
+	return func(e broker.Event) error {
+		m, err := b.forward(e.Message())
+		if err == ErrLoop {
+			// drop it, the other side already has it
+			return e.Ack()
+		} else if err != nil {
+			return err
+		}
+
+		var opts []broker.PublishOption
+		if k := m.Header[broker.OrderingKeyHeader]; len(k) > 0 {
+			opts = append(opts, broker.OrderingKey(k))
+		}
+
+		topic := target
+		if b.opts.Rewrite != nil {
+			b.opts.Rewrite(topic, m)
+		}
+
+		for i := 0; i <= b.opts.Retries; i++ {
+			if i > 0 {
+				time.Sleep(b.backoff(i))
+			}
+			if err = b.target.Publish(topic, m, opts...); err == nil {
+				return e.Ack()
+			}
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Bridge %s failed to publish %s to %s: %v", b.opts.Name, e.Topic(), topic, err)
+			}
+		}
+
+		// not acked so the source redelivers it
+		return err
+	}
+}
+
+// forward returns the copy of the message to publish to the target
+func (b *Bridge) forward(msg *broker.Message) (*broker.Message, error) {
+	var marks []string
+	if v := msg.Header[BridgeHeader]; len(v) > 0 {
+		marks = strings.Split(v, ",")
+	}
+	for _, n := range marks {
+		if n == b.opts.Name {
+			return nil, ErrLoop
+		}
+	}
+
+	header := make(map[string]string, len(msg.Header)+len(b.opts.Headers)+1)
+	for k, v := range msg.Header {
+		header[k] = v
+	}
+	for k, v := range b.opts.Headers {
+		if len(v) == 0 {
+			delete(header, k)
+			continue
+		}
+		header[k] = v
+	}
+	header[BridgeHeader] = strings.Join(append(marks, b.opts.Name), ",")
+
+	return &broker.Message{Header: header, Body: msg.Body}, nil
+}
@@ -0,0 +1,143 @@
+package bridge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func newBroker(t *testing.T, r registry.Registry) broker.Broker {
+	b := broker.NewBroker(broker.Registry(r))
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestBridge(t *testing.T) {
+	// separate registries so the brokers don't see each other
+	source := newBroker(t, registry.NewMemoryRegistry())
+	defer source.Disconnect()
+	target := newBroker(t, registry.NewMemoryRegistry())
+	defer target.Disconnect()
+
+	ch := make(chan *broker.Message, 1)
+	sub, err := target.Subscribe("legacy.orders", func(e broker.Event) error {
+		ch <- e.Message()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	b := NewBridge(source, target,
+		Name("migration"),
+		Forward("orders", "legacy.orders"),
+		Header("Secret", ""),
+		Header("Origin", "source"),
+	)
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Stop()
+
+	err = source.Publish("orders", &broker.Message{
+		Header: map[string]string{"Id": "1", "Secret": "x"},
+		Body:   []byte("order"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-ch:
+		if string(m.Body) != "order" || m.Header["Id"] != "1" {
+			t.Fatalf("unexpected message %+v", m)
+		}
+		if _, ok := m.Header["Secret"]; ok {
+			t.Fatal("expected header to be removed")
+		}
+		if m.Header["Origin"] != "source" {
+			t.Fatalf("expected header to be set got %q", m.Header["Origin"])
+		}
+		if m.Header[BridgeHeader] != "migration" {
+			t.Fatalf("expected loop marker got %q", m.Header[BridgeHeader])
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+type testEvent struct {
+	msg   *broker.Message
+	acked bool
+}
+
+func (e *testEvent) Topic() string            { return "orders" }
+func (e *testEvent) Message() *broker.Message { return e.msg }
+func (e *testEvent) Ack() error               { e.acked = true; return nil }
+func (e *testEvent) Error() error             { return nil }
+
+type testBroker struct {
+	broker.Broker
+	fail      int
+	published []*broker.Message
+}
+
+func (b *testBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	if b.fail > 0 {
+		b.fail--
+		return errors.New("unavailable")
+	}
+	b.published = append(b.published, m)
+	return nil
+}
+
+func TestBridgeAck(t *testing.T) {
+	target := &testBroker{fail: 2}
+	b := NewBridge(nil, target, Name("a"), Retries(1))
+	b.backoff = func(int) time.Duration { return 0 }
+	h := b.handler("orders")
+
+	// fails twice so the single retry isn't enough
+	e := &testEvent{msg: &broker.Message{Header: map[string]string{}}}
+	if err := h(e); err == nil {
+		t.Fatal("expected publish error")
+	}
+	if e.acked {
+		t.Fatal("expected message not to be acked")
+	}
+
+	// redelivered by the source
+	if err := h(e); err != nil {
+		t.Fatal(err)
+	}
+	if !e.acked || len(target.published) != 1 {
+		t.Fatal("expected message to be published and acked")
+	}
+	if e.msg.Header[BridgeHeader] != "" {
+		t.Fatal("expected source message to be left unchanged")
+	}
+
+	// forwarded by a bridge with the same name
+	e = &testEvent{msg: &broker.Message{Header: map[string]string{BridgeHeader: "b,a"}}}
+	if err := h(e); err != nil {
+		t.Fatal(err)
+	}
+	if !e.acked || len(target.published) != 1 {
+		t.Fatal("expected looped message to be acked and dropped")
+	}
+
+	// forwarded by another bridge
+	e = &testEvent{msg: &broker.Message{Header: map[string]string{BridgeHeader: "b"}}}
+	if err := h(e); err != nil {
+		t.Fatal(err)
+	}
+	if v := target.published[1].Header[BridgeHeader]; v != "b,a" {
+		t.Fatalf("expected marker b,a got %q", v)
+	}
+}
@@ -0,0 +1,77 @@
+package bridge
+
+import (
+	"github.com/asim/go-micro/v3/broker"
+)
+
+// Route forwards a topic of the source to a topic of the target
+type Route struct {
+	// Source topic to subscribe to
+	Source string
+	// Target topic to publish to, the source topic when empty
+	Target string
+}
+
+type Options struct {
+	// Name of the bridge used as the loop marker and default queue
+	Name string
+	// Queue the source is subscribed with so replicas share the messages
+	Queue string
+	// Routes to forward
+	Routes []Route
+	// Headers to set on forwarded messages, an empty value removes the header
+	Headers map[string]string
+	// Rewrite is called with the target topic and a copy of each message
+	Rewrite func(topic string, m *broker.Message)
+	// Retries of a failed publish before the message is left unacked
+	Retries int
+}
+
+type Option func(o *Options)
+
+// Name sets the name of the bridge. Bridges sharing a name never forward
+// messages forwarded by each other so one name can be used both ways.
+func Name(n string) Option {
+	return func(o *Options) {
+		o.Name = n
+	}
+}
+
+// Queue sets the queue the source topics are subscribed with
+func Queue(q string) Option {
+	return func(o *Options) {
+		o.Queue = q
+	}
+}
+
+// Forward adds a route from the source topic to the target topic
+func Forward(source, target string) Option {
+	return func(o *Options) {
+		o.Routes = append(o.Routes, Route{Source: source, Target: target})
+	}
+}
+
+// Header sets a header on forwarded messages, an empty value removes it
+func Header(key, value string) Option {
+	return func(o *Options) {
+		if o.Headers == nil {
+			o.Headers = make(map[string]string)
+		}
+		o.Headers[key] = value
+	}
+}
+
+// Rewrite sets a func called with the target topic and a copy of each
+// message before it's published
+func Rewrite(fn func(topic string, m *broker.Message)) Option {
+	return func(o *Options) {
+		o.Rewrite = fn
+	}
+}
+
+// Retries sets the retries of a failed publish
+func Retries(n int) Option {
+	return func(o *Options) {
+		o.Retries = n
+	}
+}
@@ -18,6 +18,9 @@ type Broker interface {
 // message and optional Ack method to acknowledge receipt of the message.
 type Handler func(Event) error
 
+// OrderingKeyHeader is set on messages published with an ordering key
+var OrderingKeyHeader = "Micro-Ordering-Key"
+
 type Message struct {
 	Header map[string]string
 	Body   []byte
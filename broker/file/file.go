@@ -0,0 +1,588 @@
+// Package file is a durable broker which stores each topic in an append only log on disk.
+//
+// Subscribers sharing a queue form a consumer group whose offset is committed
+// once a message is acked so it resumes where it left off after a restart.
+// Messages are delivered at least once, one at a time per group in the order
+// published, and redelivered until acked or MaxRedeliveries is reached. With
+// DisableAutoAck the handler must call Ack before it returns. The log is only
+// safe to use from one process.
+package file
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/google/uuid"
+)
+
+var (
+	DefaultPath          = filepath.Join(os.TempDir(), "micro", "broker")
+	DefaultSegmentSize   = int64(16 << 20)
+	DefaultRetryInterval = time.Second
+	// DefaultMaxRedeliveries is how often a message is redelivered before
+	// it's given up on when MaxRedeliveries isn't set
+	DefaultMaxRedeliveries = 10
+
+	// OffsetHeader is set on delivered messages to their offset in the topic log
+	OffsetHeader = "Micro-Broker-Offset"
+	// DeadLetterHeader is set on dead letters to the topic they were published to
+	DeadLetterHeader = "Micro-Broker-Dead-Letter"
+
+	// how often the retention limits are applied
+	retentionInterval = time.Minute
+
+	ErrNotConnected = errors.New("not connected")
+)
+
+type fileBroker struct {
+	opts broker.Options
+
+	path  string
+	retry time.Duration
+	lopts logOptions
+	// redeliveries before a message is dead lettered
+	maxRedeliveries int
+	deadLetter      string
+
+	sync.RWMutex
+	connected bool
+	topics    map[string]*topicLog
+	// consumer groups by topic and queue
+	groups map[string]*group
+	exit   chan bool
+}
+
+func (b *fileBroker) configure(opts ...broker.Option) {
+	for _, o := range opts {
+		o(&b.opts)
+	}
+
+	ctx := b.opts.Context
+	if ctx == nil {
+		return
+	}
+	if v, ok := ctx.Value(pathKey{}).(string); ok {
+		b.path = v
+	}
+	if v, ok := ctx.Value(segmentSizeKey{}).(int64); ok {
+		b.lopts.segmentSize = v
+	}
+	if v, ok := ctx.Value(maxBytesKey{}).(int64); ok {
+		b.lopts.maxBytes = v
+	}
+	if v, ok := ctx.Value(maxAgeKey{}).(time.Duration); ok {
+		b.lopts.maxAge = v
+	}
+	if v, ok := ctx.Value(syncKey{}).(bool); ok {
+		b.lopts.sync = v
+	}
+	if v, ok := ctx.Value(retryKey{}).(time.Duration); ok {
+		b.retry = v
+	}
+	if v, ok := ctx.Value(maxRedeliveriesKey{}).(int); ok {
+		b.maxRedeliveries = v
+	}
+	if v, ok := ctx.Value(deadLetterKey{}).(string); ok {
+		b.deadLetter = v
+	}
+}
+
+func (b *fileBroker) Init(opts ...broker.Option) error {
+	b.Lock()
+	defer b.Unlock()
+	if b.connected {
+		return errors.New("cannot init while connected")
+	}
+	b.configure(opts...)
+	return nil
+}
+
+func (b *fileBroker) Options() broker.Options {
+	return b.opts
+}
+
+func (b *fileBroker) Address() string {
+	return b.path
+}
+
+func (b *fileBroker) Connect() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.connected {
+		return nil
+	}
+
+	if err := os.MkdirAll(b.path, 0755); err != nil {
+		return err
+	}
+
+	b.exit = make(chan bool)
+	b.connected = true
+
+	go b.retain(b.exit)
+
+	return nil
+}
+
+func (b *fileBroker) Disconnect() error {
+	b.Lock()
+	if !b.connected {
+		b.Unlock()
+		return nil
+	}
+	close(b.exit)
+	groups, topics := b.groups, b.topics
+	b.groups = make(map[string]*group)
+	b.topics = make(map[string]*topicLog)
+	b.connected = false
+	b.Unlock()
+
+	// handlers may publish so wait for them without the lock
+	for _, g := range groups {
+		g.stop()
+		<-g.done
+	}
+	for _, l := range topics {
+		l.close()
+	}
+
+	return nil
+}
+
+// retain applies the age limit to topics which aren't being written to
+func (b *fileBroker) retain(exit chan bool) {
+	t := time.NewTicker(retentionInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-t.C:
+			b.RLock()
+			for _, l := range b.topics {
+				l.Lock()
+				l.retain()
+				l.Unlock()
+			}
+			b.RUnlock()
+		}
+	}
+}
+
+// topic returns the log of the topic opening it if needed. Must hold the lock.
+func (b *fileBroker) topic(name string) (*topicLog, error) {
+	if l, ok := b.topics[name]; ok {
+		return l, nil
+	}
+	l, err := openLog(filepath.Join(b.path, url.PathEscape(name)), b.lopts)
+	if err != nil {
+		return nil, err
+	}
+	b.topics[name] = l
+	return l, nil
+}
+
+func (b *fileBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	b.Lock()
+	if !b.connected {
+		b.Unlock()
+		return ErrNotConnected
+	}
+	l, err := b.topic(topic)
+	b.Unlock()
+	if err != nil {
+		return err
+	}
+
+	_, err = l.append(msg)
+	return err
+}
+
+func (b *fileBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	options := broker.NewSubscribeOptions(opts...)
+
+	b.Lock()
+	defer b.Unlock()
+
+	if !b.connected {
+		return nil, ErrNotConnected
+	}
+
+	l, err := b.topic(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscriber{
+		id:      uuid.New().String(),
+		topic:   topic,
+		opts:    options,
+		handler: h,
+		broker:  b,
+	}
+
+	// subscribers without a queue each get their own group
+	name := options.Queue
+	if len(name) == 0 {
+		name = sub.id
+	}
+	key := topic + "\x00" + name
+
+	g, ok := b.groups[key]
+	if !ok {
+		g = &group{
+			topic:   topic,
+			name:    name,
+			durable: len(options.Queue) > 0,
+			log:     l,
+			retry:   b.retry,
+			broker:  b,
+			exit:    make(chan bool),
+			done:    make(chan bool),
+		}
+		g.path = filepath.Join(l.dir, "offsets", url.PathEscape(name))
+
+		offset, err := g.start(options)
+		if err != nil {
+			return nil, err
+		}
+		g.offset = offset
+		b.groups[key] = g
+		g.subs = append(g.subs, sub)
+		go g.run()
+	} else {
+		g.Lock()
+		g.subs = append(g.subs, sub)
+		g.Unlock()
+	}
+
+	sub.group = g
+	sub.key = key
+
+	return sub, nil
+}
+
+func (b *fileBroker) unsubscribe(s *subscriber) error {
+	b.Lock()
+	defer b.Unlock()
+
+	g, ok := b.groups[s.key]
+	if !ok || g != s.group {
+		return nil
+	}
+
+	g.Lock()
+	for i, sub := range g.subs {
+		if sub == s {
+			g.subs = append(g.subs[:i], g.subs[i+1:]...)
+			break
+		}
+	}
+	empty := len(g.subs) == 0
+	g.Unlock()
+
+	// the handler may be unsubscribing so don't wait for the group
+	if empty {
+		delete(b.groups, s.key)
+		g.stop()
+	}
+
+	return nil
+}
+
+func (b *fileBroker) String() string {
+	return "file"
+}
+
+// group delivers the messages of a topic to one of its subscribers at a time
+type group struct {
+	topic   string
+	name    string
+	durable bool
+	path    string
+	log     *topicLog
+	retry   time.Duration
+	broker  *fileBroker
+
+	sync.Mutex
+	subs   []*subscriber
+	next   int
+	offset int64
+
+	exit chan bool
+	done chan bool
+	once sync.Once
+}
+
+// start returns the offset the group starts at
+func (g *group) start(opts broker.SubscribeOptions) (int64, error) {
+	if opts.Context != nil {
+		if off, ok := opts.Context.Value(offsetKey{}).(int64); ok {
+			off = g.clamp(off)
+			return off, g.commit(off)
+		}
+	}
+
+	if g.durable {
+		b, err := ioutil.ReadFile(g.path)
+		if err == nil {
+			off, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+			return g.clamp(off), err
+		}
+		if !os.IsNotExist(err) {
+			return 0, err
+		}
+	}
+
+	// new groups start with the next message and commit
+	// it so nothing published while stopped is missed
+	off := g.log.newest()
+	return off, g.commit(off)
+}
+
+// clamp limits the offset to the messages in the log
+func (g *group) clamp(off int64) int64 {
+	if n := g.log.newest(); off > n {
+		return n
+	}
+	if n := g.log.oldest(); off < n {
+		return n
+	}
+	return off
+}
+
+// commit saves the offset of the next message to deliver
+func (g *group) commit(off int64) error {
+	if !g.durable {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), 0755); err != nil {
+		return err
+	}
+	tmp := g.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatInt(off, 10)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.path)
+}
+
+// subscriber returns the subscriber to deliver the next message to
+func (g *group) subscriber() *subscriber {
+	g.Lock()
+	defer g.Unlock()
+	if len(g.subs) == 0 {
+		return nil
+	}
+	s := g.subs[g.next%len(g.subs)]
+	g.next++
+	return s
+}
+
+func (g *group) run() {
+	defer close(g.done)
+
+	for {
+		select {
+		case <-g.exit:
+			return
+		default:
+		}
+
+		// get the channel before reading so no append is missed
+		wait := g.log.wait()
+
+		r, ok, err := g.log.read(g.offset)
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("[file] broker failed to read %s at %d: %v", g.topic, g.offset, err)
+			}
+			select {
+			case <-g.exit:
+				return
+			case <-time.After(g.retry):
+			}
+			continue
+		}
+		if !ok {
+			select {
+			case <-g.exit:
+				return
+			case <-wait:
+			}
+			continue
+		}
+
+		if !g.deliver(r) {
+			return
+		}
+
+		g.offset = r.Offset + 1
+		if err := g.commit(g.offset); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("[file] broker failed to commit %s offset for %s: %v", g.topic, g.name, err)
+			}
+		}
+	}
+}
+
+// deliver hands the record to a subscriber until it's acked or dead
+// lettered. It returns false if the group is stopped first.
+func (g *group) deliver(r *record) bool {
+	for attempt := 0; ; attempt++ {
+		s := g.subscriber()
+		if s == nil {
+			return false
+		}
+
+		if s.handle(r) {
+			return true
+		}
+
+		if max := g.broker.maxRedeliveries; max > 0 && attempt >= max {
+			g.deadLetter(r)
+			return true
+		}
+
+		select {
+		case <-g.exit:
+			return false
+		case <-time.After(g.retry):
+		}
+	}
+}
+
+// deadLetter gives up on the record publishing it to the dead letter topic if set
+func (g *group) deadLetter(r *record) {
+	topic := g.broker.deadLetter
+	if len(topic) == 0 || topic == g.topic {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("[file] broker skipping %s at %d for %s after %d redeliveries", g.topic, r.Offset, g.name, g.broker.maxRedeliveries)
+		}
+		return
+	}
+
+	header := make(map[string]string, len(r.Message.Header)+1)
+	for k, v := range r.Message.Header {
+		header[k] = v
+	}
+	header[DeadLetterHeader] = g.topic
+
+	err := g.broker.Publish(topic, &broker.Message{Header: header, Body: r.Message.Body})
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("[file] broker failed to dead letter %s at %d to %s: %v", g.topic, r.Offset, topic, err)
+		}
+	}
+}
+
+func (g *group) stop() {
+	g.once.Do(func() {
+		close(g.exit)
+	})
+}
+
+type subscriber struct {
+	id      string
+	key     string
+	topic   string
+	opts    broker.SubscribeOptions
+	handler broker.Handler
+	broker  *fileBroker
+	group   *group
+}
+
+// handle calls the handler and returns true if the message was acked
+func (s *subscriber) handle(r *record) bool {
+	header := make(map[string]string, len(r.Message.Header)+1)
+	for k, v := range r.Message.Header {
+		header[k] = v
+	}
+	header[OffsetHeader] = strconv.FormatInt(r.Offset, 10)
+
+	e := &event{
+		topic: s.topic,
+		message: &broker.Message{
+			Header: header,
+			Body:   r.Message.Body,
+		},
+	}
+
+	e.err = s.handler(e)
+	if e.err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("[file] broker handler for %s failed at %d: %v", s.topic, r.Offset, e.err)
+		}
+		if eh := s.broker.opts.ErrorHandler; eh != nil {
+			eh(e)
+		}
+	} else if s.opts.AutoAck {
+		e.acked = true
+	}
+
+	return e.acked
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+func (s *subscriber) Unsubscribe() error {
+	return s.broker.unsubscribe(s)
+}
+
+type event struct {
+	topic   string
+	message *broker.Message
+	acked   bool
+	err     error
+}
+
+func (e *event) Topic() string {
+	return e.topic
+}
+
+func (e *event) Message() *broker.Message {
+	return e.message
+}
+
+func (e *event) Ack() error {
+	e.acked = true
+	return nil
+}
+
+func (e *event) Error() error {
+	return e.err
+}
+
+// NewBroker returns a broker which stores messages in the directory set by Path.
+// Messages which aren't acked are redelivered DefaultMaxRedeliveries times and
+// then skipped unless the MaxRedeliveries and DeadLetter options are set.
+func NewBroker(opts ...broker.Option) broker.Broker {
+	b := &fileBroker{
+		path:            DefaultPath,
+		retry:           DefaultRetryInterval,
+		maxRedeliveries: DefaultMaxRedeliveries,
+		lopts: logOptions{
+			segmentSize: DefaultSegmentSize,
+		},
+		topics: make(map[string]*topicLog),
+		groups: make(map[string]*group),
+	}
+	b.configure(opts...)
+	return b
+}
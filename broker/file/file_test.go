@@ -0,0 +1,341 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+)
+
+func newBroker(t *testing.T, dir string, opts ...broker.Option) broker.Broker {
+	b := NewBroker(append([]broker.Option{Path(dir), RetryInterval(10 * time.Millisecond)}, opts...)...)
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func publish(t *testing.T, b broker.Broker, topic string, bodies ...string) {
+	for _, body := range bodies {
+		if err := b.Publish(topic, &broker.Message{Header: map[string]string{"id": body}, Body: []byte(body)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func receive(t *testing.T, ch chan string, want ...string) {
+	for _, w := range want {
+		select {
+		case got := <-ch:
+			if got != w {
+				t.Fatalf("expected %s got %s", w, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %s", w)
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newBroker(t, dir)
+
+	// messages before subscribing aren't delivered to new subscribers
+	publish(t, b, "test", "0")
+
+	ch := make(chan string, 10)
+	sub, err := b.Subscribe("test", func(e broker.Event) error {
+		if e.Message().Header[OffsetHeader] == "" {
+			t.Error("expected the offset header")
+		}
+		ch <- string(e.Message().Body)
+		return nil
+	}, broker.Queue("q"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, b, "test", "1", "2", "3")
+	receive(t, ch, "1", "2", "3")
+
+	// the queue resumes where it left off after a restart
+	sub.Unsubscribe()
+	publish(t, b, "test", "4")
+	if err := b.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = newBroker(t, dir)
+	defer b.Disconnect()
+
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		ch <- string(e.Message().Body)
+		return nil
+	}, broker.Queue("q")); err != nil {
+		t.Fatal(err)
+	}
+	publish(t, b, "test", "5")
+	receive(t, ch, "4", "5")
+
+	// replay from an offset
+	replay := make(chan string, 10)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		replay <- string(e.Message().Body)
+		return nil
+	}, Offset(2)); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, replay, "2", "3", "4", "5")
+}
+
+func TestRedelivery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newBroker(t, dir)
+	defer b.Disconnect()
+
+	// handler errors are retried
+	attempts := make(map[string]int)
+	ch := make(chan string, 10)
+	if _, err := b.Subscribe("errors", func(e broker.Event) error {
+		body := string(e.Message().Body)
+		attempts[body]++
+		if attempts[body] < 3 {
+			return errors.New("failed")
+		}
+		ch <- body
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// unacked messages are redelivered
+	acks := make(chan string, 10)
+	seen := make(map[string]bool)
+	if _, err := b.Subscribe("acks", func(e broker.Event) error {
+		body := string(e.Message().Body)
+		if seen[body] {
+			e.Ack()
+			acks <- body
+		}
+		seen[body] = true
+		return nil
+	}, broker.DisableAutoAck()); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, b, "errors", "1", "2")
+	publish(t, b, "acks", "1", "2")
+
+	receive(t, ch, "1", "2")
+	receive(t, acks, "1", "2")
+}
+
+func TestRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newBroker(t, dir, SegmentSize(100), MaxBytes(250))
+	defer b.Disconnect()
+
+	for i := 0; i < 20; i++ {
+		publish(t, b, "test", strconv.Itoa(i))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "test", "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) > 3 {
+		t.Fatalf("expected old segments to be removed, got %d", len(files))
+	}
+
+	// replaying from the start begins with the oldest message kept
+	ch := make(chan string, 20)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		ch <- string(e.Message().Body)
+		return nil
+	}, Offset(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-ch:
+		if got == "0" {
+			t.Fatal("expected the first message to be removed")
+		}
+		n, _ := strconv.Atoi(got)
+		for i := n + 1; i < 20; i++ {
+			receive(t, ch, strconv.Itoa(i))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected messages")
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newBroker(t, dir)
+	publish(t, b, "test", "0", "1")
+	b.Disconnect()
+
+	// a partly written record
+	f, err := os.OpenFile(filepath.Join(dir, "test", segmentName(0)), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 2, 1, 2, 3})
+	f.Close()
+
+	b = newBroker(t, dir)
+	defer b.Disconnect()
+
+	publish(t, b, "test", "2")
+
+	ch := make(chan string, 10)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		ch <- fmt.Sprintf("%s:%s", e.Message().Header[OffsetHeader], e.Message().Body)
+		return nil
+	}, Offset(0)); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ch, "0:0", "1:1", "2:2")
+}
+
+func TestDeadLetter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newBroker(t, dir, MaxRedeliveries(2), DeadLetter("dead"))
+	defer b.Disconnect()
+
+	attempts := make(map[string]int)
+	ch := make(chan string, 10)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		body := string(e.Message().Body)
+		attempts[body]++
+		if body == "poison" {
+			return errors.New("failed")
+		}
+		ch <- body
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dead := make(chan string, 10)
+	if _, err := b.Subscribe("dead", func(e broker.Event) error {
+		dead <- e.Message().Header[DeadLetterHeader] + ":" + string(e.Message().Body)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, b, "test", "poison", "1")
+
+	receive(t, dead, "test:poison")
+	receive(t, ch, "1")
+	if n := attempts["poison"]; n != 3 {
+		t.Fatalf("expected 3 attempts got %d", n)
+	}
+}
+
+func TestDefaultMaxRedeliveries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b := newBroker(t, dir, DeadLetter("dead"))
+	defer b.Disconnect()
+
+	var attempts int
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		attempts++
+		return errors.New("failed")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dead := make(chan string, 10)
+	if _, err := b.Subscribe("dead", func(e broker.Event) error {
+		dead <- string(e.Message().Body)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, b, "test", "poison")
+
+	// given up on without the MaxRedeliveries option
+	receive(t, dead, "poison")
+	if attempts != DefaultMaxRedeliveries+1 {
+		t.Fatalf("expected %d attempts got %d", DefaultMaxRedeliveries+1, attempts)
+	}
+}
+
+func TestCorruptSegment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// two records per segment
+	b := newBroker(t, dir, SegmentSize(100))
+	publish(t, b, "test", "0", "1", "2", "3")
+	b.Disconnect()
+
+	// corrupt the last record of the first segment
+	path := filepath.Join(dir, "test", segmentName(0))
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test", segmentName(2))); err != nil {
+		t.Fatal(err)
+	}
+
+	b = newBroker(t, dir, SegmentSize(100))
+	defer b.Disconnect()
+
+	ch := make(chan string, 10)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		ch <- fmt.Sprintf("%s:%s", e.Message().Header[OffsetHeader], e.Message().Body)
+		return nil
+	}, Offset(0)); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, ch, "0:0", "2:2", "3:3")
+}
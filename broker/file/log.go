@@ -0,0 +1,212 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+type logOptions struct {
+	segmentSize int64
+	maxBytes    int64
+	maxAge      time.Duration
+	sync        bool
+}
+
+// topicLog is the append only log of a topic split into segments
+type topicLog struct {
+	dir  string
+	opts logOptions
+
+	sync.RWMutex
+	segments []*segment
+	// offset of the next message
+	next int64
+	// closed when a message is appended
+	notify chan struct{}
+}
+
+func segmentName(base int64) string {
+	return fmt.Sprintf("%020d.log", base)
+}
+
+func openLog(dir string, opts logOptions) (*topicLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var bases []int64
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		base, err := strconv.ParseInt(strings.TrimSuffix(name, ".log"), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+
+	l := &topicLog{
+		dir:    dir,
+		opts:   opts,
+		notify: make(chan struct{}),
+	}
+
+	for _, base := range bases {
+		s, err := openSegment(filepath.Join(dir, segmentName(base)), base)
+		if err != nil {
+			l.close()
+			return nil, err
+		}
+		l.segments = append(l.segments, s)
+	}
+
+	if len(l.segments) == 0 {
+		s, err := openSegment(filepath.Join(dir, segmentName(0)), 0)
+		if err != nil {
+			return nil, err
+		}
+		l.segments = append(l.segments, s)
+	}
+
+	l.next = l.segments[len(l.segments)-1].end()
+
+	return l, nil
+}
+
+// append writes the message to the log and returns its offset
+func (l *topicLog) append(m *broker.Message) (int64, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	s := l.segments[len(l.segments)-1]
+
+	// start a new segment once the current one is full
+	if l.opts.segmentSize > 0 && s.size >= l.opts.segmentSize {
+		ns, err := openSegment(filepath.Join(l.dir, segmentName(l.next)), l.next)
+		if err != nil {
+			return 0, err
+		}
+		l.segments = append(l.segments, ns)
+		s = ns
+		l.retain()
+	}
+
+	off := l.next
+	if err := s.append(off, time.Now(), data, l.opts.sync); err != nil {
+		return 0, err
+	}
+	l.next++
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+
+	return off, nil
+}
+
+// read returns the record at the offset or the oldest one if it was removed.
+// It returns false if the message hasn't been published yet.
+func (l *topicLog) read(off int64) (*record, bool, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if off >= l.next {
+		return nil, false, nil
+	}
+	if first := l.segments[0].base; off < first {
+		off = first
+	}
+
+	// the last segment starting at or before the offset
+	i := sort.Search(len(l.segments), func(i int) bool { return l.segments[i].base > off }) - 1
+
+	// a corrupt segment was truncated so skip what's missing of it
+	for off >= l.segments[i].end() && i+1 < len(l.segments) {
+		next := l.segments[i+1].base
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("[file] broker lost messages %d to %d of %s, skipping to %d", off, next-1, l.dir, next)
+		}
+		off = next
+		i++
+	}
+
+	r, err := l.segments[i].read(off)
+	if err != nil {
+		return nil, false, err
+	}
+	return r, true, nil
+}
+
+// wait returns a channel closed when the next message is appended
+func (l *topicLog) wait() <-chan struct{} {
+	l.RLock()
+	defer l.RUnlock()
+	return l.notify
+}
+
+// oldest returns the offset of the oldest message kept
+func (l *topicLog) oldest() int64 {
+	l.RLock()
+	defer l.RUnlock()
+	return l.segments[0].base
+}
+
+// newest returns the offset the next message is published at
+func (l *topicLog) newest() int64 {
+	l.RLock()
+	defer l.RUnlock()
+	return l.next
+}
+
+// retain removes the oldest segments beyond the size or age
+// limits. The segment being written is kept. Must hold the lock.
+func (l *topicLog) retain() {
+	var total int64
+	for _, s := range l.segments {
+		total += s.size
+	}
+
+	for len(l.segments) > 1 {
+		s := l.segments[0]
+		tooBig := l.opts.maxBytes > 0 && total > l.opts.maxBytes
+		tooOld := l.opts.maxAge > 0 && time.Since(s.last) > l.opts.maxAge
+		if !tooBig && !tooOld {
+			return
+		}
+		s.remove()
+		total -= s.size
+		l.segments[0] = nil
+		l.segments = l.segments[1:]
+	}
+}
+
+func (l *topicLog) close() {
+	l.Lock()
+	defer l.Unlock()
+	for _, s := range l.segments {
+		s.close()
+	}
+}
@@ -0,0 +1,86 @@
+package file
+
+import (
+	"context"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+)
+
+type pathKey struct{}
+type segmentSizeKey struct{}
+type maxBytesKey struct{}
+type maxAgeKey struct{}
+type syncKey struct{}
+type retryKey struct{}
+type offsetKey struct{}
+type maxRedeliveriesKey struct{}
+type deadLetterKey struct{}
+
+// Path sets the directory the topic logs are stored in
+func Path(p string) broker.Option {
+	return setOption(pathKey{}, p)
+}
+
+// SegmentSize sets the size in bytes a log segment grows to before a new one is started
+func SegmentSize(n int64) broker.Option {
+	return setOption(segmentSizeKey{}, n)
+}
+
+// MaxBytes sets the size in bytes each topic log is kept to. The oldest
+// segments are removed first and the segment being written is kept.
+func MaxBytes(n int64) broker.Option {
+	return setOption(maxBytesKey{}, n)
+}
+
+// MaxAge sets how long messages are kept. Segments are removed once
+// their newest message is older.
+func MaxAge(d time.Duration) broker.Option {
+	return setOption(maxAgeKey{}, d)
+}
+
+// Sync flushes every message to disk before Publish returns
+func Sync(b bool) broker.Option {
+	return setOption(syncKey{}, b)
+}
+
+// RetryInterval sets the delay before a message which wasn't acked is delivered again
+func RetryInterval(d time.Duration) broker.Option {
+	return setOption(retryKey{}, d)
+}
+
+// MaxRedeliveries sets how often a message which wasn't acked is delivered
+// again before it's given up on, DefaultMaxRedeliveries when not set. It's
+// then published to the DeadLetter topic if set or skipped otherwise. Zero
+// redelivers it until it's acked.
+func MaxRedeliveries(n int) broker.Option {
+	return setOption(maxRedeliveriesKey{}, n)
+}
+
+// DeadLetter sets the topic messages are published to once they've been
+// redelivered MaxRedeliveries times. The DeadLetterHeader is set to the
+// topic they were published to.
+func DeadLetter(topic string) broker.Option {
+	return setOption(deadLetterKey{}, topic)
+}
+
+// Offset sets the offset a subscription starts at, replaying the messages
+// from there. Without it a queue resumes from its committed offset and
+// anything else starts with the next message published.
+func Offset(n int64) broker.SubscribeOption {
+	return func(o *broker.SubscribeOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, offsetKey{}, n)
+	}
+}
+
+func setOption(k, v interface{}) broker.Option {
+	return func(o *broker.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
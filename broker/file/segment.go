@@ -0,0 +1,175 @@
+package file
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+)
+
+// each record is an offset, timestamp, length and crc followed by the message
+const headerSize = 24
+
+var (
+	errCorrupt = errors.New("corrupt record")
+	errMissing = errors.New("record not in segment")
+)
+
+type record struct {
+	Offset    int64
+	Timestamp time.Time
+	Message   *broker.Message
+}
+
+// segment is a file of consecutive records starting at base
+type segment struct {
+	base int64
+	path string
+	f    *os.File
+	// file position of each record
+	positions []int64
+	size      int64
+	// timestamp of the newest record
+	last time.Time
+}
+
+// openSegment opens the segment and truncates any partly written records
+func openSegment(path string, base int64) (*segment, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s := &segment{
+		base: base,
+		path: path,
+		f:    f,
+		size: info.Size(),
+	}
+
+	var pos int64
+	for {
+		off, ts, data, err := s.readAt(pos)
+		if err != nil || off != base+int64(len(s.positions)) {
+			break
+		}
+		s.positions = append(s.positions, pos)
+		s.last = ts
+		pos += headerSize + int64(len(data))
+	}
+
+	if s.size > pos {
+		if err := f.Truncate(pos); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	s.size = pos
+
+	return s, nil
+}
+
+func (s *segment) readAt(pos int64) (int64, time.Time, []byte, error) {
+	var hdr [headerSize]byte
+	if _, err := s.f.ReadAt(hdr[:], pos); err != nil {
+		return 0, time.Time{}, nil, err
+	}
+
+	off := int64(binary.BigEndian.Uint64(hdr[0:8]))
+	ts := time.Unix(0, int64(binary.BigEndian.Uint64(hdr[8:16])))
+	size := binary.BigEndian.Uint32(hdr[16:20])
+	sum := binary.BigEndian.Uint32(hdr[20:24])
+
+	// the length of a partly written header can be anything
+	if pos+headerSize+int64(size) > s.size {
+		return 0, time.Time{}, nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, size)
+	if _, err := s.f.ReadAt(data, pos+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, time.Time{}, nil, err
+	}
+	if crc32.ChecksumIEEE(data) != sum {
+		return 0, time.Time{}, nil, errCorrupt
+	}
+
+	return off, ts, data, nil
+}
+
+func (s *segment) append(off int64, ts time.Time, data []byte, sync bool) error {
+	buf := make([]byte, headerSize+len(data))
+	binary.BigEndian.PutUint64(buf[0:8], uint64(off))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(ts.UnixNano()))
+	binary.BigEndian.PutUint32(buf[16:20], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[20:24], crc32.ChecksumIEEE(data))
+	copy(buf[headerSize:], data)
+
+	if _, err := s.f.WriteAt(buf, s.size); err != nil {
+		// drop whatever was written of the record
+		s.f.Truncate(s.size)
+		return err
+	}
+	if sync {
+		if err := s.f.Sync(); err != nil {
+			return err
+		}
+	}
+
+	s.positions = append(s.positions, s.size)
+	s.size += int64(len(buf))
+	s.last = ts
+
+	return nil
+}
+
+// read returns the record at the offset. Records after a corrupt one
+// are truncated on open so the offset may be missing.
+func (s *segment) read(off int64) (*record, error) {
+	if off < s.base || off >= s.end() {
+		return nil, errMissing
+	}
+
+	_, ts, data, err := s.readAt(s.positions[off-s.base])
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(broker.Message)
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	return &record{
+		Offset:    off,
+		Timestamp: ts,
+		Message:   msg,
+	}, nil
+}
+
+// end is the offset after the last record
+func (s *segment) end() int64 {
+	return s.base + int64(len(s.positions))
+}
+
+func (s *segment) close() error {
+	return s.f.Close()
+}
+
+func (s *segment) remove() error {
+	s.f.Close()
+	return os.Remove(s.path)
+}
@@ -15,13 +15,16 @@ import (
 	"net/url"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asim/go-micro/v3/codec/json"
 	merr "github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/registry/cache"
 	maddr "github.com/asim/go-micro/v3/util/addr"
+	"github.com/asim/go-micro/v3/util/keylock"
 	mnet "github.com/asim/go-micro/v3/util/net"
 	mls "github.com/asim/go-micro/v3/util/tls"
 	"github.com/google/uuid"
@@ -47,6 +50,10 @@ type httpBroker struct {
 	// offline message inbox
 	mtx   sync.RWMutex
 	inbox map[string][][]byte
+
+	// publishes and handlers of ordered messages
+	ordered keyQueues
+	keys    keylock.Locks
 }
 
 type httpSubscriber struct {
@@ -56,6 +63,11 @@ type httpSubscriber struct {
 	fn    Handler
 	svc   *registry.Service
 	hb    *httpBroker
+
+	// messages received but not yet handled
+	inflight int32
+	// handlers running
+	running chan struct{}
 }
 
 type httpEvent struct {
@@ -71,6 +83,9 @@ var (
 	broadcastVersion = "ff.http.broadcast"
 	registerTTL      = time.Minute
 	registerInterval = time.Second * 30
+
+	// attempts to deliver an ordered message before it's saved to the inbox
+	orderedAttempts = 3
 )
 
 func init() {
@@ -193,6 +208,9 @@ func (h *httpBroker) saveMessage(topic string, msg []byte) {
 
 	// max length 64
 	if len(c) > 64 {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Broker inbox for %s is full, dropping %d messages", topic, len(c)-64)
+		}
 		c = c[:64]
 	}
 
@@ -330,23 +348,58 @@ func (h *httpBroker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	id := req.Form.Get("id")
 
 	//nolint:prealloc
-	var subs []Handler
+	var subs []*httpSubscriber
 
 	h.RLock()
 	for _, subscriber := range h.subscribers[topic] {
 		if id != subscriber.id {
 			continue
 		}
-		subs = append(subs, subscriber.fn)
+		subs = append(subs, subscriber)
 	}
 	h.RUnlock()
 
+	// refuse messages beyond the prefetch limit. The publisher saves them to its
+	// inbox which is best effort, it's retried on the next publish to the topic
+	// and drops messages once full.
+	var full bool
+	for _, sub := range subs {
+		n := atomic.AddInt32(&sub.inflight, 1)
+		defer atomic.AddInt32(&sub.inflight, -1)
+
+		if sub.opts.Prefetch > 0 && int(n) > sub.opts.Prefetch {
+			full = true
+		}
+	}
+	if full {
+		errr := merr.New("go.micro.broker", "Too many messages in flight", http.StatusServiceUnavailable)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(errr.Error()))
+		return
+	}
+
+	// handle the messages of a key one at a time
+	if key := m.Header[OrderingKeyHeader]; len(key) > 0 {
+		k := id + "\x00" + key
+		h.keys.Lock(k)
+		defer h.keys.Unlock(k)
+	}
+
 	// execute the handler
-	for _, fn := range subs {
-		p.err = fn(p)
+	for _, sub := range subs {
+		p.err = sub.handle(p)
 	}
 }
 
+// handle runs the handler within the concurrency limit
+func (h *httpSubscriber) handle(p *httpEvent) error {
+	if h.running != nil {
+		h.running <- struct{}{}
+		defer func() { <-h.running }()
+	}
+	return h.fn(p)
+}
+
 func (h *httpBroker) Address() string {
 	h.RLock()
 	defer h.RUnlock()
@@ -510,6 +563,11 @@ func (h *httpBroker) Options() Options {
 }
 
 func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption) error {
+	var options PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
 	// create the message first
 	m := &Message{
 		Header: make(map[string]string),
@@ -522,14 +580,21 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 
 	m.Header["Micro-Topic"] = topic
 
+	key := options.OrderingKey
+	if len(key) > 0 {
+		m.Header[OrderingKeyHeader] = key
+	}
+
 	// encode the message
 	b, err := h.opts.Codec.Marshal(m)
 	if err != nil {
 		return err
 	}
 
-	// save the message
-	h.saveMessage(topic, b)
+	// save the message unless it has to be sent in order
+	if len(key) == 0 {
+		h.saveMessage(topic, b)
+	}
 
 	// now attempt to get the service
 	h.RLock()
@@ -560,10 +625,18 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 		// discard response body
 		io.Copy(ioutil.Discard, r.Body)
 		r.Body.Close()
+
+		// the subscriber is at its prefetch limit
+		if r.StatusCode == http.StatusServiceUnavailable {
+			return merr.New("go.micro.broker", "Subscriber unavailable", http.StatusServiceUnavailable)
+		}
 		return nil
 	}
 
-	srv := func(s []*registry.Service, b []byte) {
+	// srv publishes to each service and returns false if any failed
+	srv := func(s []*registry.Service, b []byte, key string) bool {
+		ok := true
+
 		for _, service := range s {
 			var nodes []*registry.Node
 
@@ -599,21 +672,44 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 					}
 				}
 
-				// save if it failed to publish at least once
+				// failed to publish at least once
 				if !success {
-					h.saveMessage(topic, b)
+					ok = false
 				}
 			default:
 				// select node to publish to
-				node := nodes[rand.Int()%len(nodes)]
+				var node *registry.Node
+				if len(key) > 0 {
+					node = keyNode(key, nodes)
+				} else {
+					node = nodes[rand.Int()%len(nodes)]
+				}
 
 				// publish async to one node
 				if err := pub(node, topic, b); err != nil {
-					// if failed save it
-					h.saveMessage(topic, b)
+					ok = false
 				}
 			}
 		}
+
+		return ok
+	}
+
+	// ordered messages are sent one at a time per key
+	if len(key) > 0 {
+		h.ordered.push(key, func() {
+			for i := 0; i < orderedAttempts; i++ {
+				if i > 0 {
+					time.Sleep(time.Millisecond * 100)
+				}
+				if srv(s, b, key) {
+					return
+				}
+			}
+			// give up on the order rather than the message
+			h.saveMessage(topic, b)
+		})
+		return nil
 	}
 
 	// do the rest async
@@ -624,8 +720,10 @@ func (h *httpBroker) Publish(topic string, msg *Message, opts ...PublishOption)
 
 		// publish all the messages
 		for _, msg := range messages {
-			// serialize here
-			srv(s, msg)
+			// serialize here, if failed save it
+			if !srv(s, msg, "") {
+				h.saveMessage(topic, msg)
+			}
 
 			// sending a backlog of messages
 			if delay {
@@ -692,6 +790,10 @@ func (h *httpBroker) Subscribe(topic string, handler Handler, opts ...SubscribeO
 		svc:   service,
 	}
 
+	if options.Concurrency > 0 {
+		subscriber.running = make(chan struct{}, options.Concurrency)
+	}
+
 	// subscribe now
 	if err := h.subscribe(subscriber); err != nil {
 		return nil, err
@@ -1,6 +1,8 @@
 package broker_test
 
 import (
+	"fmt"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -233,6 +235,161 @@ func TestBroker(t *testing.T) {
 	}
 }
 
+func TestOrderedBroker(t *testing.T) {
+	m := newTestRegistry()
+
+	// a broker per subscriber of the queue
+	var brokers []broker.Broker
+	for i := 0; i < 2; i++ {
+		b := broker.NewBroker(broker.Registry(m))
+		if err := b.Connect(); err != nil {
+			t.Fatalf("Unexpected connect error: %v", err)
+		}
+		defer b.Disconnect()
+		brokers = append(brokers, b)
+	}
+
+	keys := []string{"a", "b", "c", "d"}
+	count := 10
+
+	var mtx sync.Mutex
+	// the messages and subscriber handling each key
+	received := make(map[string][]int)
+	handlers := make(map[string]int)
+	inflight := make(map[string]bool)
+
+	var wg sync.WaitGroup
+	wg.Add(len(keys) * count)
+
+	handler := func(i int) broker.Handler {
+		return func(p broker.Event) error {
+			key := p.Message().Header[broker.OrderingKeyHeader]
+
+			mtx.Lock()
+			if inflight[key] {
+				t.Errorf("Unexpected message in flight for key %s", key)
+			}
+			inflight[key] = true
+			if h, ok := handlers[key]; ok && h != i {
+				t.Errorf("Expected key %s to be handled by subscriber %d, got %d", key, h, i)
+			}
+			handlers[key] = i
+			mtx.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			n, _ := strconv.Atoi(string(p.Message().Body))
+
+			mtx.Lock()
+			inflight[key] = false
+			received[key] = append(received[key], n)
+			mtx.Unlock()
+
+			wg.Done()
+			return nil
+		}
+	}
+
+	for i, b := range brokers {
+		sub, err := b.Subscribe("ordered", handler(i), broker.Queue("queue"))
+		if err != nil {
+			t.Fatalf("Unexpected subscribe error: %v", err)
+		}
+		defer sub.Unsubscribe()
+	}
+
+	for n := 0; n < count; n++ {
+		for _, key := range keys {
+			msg := &broker.Message{
+				Header: map[string]string{"Content-Type": "text/plain"},
+				Body:   []byte(fmt.Sprintf("%d", n)),
+			}
+			if err := brokers[0].Publish("ordered", msg, broker.OrderingKey(key)); err != nil {
+				t.Fatalf("Unexpected publish error: %v", err)
+			}
+		}
+	}
+
+	done := make(chan bool)
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for messages")
+	}
+
+	for _, key := range keys {
+		for n, got := range received[key] {
+			if got != n {
+				t.Fatalf("Expected messages for key %s in order, got %v", key, received[key])
+			}
+		}
+	}
+}
+
+func TestPrefetchBroker(t *testing.T) {
+	m := newTestRegistry()
+	b := broker.NewBroker(broker.Registry(m))
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error: %v", err)
+	}
+	defer b.Disconnect()
+
+	release := make(chan bool)
+	received := make(chan string, 10)
+
+	sub, err := b.Subscribe("prefetch", func(p broker.Event) error {
+		received <- string(p.Message().Body)
+		<-release
+		return nil
+	}, broker.Prefetch(1), broker.Concurrency(1))
+	if err != nil {
+		t.Fatalf("Unexpected subscribe error: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	publish := func(body string) {
+		if err := b.Publish("prefetch", &broker.Message{Body: []byte(body)}); err != nil {
+			t.Fatalf("Unexpected publish error: %v", err)
+		}
+	}
+
+	publish("1")
+	if got := <-received; got != "1" {
+		t.Fatalf("Expected 1 got %s", got)
+	}
+
+	// refused while the first is being handled
+	publish("2")
+	select {
+	case got := <-received:
+		t.Fatalf("Expected the message to be held back, got %s", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	// held back messages are sent with the next publish
+	publish("3")
+
+	seen := make(map[string]bool)
+	for len(seen) < 2 {
+		select {
+		case got := <-received:
+			seen[got] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected the held back messages, got %v", seen)
+		}
+	}
+	if !seen["2"] || !seen["3"] {
+		t.Fatalf("Expected 2 and 3 got %v", seen)
+	}
+}
+
 func TestConcurrentSubBroker(t *testing.T) {
 	m := newTestRegistry()
 	b := broker.NewBroker(broker.Registry(m))
@@ -3,6 +3,7 @@ package broker
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
 	"github.com/asim/go-micro/v3/codec"
 	"github.com/asim/go-micro/v3/registry"
@@ -26,6 +27,15 @@ type Options struct {
 }
 
 type PublishOptions struct {
+	// OrderingKey is used to keep messages in order. Messages
+	// with the same key are delivered to the same subscriber
+	// of a queue and processed one at a time in the order published.
+	// Brokers without partitions such as nats only set the
+	// OrderingKeyHeader and don't keep keyed messages in order.
+	OrderingKey string
+	// DeliverAt is when the message should be delivered. Brokers
+	// which can't delay messages deliver them straight away.
+	DeliverAt time.Time
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -39,6 +49,10 @@ type SubscribeOptions struct {
 	// will create a shared subscription where each
 	// receives a subset of messages.
 	Queue string
+	// Concurrency limits the handlers running at once
+	Concurrency int
+	// Prefetch limits the messages received but not yet handled
+	Prefetch int
 
 	// Other options for implementations of the interface
 	// can be stored in a context
@@ -56,6 +70,28 @@ func PublishContext(ctx context.Context) PublishOption {
 	}
 }
 
+// OrderingKey sets the key to keep the message in order with. It's
+// unsupported by nats which passes the key on in the OrderingKeyHeader.
+func OrderingKey(k string) PublishOption {
+	return func(o *PublishOptions) {
+		o.OrderingKey = k
+	}
+}
+
+// DeliverAt delays delivery of the message until the time
+func DeliverAt(t time.Time) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = t
+	}
+}
+
+// Delay delays delivery of the message for the duration
+func Delay(d time.Duration) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = time.Now().Add(d)
+	}
+}
+
 type SubscribeOption func(*SubscribeOptions)
 
 func NewSubscribeOptions(opts ...SubscribeOption) SubscribeOptions {
@@ -128,6 +164,23 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// Concurrency limits the number of handlers running at once
+func Concurrency(n int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Concurrency = n
+	}
+}
+
+// Prefetch limits the number of messages received but not yet handled.
+// Brokers which can't hold messages back refuse them while it's reached.
+// The http broker's publisher keeps refused messages in a capped inbox,
+// retried on its next publish to the topic, so they may be dropped.
+func Prefetch(n int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Prefetch = n
+	}
+}
+
 // SubscribeContext set context
 func SubscribeContext(ctx context.Context) SubscribeOption {
 	return func(o *SubscribeOptions) {
@@ -0,0 +1,59 @@
+package broker
+
+import (
+	"hash/crc32"
+	"sort"
+	"sync"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// keyQueues runs the functions queued for a key one at a time in order
+type keyQueues struct {
+	sync.Mutex
+	queues map[string][]func()
+}
+
+func (k *keyQueues) push(key string, fn func()) {
+	k.Lock()
+	defer k.Unlock()
+
+	if k.queues == nil {
+		k.queues = make(map[string][]func())
+	}
+
+	// already running so wait in line
+	if q, ok := k.queues[key]; ok {
+		k.queues[key] = append(q, fn)
+		return
+	}
+
+	k.queues[key] = nil
+	go k.run(key, fn)
+}
+
+func (k *keyQueues) run(key string, fn func()) {
+	for {
+		fn()
+
+		k.Lock()
+		q := k.queues[key]
+		if len(q) == 0 {
+			delete(k.queues, key)
+			k.Unlock()
+			return
+		}
+		fn = q[0]
+		k.queues[key] = q[1:]
+		k.Unlock()
+	}
+}
+
+// keyNode returns the node the key is assigned to which stays
+// the same for as long as the set of nodes doesn't change
+func keyNode(key string, nodes []*registry.Node) *registry.Node {
+	sorted := make([]*registry.Node, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	return sorted[crc32.ChecksumIEEE([]byte(key))%uint32(len(sorted))]
+}
@@ -0,0 +1,28 @@
+package reply
+
+import (
+	"time"
+)
+
+type Options struct {
+	// Prefix of the topic replies are sent to
+	Prefix string
+	// Timeout of requests without a deadline
+	Timeout time.Duration
+}
+
+type Option func(o *Options)
+
+// Prefix sets the prefix of the reply topic
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// Timeout sets the timeout of requests without a deadline
+func Timeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = d
+	}
+}
@@ -0,0 +1,221 @@
+// Package reply provides request and reply over a broker.
+//
+// A request is published with the topic to reply to and a correlation id in
+// its header. Replies carrying the correlation id are sent back to the
+// requester which waits for one or, to scatter and gather, several of them.
+package reply
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	merr "github.com/asim/go-micro/v3/errors"
+	"github.com/google/uuid"
+)
+
+var (
+	DefaultPrefix  = "micro.reply."
+	DefaultTimeout = time.Second * 5
+
+	// ReplyToHeader is the topic to send the reply to
+	ReplyToHeader = "Micro-Reply-To"
+	// CorrelationHeader matches the reply to its request
+	CorrelationHeader = "Micro-Correlation-Id"
+	// ErrorHeader is set on replies to requests which failed
+	ErrorHeader = "Micro-Error"
+
+	// ErrIncomplete is returned when fewer replies than expected are gathered
+	ErrIncomplete = errors.New("fewer replies than expected")
+)
+
+// Requester publishes requests and waits for their replies
+type Requester struct {
+	opts   Options
+	broker broker.Broker
+	topic  string
+
+	sync.Mutex
+	sub     broker.Subscriber
+	pending map[string]chan *broker.Message
+}
+
+// NewRequester returns a requester which receives
+// replies on a topic of its own on the broker
+func NewRequester(b broker.Broker, opts ...Option) *Requester {
+	options := Options{
+		Prefix:  DefaultPrefix,
+		Timeout: DefaultTimeout,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Requester{
+		opts:    options,
+		broker:  b,
+		topic:   options.Prefix + uuid.New().String(),
+		pending: make(map[string]chan *broker.Message),
+	}
+}
+
+// Topic returns the topic replies are sent to
+func (r *Requester) Topic() string {
+	return r.topic
+}
+
+// subscribe to the reply topic if not already subscribed
+func (r *Requester) subscribe() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.sub != nil {
+		return nil
+	}
+
+	sub, err := r.broker.Subscribe(r.topic, r.handle)
+	if err != nil {
+		return err
+	}
+	r.sub = sub
+
+	return nil
+}
+
+// handle passes a reply to the request waiting for it
+func (r *Requester) handle(e broker.Event) error {
+	msg := e.Message()
+
+	r.Lock()
+	ch, ok := r.pending[msg.Header[CorrelationHeader]]
+	r.Unlock()
+
+	// the request is no longer waiting
+	if !ok {
+		return nil
+	}
+
+	select {
+	case ch <- msg:
+	default:
+	}
+
+	return nil
+}
+
+// send publishes the request and returns the channel its replies are received on
+func (r *Requester) send(topic string, msg *broker.Message, size int, opts ...broker.PublishOption) (string, chan *broker.Message, error) {
+	if err := r.subscribe(); err != nil {
+		return "", nil, err
+	}
+
+	id := uuid.New().String()
+	ch := make(chan *broker.Message, size)
+
+	r.Lock()
+	r.pending[id] = ch
+	r.Unlock()
+
+	header := make(map[string]string, len(msg.Header)+2)
+	for k, v := range msg.Header {
+		header[k] = v
+	}
+	header[ReplyToHeader] = r.topic
+	header[CorrelationHeader] = id
+
+	if err := r.broker.Publish(topic, &broker.Message{Header: header, Body: msg.Body}, opts...); err != nil {
+		r.done(id)
+		return "", nil, err
+	}
+
+	return id, ch, nil
+}
+
+// done stops waiting for replies to the request
+func (r *Requester) done(id string) {
+	r.Lock()
+	delete(r.pending, id)
+	r.Unlock()
+}
+
+// deadline applies the default timeout to contexts without a deadline
+func (r *Requester) deadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.opts.Timeout)
+}
+
+// Request publishes the message to the topic and waits for the reply.
+// A reply to a request which failed is returned as its error.
+func (r *Requester) Request(ctx context.Context, topic string, msg *broker.Message, opts ...broker.PublishOption) (*broker.Message, error) {
+	ctx, cancel := r.deadline(ctx)
+	defer cancel()
+
+	id, ch, err := r.send(topic, msg, 1, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.done(id)
+
+	select {
+	case rsp := <-ch:
+		if e := rsp.Header[ErrorHeader]; len(e) > 0 {
+			return rsp, merr.Parse(e)
+		}
+		return rsp, nil
+	case <-ctx.Done():
+		return nil, merr.Timeout("go.micro.broker", "no reply to %s: %v", topic, ctx.Err())
+	}
+}
+
+// Gather publishes the message to the topic and collects n replies. Replies
+// received by the deadline are returned with ErrIncomplete if there are fewer.
+// With n of zero every reply received by the deadline is returned.
+func (r *Requester) Gather(ctx context.Context, topic string, msg *broker.Message, n int, opts ...broker.PublishOption) ([]*broker.Message, error) {
+	ctx, cancel := r.deadline(ctx)
+	defer cancel()
+
+	size := n
+	if size <= 0 {
+		size = 64
+	}
+
+	id, ch, err := r.send(topic, msg, size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.done(id)
+
+	var replies []*broker.Message
+
+	for n <= 0 || len(replies) < n {
+		select {
+		case rsp := <-ch:
+			replies = append(replies, rsp)
+		case <-ctx.Done():
+			if n > 0 {
+				return replies, ErrIncomplete
+			}
+			return replies, nil
+		}
+	}
+
+	return replies, nil
+}
+
+// Close unsubscribes from the reply topic
+func (r *Requester) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.sub == nil {
+		return nil
+	}
+
+	err := r.sub.Unsubscribe()
+	r.sub = nil
+	return err
+}
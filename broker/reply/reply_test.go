@@ -0,0 +1,115 @@
+package reply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	merr "github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func newBroker(t *testing.T, r registry.Registry) broker.Broker {
+	b := broker.NewBroker(broker.Registry(r))
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestRequest(t *testing.T) {
+	r := registry.NewMemoryRegistry()
+	b := newBroker(t, r)
+	defer b.Disconnect()
+
+	sub, err := Subscribe(b, "greeter", func(ctx context.Context, msg *broker.Message) (*broker.Message, error) {
+		if _, ok := metadata.Get(ctx, "Name"); !ok {
+			return nil, merr.BadRequest("greeter", "name required")
+		}
+		return &broker.Message{Body: append([]byte("hello "), msg.Body...)}, nil
+	}, broker.Queue("greeter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	req := NewRequester(b)
+	defer req.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	rsp, err := req.Request(ctx, "greeter", &broker.Message{
+		Header: map[string]string{"Name": "john"},
+		Body:   []byte("john"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rsp.Body) != "hello john" {
+		t.Fatalf("expected hello john got %s", rsp.Body)
+	}
+
+	// handler errors are returned
+	_, err = req.Request(ctx, "greeter", &broker.Message{Body: []byte("john")})
+	if e := merr.FromError(err); e.Code != 400 || e.Detail != "name required" {
+		t.Fatalf("expected the handler error got %v", err)
+	}
+
+	// requests without a reply time out
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = req.Request(short, "nobody", &broker.Message{})
+	if merr.FromError(err).Code != 408 {
+		t.Fatalf("expected a timeout got %v", err)
+	}
+}
+
+func TestGather(t *testing.T) {
+	r := registry.NewMemoryRegistry()
+
+	// each subscriber is on a broker of its own
+	for i := 0; i < 3; i++ {
+		b := newBroker(t, r)
+		defer b.Disconnect()
+
+		i := i
+		if _, err := Subscribe(b, "quotes", func(ctx context.Context, msg *broker.Message) (*broker.Message, error) {
+			return &broker.Message{Body: []byte(fmt.Sprintf("%d", i))}, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := newBroker(t, r)
+	defer b.Disconnect()
+
+	req := NewRequester(b)
+	defer req.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	replies, err := req.Gather(ctx, "quotes", &broker.Message{}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, rsp := range replies {
+		seen[string(rsp.Body)] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected a reply from each subscriber got %v", seen)
+	}
+
+	// fewer replies than expected by the deadline
+	short, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	replies, err = req.Gather(short, "quotes", &broker.Message{}, 4)
+	if err != ErrIncomplete || len(replies) != 3 {
+		t.Fatalf("expected 3 replies and ErrIncomplete got %d %v", len(replies), err)
+	}
+}
@@ -0,0 +1,49 @@
+package reply
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/broker"
+	merr "github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+// Handler handles a request and returns the reply
+type Handler func(ctx context.Context, msg *broker.Message) (*broker.Message, error)
+
+// Subscribe subscribes the handler to the topic and publishes what it returns
+// as the reply. A returned error is sent back as the reply in ErrorHeader.
+// Messages without a reply topic are handled without a reply.
+func Subscribe(b broker.Broker, topic string, h Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return b.Subscribe(topic, func(e broker.Event) error {
+		msg := e.Message()
+		ctx := metadata.NewContext(context.Background(), metadata.Copy(msg.Header))
+
+		rsp, err := h(ctx, msg)
+
+		replyTo := msg.Header[ReplyToHeader]
+		if len(replyTo) == 0 {
+			return err
+		}
+
+		header := make(map[string]string)
+		var body []byte
+		if rsp != nil {
+			for k, v := range rsp.Header {
+				header[k] = v
+			}
+			body = rsp.Body
+		}
+		header[CorrelationHeader] = msg.Header[CorrelationHeader]
+
+		if err != nil {
+			e := *merr.FromError(err)
+			if len(e.Id) == 0 {
+				e.Id = topic
+			}
+			header[ErrorHeader] = e.Error()
+		}
+
+		return b.Publish(replyTo, &broker.Message{Header: header, Body: body})
+	}, opts...)
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/sync"
+)
+
+type Options struct {
+	// Store the scheduled messages are kept in, they're
+	// lost on restart unless the store is persistent
+	Store store.Store
+	// Sync elects the instance which delivers the messages.
+	// Every instance delivers them when it's nil.
+	Sync sync.Sync
+	// Prefix of the keys in the store and the leader id
+	Prefix string
+	// Interval between checks for messages which are due
+	Interval time.Duration
+}
+
+type Option func(o *Options)
+
+// Store sets the store to keep scheduled messages in
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Sync sets the sync used to elect the instance delivering messages
+func Sync(s sync.Sync) Option {
+	return func(o *Options) {
+		o.Sync = s
+	}
+}
+
+// Prefix sets the prefix of the keys in the store
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// Interval sets how often to check for messages which are due
+func Interval(d time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = d
+	}
+}
@@ -0,0 +1,294 @@
+// Package scheduler delays the delivery of messages published with a deliver time.
+//
+// The messages are saved in a store until they're due. They only survive
+// restarts with a persistent store, the default store.DefaultStore is in
+// memory so set one with the Store option. When a sync is set only the
+// elected leader delivers them so each message is published once by one of
+// the instances sharing the store.
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/sync"
+	"github.com/google/uuid"
+)
+
+var (
+	DefaultPrefix   = "scheduler/"
+	DefaultInterval = time.Second
+)
+
+// message is a scheduled message as kept in the store
+type message struct {
+	Topic       string          `json:"topic"`
+	Message     *broker.Message `json:"message"`
+	OrderingKey string          `json:"ordering_key,omitempty"`
+	DeliverAt   time.Time       `json:"deliver_at"`
+}
+
+type scheduler struct {
+	broker.Broker
+	opts Options
+
+	gosync.Mutex
+	exit chan bool
+	done chan bool
+}
+
+// NewBroker returns a broker which keeps messages in the store until they're due
+// before publishing them to the broker. Other messages are published straight away.
+// Without the Store option messages are kept in store.DefaultStore.
+func NewBroker(b broker.Broker, opts ...Option) broker.Broker {
+	options := Options{
+		Store:    store.DefaultStore,
+		Prefix:   DefaultPrefix,
+		Interval: DefaultInterval,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &scheduler{
+		Broker: b,
+		opts:   options,
+	}
+}
+
+// key sorts the messages by the time they're due
+func (s *scheduler) key(m *message) string {
+	return fmt.Sprintf("%s%020d/%s", s.opts.Prefix, m.DeliverAt.UnixNano(), uuid.New().String())
+}
+
+// due returns the time a key is due from its name
+func (s *scheduler) due(key string) (time.Time, error) {
+	parts := strings.SplitN(strings.TrimPrefix(key, s.opts.Prefix), "/", 2)
+	ns, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, ns), nil
+}
+
+func (s *scheduler) Connect() error {
+	if err := s.Broker.Connect(); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.exit != nil {
+		return nil
+	}
+
+	s.exit = make(chan bool)
+	s.done = make(chan bool)
+	go s.run(s.exit, s.done)
+
+	return nil
+}
+
+func (s *scheduler) Disconnect() error {
+	s.Lock()
+	exit, done := s.exit, s.done
+	s.exit = nil
+	s.Unlock()
+
+	if exit != nil {
+		close(exit)
+		<-done
+	}
+
+	return s.Broker.Disconnect()
+}
+
+func (s *scheduler) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if !options.DeliverAt.After(time.Now()) {
+		return s.Broker.Publish(topic, msg, opts...)
+	}
+
+	m := &message{
+		Topic:       topic,
+		Message:     msg,
+		OrderingKey: options.OrderingKey,
+		DeliverAt:   options.DeliverAt,
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return s.opts.Store.Write(&store.Record{
+		Key:   s.key(m),
+		Value: b,
+	})
+}
+
+// run delivers the messages while this instance is the leader
+func (s *scheduler) run(exit, done chan bool) {
+	defer close(done)
+
+	for {
+		lost, resign, ok := s.elect(exit)
+		if !ok {
+			return
+		}
+
+		s.deliver(exit, lost)
+		resign()
+
+		select {
+		case <-exit:
+			return
+		default:
+		}
+	}
+}
+
+// elect waits to become the leader. It returns a channel closed when the
+// leadership is lost, the func to resign and false if the scheduler exits.
+func (s *scheduler) elect(exit chan bool) (chan bool, func(), bool) {
+	if s.opts.Sync == nil {
+		return nil, func() {}, true
+	}
+
+	for {
+		type result struct {
+			leader sync.Leader
+			err    error
+		}
+		ch := make(chan result, 1)
+
+		go func() {
+			l, err := s.opts.Sync.Leader(strings.TrimSuffix(s.opts.Prefix, "/"))
+			ch <- result{l, err}
+		}()
+
+		select {
+		case r := <-ch:
+			if r.err == nil {
+				return r.leader.Status(), func() { r.leader.Resign() }, true
+			}
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("[scheduler] failed to elect leader: %v", r.err)
+			}
+		case <-exit:
+			// resign if elected after exiting
+			go func() {
+				if r := <-ch; r.err == nil {
+					r.leader.Resign()
+				}
+			}()
+			return nil, nil, false
+		}
+
+		select {
+		case <-exit:
+			return nil, nil, false
+		case <-time.After(s.opts.Interval):
+		}
+	}
+}
+
+// deliver publishes the messages as they're due until leadership is lost
+func (s *scheduler) deliver(exit, lost chan bool) {
+	t := time.NewTicker(s.opts.Interval)
+	defer t.Stop()
+
+	for {
+		if err := s.publish(lost); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("[scheduler] failed to publish scheduled messages: %v", err)
+			}
+		}
+
+		select {
+		case <-exit:
+			return
+		case <-lost:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// publish publishes the messages which are due in the order they're due. It
+// stops as soon as the leadership is lost so another leader can take over.
+func (s *scheduler) publish(lost chan bool) error {
+	keys, err := s.opts.Store.List(store.ListPrefix(s.opts.Prefix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(keys)
+
+	now := time.Now()
+
+	for _, key := range keys {
+		select {
+		case <-lost:
+			return nil
+		default:
+		}
+
+		due, err := s.due(key)
+		if err != nil {
+			continue
+		}
+		if due.After(now) {
+			return nil
+		}
+
+		recs, err := s.opts.Store.Read(key)
+		if err == store.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		m := new(message)
+		if err := json.Unmarshal(recs[0].Value, m); err != nil {
+			// it can never be delivered
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("[scheduler] dropping invalid message %s: %v", key, err)
+			}
+			s.opts.Store.Delete(key)
+			continue
+		}
+
+		var opts []broker.PublishOption
+		if len(m.OrderingKey) > 0 {
+			opts = append(opts, broker.OrderingKey(m.OrderingKey))
+		}
+
+		// stop at the first failure so the messages stay in order
+		if err := s.Broker.Publish(m.Topic, m.Message, opts...); err != nil {
+			return err
+		}
+
+		if err := s.opts.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *scheduler) String() string {
+	return s.Broker.String()
+}
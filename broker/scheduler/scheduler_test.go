@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/sync"
+)
+
+// testBroker records the messages published to it
+type testBroker struct {
+	broker.Broker
+
+	gosync.Mutex
+	published []string
+}
+
+func (b *testBroker) Connect() error    { return nil }
+func (b *testBroker) Disconnect() error { return nil }
+func (b *testBroker) String() string    { return "test" }
+
+func (b *testBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	b.Lock()
+	defer b.Unlock()
+	b.published = append(b.published, string(msg.Body))
+	return nil
+}
+
+func (b *testBroker) messages() []string {
+	b.Lock()
+	defer b.Unlock()
+	return append([]string(nil), b.published...)
+}
+
+// testSync elects one leader at a time
+type testSync struct {
+	sync.Sync
+	lock chan bool
+}
+
+type testLeader struct {
+	once   gosync.Once
+	lock   chan bool
+	status chan bool
+}
+
+func (s *testSync) Leader(id string, opts ...sync.LeaderOption) (sync.Leader, error) {
+	s.lock <- true
+	return &testLeader{lock: s.lock, status: make(chan bool)}, nil
+}
+
+func (l *testLeader) Resign() error {
+	l.once.Do(func() { <-l.lock })
+	return nil
+}
+
+func (l *testLeader) Status() chan bool {
+	return l.status
+}
+
+func publish(t *testing.T, b broker.Broker, body string, opts ...broker.PublishOption) {
+	if err := b.Publish("test", &broker.Message{Body: []byte(body)}, opts...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func wait(t *testing.T, b *testBroker, want ...string) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := b.messages()
+		if len(got) >= len(want) {
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("expected %v got %v", want, got)
+				}
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %v got %v", want, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	st := store.NewMemoryStore()
+	tb := &testBroker{}
+	b := NewBroker(tb, Store(st), Interval(10*time.Millisecond))
+
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	publish(t, b, "now")
+	wait(t, tb, "now")
+
+	publish(t, b, "later", broker.Delay(100*time.Millisecond))
+	publish(t, b, "soon", broker.Delay(50*time.Millisecond))
+
+	time.Sleep(20 * time.Millisecond)
+	if got := tb.messages(); len(got) != 1 {
+		t.Fatalf("expected the delayed messages to be held, got %v", got)
+	}
+
+	wait(t, tb, "now", "soon", "later")
+
+	keys, err := st.List(store.ListPrefix(DefaultPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("expected delivered messages to be removed, got %v", keys)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	st := store.NewMemoryStore()
+
+	// scheduled but stopped before it was due
+	b := NewBroker(&testBroker{}, Store(st), Interval(10*time.Millisecond))
+	publish(t, b, "1", broker.DeliverAt(time.Now().Add(50*time.Millisecond)))
+
+	tb := &testBroker{}
+	b = NewBroker(tb, Store(st), Interval(10*time.Millisecond))
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	wait(t, tb, "1")
+}
+
+func TestLeader(t *testing.T) {
+	st := store.NewMemoryStore()
+	sy := &testSync{lock: make(chan bool, 1)}
+
+	var brokers []*testBroker
+	for i := 0; i < 3; i++ {
+		tb := &testBroker{}
+		b := NewBroker(tb, Store(st), Sync(sy), Interval(10*time.Millisecond))
+		if err := b.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer b.Disconnect()
+		brokers = append(brokers, tb)
+
+		publish(t, b, string(rune('a'+i)), broker.Delay(20*time.Millisecond))
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	var got []string
+	var leaders int
+	for _, tb := range brokers {
+		if msgs := tb.messages(); len(msgs) > 0 {
+			leaders++
+			got = append(got, msgs...)
+		}
+	}
+	if leaders != 1 {
+		t.Fatalf("expected one instance to deliver, got %d", leaders)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected each message to be delivered once, got %v", got)
+	}
+}
+
+func TestLostLeadership(t *testing.T) {
+	st := store.NewMemoryStore()
+	tb := &testBroker{}
+	b := NewBroker(tb, Store(st))
+
+	publish(t, b, "1", broker.Delay(10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	lost := make(chan bool)
+	close(lost)
+
+	if err := b.(*scheduler).publish(lost); err != nil {
+		t.Fatal(err)
+	}
+	if got := tb.messages(); len(got) != 0 {
+		t.Fatalf("expected nothing to be published after losing the leadership, got %v", got)
+	}
+
+	keys, err := st.List(store.ListPrefix(DefaultPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("expected the message to be kept for the next leader, got %v", keys)
+	}
+}
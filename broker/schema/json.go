@@ -0,0 +1,234 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// jsonSchema is the subset of JSON schema used to describe payloads
+type jsonSchema struct {
+	Type                 interface{}            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+}
+
+func parseJSON(b []byte) (*jsonSchema, error) {
+	js := new(jsonSchema)
+	if err := json.Unmarshal(b, js); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %v", err)
+	}
+	if _, err := js.types(); err != nil {
+		return nil, err
+	}
+	return js, nil
+}
+
+// types returns the allowed types, none means any type
+func (s *jsonSchema) types() ([]string, error) {
+	switch t := s.Type.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{t}, nil
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			str, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid json schema type %v", v)
+			}
+			types = append(types, str)
+		}
+		return types, nil
+	default:
+		return nil, fmt.Errorf("invalid json schema type %v", t)
+	}
+}
+
+func (s *jsonSchema) required(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *jsonSchema) closed() bool {
+	return s.AdditionalProperties != nil && !*s.AdditionalProperties
+}
+
+func allows(types []string, t string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, v := range types {
+		// integers are numbers
+		if v == t || (v == "number" && t == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func field(path, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
+func where(path string) string {
+	if len(path) == 0 {
+		return "payload"
+	}
+	return path
+}
+
+// compareJSON returns why the reader can't read what the writer wrote
+func compareJSON(writer, reader *jsonSchema, path string) []string {
+	var reasons []string
+
+	wt, _ := writer.types()
+	rt, _ := reader.types()
+	if len(rt) > 0 {
+		if len(wt) == 0 {
+			reasons = append(reasons, fmt.Sprintf("%s changed from any type to %v", where(path), rt))
+		}
+		for _, t := range wt {
+			if !allows(rt, t) {
+				reasons = append(reasons, fmt.Sprintf("%s changed type from %v to %v", where(path), wt, rt))
+				break
+			}
+		}
+	}
+
+	if len(reader.Enum) > 0 {
+		if len(writer.Enum) == 0 {
+			reasons = append(reasons, fmt.Sprintf("%s is restricted to %v", where(path), reader.Enum))
+		}
+		for _, v := range writer.Enum {
+			if !contains(reader.Enum, v) {
+				reasons = append(reasons, fmt.Sprintf("%s no longer allows %v", where(path), v))
+			}
+		}
+	}
+
+	for _, name := range reader.Required {
+		if !writer.required(name) {
+			reasons = append(reasons, fmt.Sprintf("%s is required but may be missing", field(path, name)))
+		}
+	}
+
+	names := make([]string, 0, len(writer.Properties))
+	for name := range writer.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r, ok := reader.Properties[name]
+		if !ok {
+			if reader.closed() {
+				reasons = append(reasons, fmt.Sprintf("%s is not allowed", field(path, name)))
+			}
+			continue
+		}
+		reasons = append(reasons, compareJSON(writer.Properties[name], r, field(path, name))...)
+	}
+
+	if writer.Items != nil && reader.Items != nil {
+		reasons = append(reasons, compareJSON(writer.Items, reader.Items, path+"[]")...)
+	}
+
+	return reasons
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	b, _ := json.Marshal(v)
+	for _, e := range values {
+		c, _ := json.Marshal(e)
+		if string(b) == string(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON schema type of a value decoded with UseNumber
+func typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := t.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// validateJSON checks the value decoded with UseNumber matches the schema
+func validateJSON(s *jsonSchema, v interface{}, path string) error {
+	types, _ := s.types()
+	if t := typeOf(v); !allows(types, t) {
+		return fmt.Errorf("%s is %s, expected %v", where(path), t, types)
+	}
+
+	if len(s.Enum) > 0 {
+		// compare numbers by value rather than by representation
+		var val interface{} = v
+		if n, ok := v.(json.Number); ok {
+			val, _ = n.Float64()
+		}
+		if !contains(s.Enum, val) {
+			return fmt.Errorf("%s is not one of %v", where(path), s.Enum)
+		}
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := t[name]; !ok {
+				return fmt.Errorf("%s is required", field(path, name))
+			}
+		}
+		for name, val := range t {
+			p, ok := s.Properties[name]
+			if !ok {
+				if s.closed() {
+					return fmt.Errorf("%s is not allowed", field(path, name))
+				}
+				continue
+			}
+			if err := validateJSON(p, val, field(path, name)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items == nil {
+			return nil
+		}
+		for i, val := range t {
+			if err := validateJSON(s.Items, val, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+)
+
+type Options struct {
+	// Store the schemas are saved in
+	Store store.Store
+	// Prefix of the keys in the store
+	Prefix string
+	// Compatibility required between a new version and the previous one
+	Compatibility Compatibility
+	// CacheTTL is how long the latest version of a topic is cached for
+	CacheTTL time.Duration
+}
+
+type Option func(o *Options)
+
+// Store sets the store the schemas are saved in
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Prefix sets the prefix of the keys in the store
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// WithCompatibility sets the compatibility required when registering a new version
+func WithCompatibility(c Compatibility) Option {
+	return func(o *Options) {
+		o.Compatibility = c
+	}
+}
+
+// CacheTTL sets how long the latest version of a topic is cached for
+func CacheTTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.CacheTTL = d
+	}
+}
+
+type WrapperOptions struct {
+	// Require rejects messages published without a schema id
+	Require bool
+	// Readers pins the version a subscriber reads of a topic
+	Readers map[string]int
+}
+
+type WrapperOption func(o *WrapperOptions)
+
+// Require rejects messages of registered topics published without a schema id
+func Require() WrapperOption {
+	return func(o *WrapperOptions) {
+		o.Require = true
+	}
+}
+
+// Reader pins the version of the topic the subscriber was built against.
+// By default the latest version is used.
+func Reader(topic string, version int) WrapperOption {
+	return func(o *WrapperOptions) {
+		if o.Readers == nil {
+			o.Readers = make(map[string]int)
+		}
+		o.Readers[topic] = version
+	}
+}
@@ -0,0 +1,216 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// protoSchema is a protobuf message and the messages it references
+type protoSchema struct {
+	name     string
+	messages map[string]*descriptor.DescriptorProto
+}
+
+func parseProto(b []byte, message string) (*protoSchema, error) {
+	if len(message) == 0 {
+		return nil, fmt.Errorf("protobuf schema requires a message name")
+	}
+
+	set := new(descriptor.FileDescriptorSet)
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor: %v", err)
+	}
+
+	ps := &protoSchema{
+		name:     strings.TrimPrefix(message, "."),
+		messages: make(map[string]*descriptor.DescriptorProto),
+	}
+	for _, f := range set.File {
+		prefix := f.GetPackage()
+		for _, m := range f.MessageType {
+			ps.add(prefix, m)
+		}
+	}
+	if _, ok := ps.messages[ps.name]; !ok {
+		return nil, fmt.Errorf("protobuf message %s not found in descriptor", ps.name)
+	}
+
+	return ps, nil
+}
+
+func (p *protoSchema) add(prefix string, m *descriptor.DescriptorProto) {
+	name := m.GetName()
+	if len(prefix) > 0 {
+		name = prefix + "." + name
+	}
+	p.messages[name] = m
+	for _, n := range m.NestedType {
+		p.add(name, n)
+	}
+}
+
+func (p *protoSchema) message(typeName string) *descriptor.DescriptorProto {
+	return p.messages[strings.TrimPrefix(typeName, ".")]
+}
+
+func fields(m *descriptor.DescriptorProto) map[int32]*descriptor.FieldDescriptorProto {
+	f := make(map[int32]*descriptor.FieldDescriptorProto, len(m.Field))
+	for _, fd := range m.Field {
+		f[fd.GetNumber()] = fd
+	}
+	return f
+}
+
+func numbers(f map[int32]*descriptor.FieldDescriptorProto) []int {
+	n := make([]int, 0, len(f))
+	for k := range f {
+		n = append(n, int(k))
+	}
+	sort.Ints(n)
+	return n
+}
+
+// compareProto returns why the reader can't read what the writer wrote.
+// Fields are matched by number since names aren't part of the wire format.
+func compareProto(writer, reader *protoSchema) []string {
+	return compareMessage(writer, reader, writer.message(writer.name), reader.message(reader.name), reader.name, map[string]bool{})
+}
+
+func compareMessage(writer, reader *protoSchema, w, r *descriptor.DescriptorProto, path string, seen map[string]bool) []string {
+	// recursive messages are only compared once
+	if seen[path] {
+		return nil
+	}
+	seen[path] = true
+
+	var reasons []string
+	wf := fields(w)
+	rf := fields(r)
+
+	for _, n := range numbers(rf) {
+		rd := rf[int32(n)]
+		wd, ok := wf[int32(n)]
+		name := path + "." + rd.GetName()
+
+		if !ok {
+			if rd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REQUIRED {
+				reasons = append(reasons, fmt.Sprintf("%s is required but may be missing", name))
+			}
+			continue
+		}
+		if rd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REQUIRED && wd.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REQUIRED {
+			reasons = append(reasons, fmt.Sprintf("%s is required but may be missing", name))
+		}
+		if (rd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED) != (wd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED) {
+			reasons = append(reasons, fmt.Sprintf("%s changed label from %s to %s", name, wd.GetLabel(), rd.GetLabel()))
+			continue
+		}
+		if rd.GetType() != wd.GetType() {
+			reasons = append(reasons, fmt.Sprintf("%s changed type from %s to %s", name, wd.GetType(), rd.GetType()))
+			continue
+		}
+		if rd.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+			wm := writer.message(wd.GetTypeName())
+			rm := reader.message(rd.GetTypeName())
+			if wm == nil || rm == nil {
+				reasons = append(reasons, fmt.Sprintf("%s references an unknown message", name))
+				continue
+			}
+			reasons = append(reasons, compareMessage(writer, reader, wm, rm, name, seen)...)
+		}
+	}
+
+	return reasons
+}
+
+// wireType returns the wire type a field is encoded with
+func wireType(t descriptor.FieldDescriptorProto_Type) uint64 {
+	switch t {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return proto.WireFixed64
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT,
+		descriptor.FieldDescriptorProto_TYPE_FIXED32,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return proto.WireFixed32
+	case descriptor.FieldDescriptorProto_TYPE_STRING,
+		descriptor.FieldDescriptorProto_TYPE_BYTES,
+		descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		return proto.WireBytes
+	case descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return proto.WireStartGroup
+	default:
+		return proto.WireVarint
+	}
+}
+
+// validateProto walks the wire format of the payload checking the known
+// fields are encoded as the descriptor says and required fields are set.
+func validateProto(p *protoSchema, b []byte) error {
+	return validateMessage(p, p.message(p.name), b, p.name)
+}
+
+func validateMessage(p *protoSchema, m *descriptor.DescriptorProto, b []byte, path string) error {
+	f := fields(m)
+	set := make(map[int32]bool)
+	buf := proto.NewBuffer(b)
+
+	for len(buf.Unread()) > 0 {
+		tag, err := buf.DecodeVarint()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		num, wt := int32(tag>>3), tag&7
+
+		fd, known := f[num]
+		if known {
+			want := wireType(fd.GetType())
+			// repeated scalars may be packed
+			packed := wt == proto.WireBytes && fd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED && want != proto.WireBytes
+			if wt != want && !packed {
+				return fmt.Errorf("%s.%s has wire type %d, expected %d", path, fd.GetName(), wt, want)
+			}
+			set[num] = true
+		}
+
+		switch wt {
+		case proto.WireVarint:
+			_, err = buf.DecodeVarint()
+		case proto.WireFixed64:
+			_, err = buf.DecodeFixed64()
+		case proto.WireFixed32:
+			_, err = buf.DecodeFixed32()
+		case proto.WireBytes:
+			var v []byte
+			v, err = buf.DecodeRawBytes(false)
+			if err == nil && known && fd.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+				nm := p.message(fd.GetTypeName())
+				if nm == nil {
+					return fmt.Errorf("%s.%s references an unknown message", path, fd.GetName())
+				}
+				if err := validateMessage(p, nm, v, path+"."+fd.GetName()); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%s: unsupported wire type %d", path, wt)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	for _, n := range numbers(f) {
+		fd := f[int32(n)]
+		if fd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REQUIRED && !set[int32(n)] {
+			return fmt.Errorf("%s.%s is required", path, fd.GetName())
+		}
+	}
+
+	return nil
+}
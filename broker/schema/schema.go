@@ -0,0 +1,395 @@
+// Package schema is a registry of the payload schemas of event topics.
+//
+// Every topic has a list of versioned schemas kept in a store. A new version is
+// only accepted when it's compatible with the previous one. Publishers stamp the
+// id of the schema they wrote the payload with and subscribers check the payload
+// against it before handling the message.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+)
+
+var (
+	DefaultPrefix   = "schema/"
+	DefaultCacheTTL = time.Minute
+
+	// ErrNotFound is returned when a schema doesn't exist
+	ErrNotFound = errors.New("schema not found")
+)
+
+// Format of a schema definition
+type Format string
+
+const (
+	// JSONSchema definitions are JSON schema documents
+	JSONSchema Format = "jsonschema"
+	// Protobuf definitions are serialized FileDescriptorSets
+	Protobuf Format = "protobuf"
+)
+
+// Compatibility between two versions of a schema
+type Compatibility int
+
+const (
+	// None allows any change
+	None Compatibility = iota
+	// Backward means the new version can read data written with the old one
+	Backward
+	// Forward means the old version can read data written with the new one
+	Forward
+	// Full is both backward and forward
+	Full
+)
+
+func (c Compatibility) String() string {
+	switch c {
+	case None:
+		return "none"
+	case Backward:
+		return "backward"
+	case Forward:
+		return "forward"
+	case Full:
+		return "full"
+	default:
+		return "unknown"
+	}
+}
+
+// Schema is a version of the payload schema of a topic
+type Schema struct {
+	// Id is the topic and version e.g. orders@2
+	Id      string `json:"id"`
+	Topic   string `json:"topic"`
+	Version int    `json:"version"`
+	Format  Format `json:"format"`
+	// Message is the full name of the protobuf message
+	Message    string    `json:"message,omitempty"`
+	Definition []byte    `json:"definition"`
+	Created    time.Time `json:"created"`
+}
+
+// IncompatibleError lists why two versions of a schema are incompatible
+type IncompatibleError struct {
+	Reasons []string
+}
+
+func (e *IncompatibleError) Error() string {
+	return "incompatible schema: " + strings.Join(e.Reasons, "; ")
+}
+
+// Compatible checks the new version of a schema against the old one
+func Compatible(old, new *Schema, c Compatibility) error {
+	if c == None {
+		return nil
+	}
+	if old.Format != new.Format {
+		return &IncompatibleError{[]string{fmt.Sprintf("format changed from %s to %s", old.Format, new.Format)}}
+	}
+
+	var reasons []string
+
+	// backward: the new version reads what the old one wrote
+	if c == Backward || c == Full {
+		r, err := readable(old, new)
+		if err != nil {
+			return err
+		}
+		reasons = append(reasons, r...)
+	}
+	// forward: the old version reads what the new one writes
+	if c == Forward || c == Full {
+		r, err := readable(new, old)
+		if err != nil {
+			return err
+		}
+		reasons = append(reasons, r...)
+	}
+
+	if len(reasons) > 0 {
+		return &IncompatibleError{reasons}
+	}
+	return nil
+}
+
+// readable returns why the reader can't read what the writer wrote
+func readable(writer, reader *Schema) ([]string, error) {
+	switch writer.Format {
+	case JSONSchema:
+		w, err := parseJSON(writer.Definition)
+		if err != nil {
+			return nil, err
+		}
+		r, err := parseJSON(reader.Definition)
+		if err != nil {
+			return nil, err
+		}
+		return compareJSON(w, r, ""), nil
+	case Protobuf:
+		w, err := parseProto(writer.Definition, writer.Message)
+		if err != nil {
+			return nil, err
+		}
+		r, err := parseProto(reader.Definition, reader.Message)
+		if err != nil {
+			return nil, err
+		}
+		return compareProto(w, r), nil
+	default:
+		return nil, fmt.Errorf("unknown schema format %q", writer.Format)
+	}
+}
+
+// Validate checks the payload matches the schema
+func (s *Schema) Validate(payload []byte) error {
+	switch s.Format {
+	case JSONSchema:
+		js, err := parseJSON(s.Definition)
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(payload))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return fmt.Errorf("invalid json: %v", err)
+		}
+		return validateJSON(js, v, "")
+	case Protobuf:
+		p, err := parseProto(s.Definition, s.Message)
+		if err != nil {
+			return err
+		}
+		return validateProto(p, payload)
+	default:
+		return fmt.Errorf("unknown schema format %q", s.Format)
+	}
+}
+
+// parse checks the definition can be read
+func (s *Schema) parse() error {
+	switch s.Format {
+	case JSONSchema:
+		_, err := parseJSON(s.Definition)
+		return err
+	case Protobuf:
+		_, err := parseProto(s.Definition, s.Message)
+		return err
+	default:
+		return fmt.Errorf("unknown schema format %q", s.Format)
+	}
+}
+
+type latest struct {
+	schema  *Schema
+	expires time.Time
+}
+
+// Registry keeps the schemas of topics in a store
+type Registry struct {
+	opts Options
+
+	sync.RWMutex
+	// schemas by id, they never change once registered
+	schemas map[string]*Schema
+	latest  map[string]latest
+}
+
+// NewRegistry returns a registry of schemas saved in the store
+func NewRegistry(opts ...Option) *Registry {
+	options := Options{
+		Store:         store.DefaultStore,
+		Prefix:        DefaultPrefix,
+		Compatibility: Backward,
+		CacheTTL:      DefaultCacheTTL,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Registry{
+		opts:    options,
+		schemas: make(map[string]*Schema),
+		latest:  make(map[string]latest),
+	}
+}
+
+// Options returns the options of the registry
+func (r *Registry) Options() Options {
+	return r.opts
+}
+
+// Register adds a new version of the schema of the topic. The version is
+// checked against the latest one with the compatibility of the registry.
+// Registering the latest definition again returns the existing version.
+func (r *Registry) Register(topic string, format Format, definition []byte, message ...string) (*Schema, error) {
+	s := &Schema{
+		Topic:      topic,
+		Format:     format,
+		Definition: definition,
+	}
+	if len(message) > 0 {
+		s.Message = message[0]
+	}
+	if err := s.parse(); err != nil {
+		return nil, err
+	}
+
+	prev, err := r.load(topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(prev) > 0 {
+		last := prev[len(prev)-1]
+		if last.Format == s.Format && last.Message == s.Message && bytes.Equal(last.Definition, s.Definition) {
+			return last, nil
+		}
+		if err := Compatible(last, s, r.opts.Compatibility); err != nil {
+			return nil, err
+		}
+		s.Version = last.Version + 1
+	} else {
+		s.Version = 1
+	}
+
+	s.Id = id(topic, s.Version)
+	s.Created = time.Now()
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.opts.Store.Write(&store.Record{Key: r.key(topic, s.Version), Value: b}); err != nil {
+		return nil, err
+	}
+
+	r.Lock()
+	r.schemas[s.Id] = s
+	r.latest[topic] = latest{s, time.Now().Add(r.opts.CacheTTL)}
+	r.Unlock()
+
+	return s, nil
+}
+
+// Get returns the schema with the id
+func (r *Registry) Get(sid string) (*Schema, error) {
+	r.RLock()
+	s, ok := r.schemas[sid]
+	r.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	i := strings.LastIndex(sid, "@")
+	if i <= 0 {
+		return nil, ErrNotFound
+	}
+	v, err := strconv.Atoi(sid[i+1:])
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	recs, err := r.opts.Store.Read(r.key(sid[:i], v))
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	s = new(Schema)
+	if err := json.Unmarshal(recs[0].Value, s); err != nil {
+		return nil, err
+	}
+
+	r.Lock()
+	r.schemas[s.Id] = s
+	r.Unlock()
+
+	return s, nil
+}
+
+// Latest returns the latest version of the schema of the topic
+func (r *Registry) Latest(topic string) (*Schema, error) {
+	r.RLock()
+	l, ok := r.latest[topic]
+	r.RUnlock()
+	if ok && time.Now().Before(l.expires) {
+		return l.schema, nil
+	}
+
+	versions, err := r.load(topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	s := versions[len(versions)-1]
+	r.Lock()
+	r.latest[topic] = latest{s, time.Now().Add(r.opts.CacheTTL)}
+	r.Unlock()
+
+	return s, nil
+}
+
+// Versions returns every version of the schema of the topic, oldest first
+func (r *Registry) Versions(topic string) ([]*Schema, error) {
+	return r.load(topic)
+}
+
+func (r *Registry) load(topic string) ([]*Schema, error) {
+	prefix := r.opts.Prefix + topic + "/"
+	keys, err := r.opts.Store.List(store.ListPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	var schemas []*Schema
+	for _, k := range keys {
+		// skip the keys of topics nested under this one
+		if !strings.HasPrefix(k, prefix) || strings.Contains(k[len(prefix):], "/") {
+			continue
+		}
+		recs, err := r.opts.Store.Read(k)
+		if err == store.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, rec := range recs {
+			s := new(Schema)
+			if err := json.Unmarshal(rec.Value, s); err != nil {
+				return nil, err
+			}
+			schemas = append(schemas, s)
+		}
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Version < schemas[j].Version
+	})
+
+	return schemas, nil
+}
+
+func (r *Registry) key(topic string, version int) string {
+	// zero padded so the keys sort by version
+	return fmt.Sprintf("%s%s/%010d", r.opts.Prefix, topic, version)
+}
+
+func id(topic string, version int) string {
+	return fmt.Sprintf("%s@%d", topic, version)
+}
@@ -0,0 +1,331 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/codec"
+	merr "github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const (
+	orderV1 = `{"type":"object","properties":{"id":{"type":"string"},"total":{"type":"number"}},"required":["id"]}`
+	// adds an optional field
+	orderV2 = `{"type":"object","properties":{"id":{"type":"string"},"total":{"type":"number"},"note":{"type":"string"}},"required":["id"]}`
+	// adds a required field
+	orderV3 = `{"type":"object","properties":{"id":{"type":"string"},"total":{"type":"number"},"user":{"type":"string"}},"required":["id","user"]}`
+	// changes the type of a field
+	orderV4 = `{"type":"object","properties":{"id":{"type":"integer"},"total":{"type":"number"}},"required":["id"]}`
+)
+
+func newRegistry(opts ...Option) *Registry {
+	return NewRegistry(append([]Option{Store(store.NewMemoryStore())}, opts...)...)
+}
+
+func TestRegister(t *testing.T) {
+	r := newRegistry()
+
+	v1, err := r.Register("orders", JSONSchema, []byte(orderV1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1.Id != "orders@1" || v1.Version != 1 {
+		t.Fatalf("unexpected schema %s version %d", v1.Id, v1.Version)
+	}
+
+	// registering the same definition returns the existing version
+	again, err := r.Register("orders", JSONSchema, []byte(orderV1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Id != v1.Id {
+		t.Fatalf("expected %s got %s", v1.Id, again.Id)
+	}
+
+	v2, err := r.Register("orders", JSONSchema, []byte(orderV2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.Version != 2 {
+		t.Fatalf("expected version 2 got %d", v2.Version)
+	}
+
+	for _, def := range []string{orderV3, orderV4} {
+		_, err := r.Register("orders", JSONSchema, []byte(def))
+		if _, ok := err.(*IncompatibleError); !ok {
+			t.Fatalf("expected incompatible error got %v", err)
+		}
+	}
+
+	if _, err := r.Register("orders", JSONSchema, []byte(`{"type":`)); err == nil {
+		t.Fatal("expected invalid schema to be rejected")
+	}
+
+	// a second registry on the same store sees the versions
+	other := NewRegistry(Store(r.Options().Store))
+	latest, err := other.Latest("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Id != "orders@2" {
+		t.Fatalf("expected orders@2 got %s", latest.Id)
+	}
+	versions, err := other.Versions("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 1 {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+	if _, err := other.Get("orders@3"); err != ErrNotFound {
+		t.Fatalf("expected not found got %v", err)
+	}
+	if _, err := other.Latest("payments"); err != ErrNotFound {
+		t.Fatalf("expected not found got %v", err)
+	}
+}
+
+func TestCompatibility(t *testing.T) {
+	v1 := &Schema{Format: JSONSchema, Definition: []byte(orderV1)}
+	v3 := &Schema{Format: JSONSchema, Definition: []byte(orderV3)}
+	closed := &Schema{Format: JSONSchema, Definition: []byte(`{"type":"object","properties":{"id":{"type":"string"}},"required":["id"],"additionalProperties":false}`)}
+
+	testData := []struct {
+		old, new *Schema
+		c        Compatibility
+		ok       bool
+	}{
+		// the new version requires a field old data may not have
+		{v1, v3, Backward, false},
+		// old readers ignore the new field
+		{v1, v3, Forward, true},
+		{v1, v3, Full, false},
+		{v1, v3, None, true},
+		// old readers don't allow the total written by the new version
+		{closed, v1, Forward, false},
+		{closed, v1, Backward, true},
+	}
+
+	for i, d := range testData {
+		err := Compatible(d.old, d.new, d.c)
+		if d.ok && err != nil {
+			t.Fatalf("%d: expected %s compatible got %v", i, d.c, err)
+		}
+		if !d.ok && err == nil {
+			t.Fatalf("%d: expected %s incompatible", i, d.c)
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	s := &Schema{Format: JSONSchema, Definition: []byte(`{
+		"type":"object",
+		"properties":{
+			"id":{"type":"string"},
+			"qty":{"type":"integer"},
+			"state":{"enum":["new","paid"]},
+			"items":{"type":"array","items":{"type":"string"}}
+		},
+		"required":["id"]
+	}`)}
+
+	testData := []struct {
+		payload string
+		ok      bool
+	}{
+		{`{"id":"1","qty":2,"state":"paid","items":["a"]}`, true},
+		{`{"id":"1","extra":true}`, true},
+		{`{"qty":2}`, false},
+		{`{"id":1}`, false},
+		{`{"id":"1","qty":2.5}`, false},
+		{`{"id":"1","state":"lost"}`, false},
+		{`{"id":"1","items":[1]}`, false},
+		{`[]`, false},
+		{`{`, false},
+	}
+
+	for _, d := range testData {
+		err := s.Validate([]byte(d.payload))
+		if d.ok && err != nil {
+			t.Fatalf("expected %s to be valid got %v", d.payload, err)
+		}
+		if !d.ok && err == nil {
+			t.Fatalf("expected %s to be invalid", d.payload)
+		}
+	}
+}
+
+func newField(name string, number int32, label descriptor.FieldDescriptorProto_Label, typ descriptor.FieldDescriptorProto_Type) *descriptor.FieldDescriptorProto {
+	return &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  label.Enum(),
+		Type:   typ.Enum(),
+	}
+}
+
+func protoDefinition(t *testing.T, fields ...*descriptor.FieldDescriptorProto) []byte {
+	set := &descriptor.FileDescriptorSet{
+		File: []*descriptor.FileDescriptorProto{{
+			Name:    proto.String("order.proto"),
+			Package: proto.String("example"),
+			Syntax:  proto.String("proto2"),
+			MessageType: []*descriptor.DescriptorProto{{
+				Name:  proto.String("Order"),
+				Field: fields,
+			}},
+		}},
+	}
+	b, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestProtobuf(t *testing.T) {
+	optional := descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+	required := descriptor.FieldDescriptorProto_LABEL_REQUIRED
+
+	id := newField("id", 1, required, descriptor.FieldDescriptorProto_TYPE_STRING)
+	v1 := protoDefinition(t, id)
+	v2 := protoDefinition(t, id, newField("qty", 2, optional, descriptor.FieldDescriptorProto_TYPE_INT64))
+	v3 := protoDefinition(t, id, newField("qty", 2, optional, descriptor.FieldDescriptorProto_TYPE_STRING))
+	v4 := protoDefinition(t, id, newField("user", 3, required, descriptor.FieldDescriptorProto_TYPE_STRING))
+
+	r := newRegistry(WithCompatibility(Full))
+	if _, err := r.Register("orders", Protobuf, v1, "example.Order"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register("orders", Protobuf, v1, "example.Missing"); err == nil {
+		t.Fatal("expected unknown message to be rejected")
+	}
+	s, err := r.Register("orders", Protobuf, v2, "example.Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Register("orders", Protobuf, v3, "example.Order"); err == nil {
+		t.Fatal("expected type change to be rejected")
+	}
+	if _, err := r.Register("orders", Protobuf, v4, "example.Order"); err == nil {
+		t.Fatal("expected new required field to be rejected")
+	}
+
+	// id "1", qty 2
+	valid := []byte{0x0a, 0x01, '1', 0x10, 0x02}
+	if err := s.Validate(valid); err != nil {
+		t.Fatal(err)
+	}
+	// qty as a string
+	if err := s.Validate([]byte{0x0a, 0x01, '1', 0x12, 0x01, '2'}); err == nil {
+		t.Fatal("expected wrong wire type to be rejected")
+	}
+	// missing the required id
+	if err := s.Validate([]byte{0x10, 0x02}); err == nil {
+		t.Fatal("expected missing required field to be rejected")
+	}
+	// truncated
+	if err := s.Validate(valid[:2]); err == nil {
+		t.Fatal("expected truncated payload to be rejected")
+	}
+}
+
+type testMessage struct {
+	topic  string
+	header map[string]string
+	body   []byte
+}
+
+func (m *testMessage) Topic() string             { return m.topic }
+func (m *testMessage) Payload() interface{}      { return m.body }
+func (m *testMessage) ContentType() string       { return "application/json" }
+func (m *testMessage) Header() map[string]string { return m.header }
+func (m *testMessage) Body() []byte              { return m.body }
+func (m *testMessage) Codec() codec.Reader       { return nil }
+
+type testClient struct {
+	client.Client
+	md metadata.Metadata
+}
+
+func (c *testClient) Publish(ctx context.Context, p client.Message, opts ...client.PublishOption) error {
+	c.md, _ = metadata.FromContext(ctx)
+	return nil
+}
+
+func TestWrappers(t *testing.T) {
+	r := newRegistry()
+	if _, err := r.Register("orders", JSONSchema, []byte(orderV1)); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := new(testClient)
+	c := NewClientWrapper(r)(tc)
+	if err := c.Publish(context.TODO(), &testMessage{topic: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if v := tc.md[SchemaHeader]; v != "orders@1" {
+		t.Fatalf("expected schema id orders@1 got %q", v)
+	}
+	if err := c.Publish(context.TODO(), &testMessage{topic: "payments"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tc.md[SchemaHeader]; ok {
+		t.Fatal("unexpected schema id for unregistered topic")
+	}
+
+	for _, topic := range []string{"orders", "payments"} {
+		if _, err := r.Register(topic, JSONSchema, []byte(orderV2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testData := []struct {
+		opts   []WrapperOption
+		topic  string
+		header map[string]string
+		body   string
+		ok     bool
+	}{
+		// written with the previous version
+		{nil, "orders", map[string]string{SchemaHeader: "orders@1"}, `{"id":"1"}`, true},
+		{nil, "orders", map[string]string{SchemaHeader: "orders@2"}, `{"id":"1","note":"x"}`, true},
+		// payload doesn't match
+		{nil, "orders", map[string]string{SchemaHeader: "orders@2"}, `{"note":"x"}`, false},
+		// unknown schema
+		{nil, "orders", map[string]string{SchemaHeader: "orders@9"}, `{"id":"1"}`, false},
+		// schema of another topic
+		{nil, "payments", map[string]string{SchemaHeader: "orders@1"}, `{"id":"1"}`, false},
+		{nil, "orders", map[string]string{}, `{}`, true},
+		{[]WrapperOption{Require()}, "orders", map[string]string{}, `{"id":"1"}`, false},
+		// a subscriber pinned to the first version reading the second
+		{[]WrapperOption{Reader("orders", 1)}, "orders", map[string]string{SchemaHeader: "orders@2"}, `{"id":"1"}`, true},
+	}
+
+	for i, d := range testData {
+		var handled int
+		fn := NewSubscriberWrapper(r, d.opts...)(func(ctx context.Context, msg server.Message) error {
+			handled++
+			return nil
+		})
+		err := fn(context.TODO(), &testMessage{topic: d.topic, header: d.header, body: []byte(d.body)})
+		if d.ok {
+			if err != nil || handled != 1 {
+				t.Fatalf("%d: expected message to be handled got %v", i, err)
+			}
+			continue
+		}
+		if handled != 0 {
+			t.Fatalf("%d: expected message to be rejected", i)
+		}
+		if e := merr.Parse(err.Error()); e.Code != 400 {
+			t.Fatalf("%d: expected bad request got %v", i, err)
+		}
+	}
+}
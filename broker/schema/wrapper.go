@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/server"
+)
+
+var (
+	// SchemaHeader is the header holding the id of the schema of the payload
+	SchemaHeader = "Micro-Schema-Id"
+)
+
+type schemaClient struct {
+	client.Client
+	registry *Registry
+}
+
+func (s *schemaClient) Publish(ctx context.Context, p client.Message, opts ...client.PublishOption) error {
+	sc, err := s.registry.Latest(p.Topic())
+	if err == ErrNotFound {
+		return s.Client.Publish(ctx, p, opts...)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.schema", "schema lookup failed: %v", err)
+	}
+
+	// an id already set by the caller pins an older version
+	ctx = metadata.MergeContext(ctx, metadata.Metadata{SchemaHeader: sc.Id}, false)
+	return s.Client.Publish(ctx, p, opts...)
+}
+
+// NewClientWrapper returns a client wrapper which stamps the id of the latest
+// schema of the topic on published messages
+func NewClientWrapper(r *Registry) client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &schemaClient{c, r}
+	}
+}
+
+// NewSubscriberWrapper returns a subscriber wrapper which rejects messages
+// whose payload doesn't match the schema they were published with or whose
+// schema can't be read by the version the subscriber reads.
+func NewSubscriberWrapper(r *Registry, opts ...WrapperOption) server.SubscriberWrapper {
+	var options WrapperOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			if err := check(r, options, msg); err != nil {
+				return err
+			}
+			return fn(ctx, msg)
+		}
+	}
+}
+
+func check(r *Registry, opts WrapperOptions, msg server.Message) error {
+	topic := msg.Topic()
+
+	var reader *Schema
+	var err error
+	if v, ok := opts.Readers[topic]; ok {
+		reader, err = r.Get(id(topic, v))
+	} else {
+		reader, err = r.Latest(topic)
+	}
+	if err == ErrNotFound {
+		// nothing registered for the topic
+		return nil
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.schema", "schema lookup failed: %v", err)
+	}
+
+	sid := msg.Header()[SchemaHeader]
+	if len(sid) == 0 {
+		if opts.Require {
+			return errors.BadRequest("go.micro.schema", "message on %s has no schema id", topic)
+		}
+		return nil
+	}
+
+	writer, err := r.Get(sid)
+	if err == ErrNotFound {
+		return errors.BadRequest("go.micro.schema", "unknown schema %s", sid)
+	} else if err != nil {
+		return errors.InternalServerError("go.micro.schema", "schema lookup failed: %v", err)
+	}
+	if writer.Topic != topic {
+		return errors.BadRequest("go.micro.schema", "schema %s is not a schema of %s", sid, topic)
+	}
+
+	if writer.Id != reader.Id {
+		var err error
+		if writer.Version < reader.Version {
+			err = Compatible(writer, reader, Backward)
+		} else {
+			err = Compatible(reader, writer, Forward)
+		}
+		if err != nil {
+			return errors.BadRequest("go.micro.schema", "schema %s can't be read as %s: %v", sid, reader.Id, err)
+		}
+	}
+
+	if err := writer.Validate(msg.Body()); err != nil {
+		return errors.BadRequest("go.micro.schema", "payload doesn't match schema %s: %v", sid, err)
+	}
+
+	return nil
+}
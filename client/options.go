@@ -9,6 +9,7 @@ import (
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/selector"
 	"github.com/asim/go-micro/v3/transport"
+	"github.com/asim/go-micro/v3/util/cloudevents"
 )
 
 type Options struct {
@@ -32,6 +33,11 @@ type Options struct {
 	// Response cache
 	Cache *Cache
 
+	// CloudEvents is how published messages are encoded as cloud events
+	CloudEvents cloudevents.Mode
+	// EventSource is the source of the cloud events, usually the service name
+	EventSource string
+
 	// Middleware for client
 	Wrappers []Wrapper
 
@@ -76,6 +82,12 @@ type CallOptions struct {
 type PublishOptions struct {
 	// Exchange is the routing exchange for the message
 	Exchange string
+	// OrderingKey keeps messages with the same key in order
+	OrderingKey string
+	// CloudEvents overrides the cloud events mode of the client
+	CloudEvents *cloudevents.Mode
+	// DeliverAt delays delivery of the message until the time
+	DeliverAt time.Time
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -180,6 +192,14 @@ func Selector(s selector.Selector) Option {
 	}
 }
 
+// CloudEvents encodes published messages as cloud events from the source
+func CloudEvents(mode cloudevents.Mode, source string) Option {
+	return func(o *Options) {
+		o.CloudEvents = mode
+		o.EventSource = source
+	}
+}
+
 // Adds a Wrapper to a list of options passed into the client
 func Wrap(w Wrapper) Option {
 	return func(o *Options) {
@@ -248,6 +268,35 @@ func WithExchange(e string) PublishOption {
 	}
 }
 
+// WithOrderingKey sets the key messages are kept in order by. Subscribers
+// of a queue process the messages of a key one at a time in publish order.
+func WithOrderingKey(k string) PublishOption {
+	return func(o *PublishOptions) {
+		o.OrderingKey = k
+	}
+}
+
+// WithDeliverAt delays delivery of the message until the time
+func WithDeliverAt(t time.Time) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = t
+	}
+}
+
+// WithDelay delays delivery of the message for the duration
+func WithDelay(d time.Duration) PublishOption {
+	return func(o *PublishOptions) {
+		o.DeliverAt = time.Now().Add(d)
+	}
+}
+
+// WithCloudEvents sets how the message is encoded as a cloud event
+func WithCloudEvents(mode cloudevents.Mode) PublishOption {
+	return func(o *PublishOptions) {
+		o.CloudEvents = &mode
+	}
+}
+
 // PublishContext sets the context in publish options
 func PublishContext(ctx context.Context) PublishOption {
 	return func(o *PublishOptions) {
@@ -0,0 +1,28 @@
+package routing
+
+import (
+	"github.com/asim/go-micro/v3/config"
+)
+
+type Options struct {
+	// Config the rules are read from
+	Config config.Config
+	// Path of the rules in the config
+	Path []string
+}
+
+type Option func(o *Options)
+
+// Config sets the config the rules are read from
+func Config(c config.Config) Option {
+	return func(o *Options) {
+		o.Config = c
+	}
+}
+
+// Path sets the path of the rules in the config
+func Path(p ...string) Option {
+	return func(o *Options) {
+		o.Path = p
+	}
+}
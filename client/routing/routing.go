@@ -0,0 +1,149 @@
+// Package routing provides declarative client routing rules read from config e.g
+//
+//	{
+//		"routing": [
+//			{
+//				"service": "go.micro.srv.greeter",
+//				"headers": {"X-Beta": "true"},
+//				"split": [{"version": "v2", "weight": 100}]
+//			},
+//			{
+//				"service": "go.micro.srv.greeter",
+//				"endpoint": "Say.*",
+//				"split": [{"version": "v1", "weight": 90}, {"version": "v2", "weight": 10}],
+//				"mirror": {"version": "v3", "percent": 5},
+//				"fault": {"delay": "100ms", "percent": 1}
+//			}
+//		]
+//	}
+//
+// The first matching rule applies. The rules are reloaded as the config changes.
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+var (
+	// DefaultPath is the config path the rules are read from
+	DefaultPath = []string{"routing"}
+)
+
+// Rules routes requests using the rules read from config
+type Rules struct {
+	opts Options
+
+	sync.RWMutex
+	rules []*Rule
+
+	exit chan bool
+}
+
+// NewRules returns rules read from the config which are updated as it changes
+func NewRules(opts ...Option) (*Rules, error) {
+	options := Options{
+		Config: config.DefaultConfig,
+		Path:   DefaultPath,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	r := &Rules{
+		opts: options,
+		exit: make(chan bool),
+	}
+
+	if err := r.load(options.Config.Get(options.Path...)); err != nil {
+		return nil, err
+	}
+
+	w, err := options.Config.Watch(options.Path...)
+	if err != nil {
+		return nil, err
+	}
+
+	go r.watch(w)
+
+	return r, nil
+}
+
+func (r *Rules) load(v interface{ Scan(interface{}) error }) error {
+	var rules []*Rule
+	if err := v.Scan(&rules); err != nil {
+		return err
+	}
+
+	// drop the empty entries and check the rest
+	valid := rules[:0]
+	for i, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+		valid = append(valid, rule)
+	}
+
+	r.Lock()
+	r.rules = valid
+	r.Unlock()
+
+	return nil
+}
+
+func (r *Rules) watch(w config.Watcher) {
+	go func() {
+		<-r.exit
+		w.Stop()
+	}()
+
+	for {
+		v, err := w.Next()
+		if err != nil {
+			return
+		}
+		if err := r.load(v); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("routing: invalid rules: %v", err)
+			}
+		}
+	}
+}
+
+// List returns the current rules
+func (r *Rules) List() []*Rule {
+	r.RLock()
+	defer r.RUnlock()
+	rules := make([]*Rule, len(r.rules))
+	copy(rules, r.rules)
+	return rules
+}
+
+// Match returns the first rule which matches the request
+func (r *Rules) Match(ctx context.Context, service, endpoint string) (*Rule, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, rule := range r.rules {
+		if rule.Match(ctx, service, endpoint) {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// Stop stops watching the config
+func (r *Rules) Stop() {
+	select {
+	case <-r.exit:
+	default:
+		close(r.exit)
+	}
+}
@@ -0,0 +1,190 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/memory"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+const testRules = `{
+	"routing": [
+		{"service": "greeter", "headers": {"X-Beta": "true"}, "split": [{"version": "v2", "weight": 1}]},
+		{"service": "greeter", "endpoint": "Greeter.Abort", "fault": {"abort": 503}},
+		{"service": "greeter", "endpoint": "Greeter.Delay", "fault": {"delay": "50ms"}},
+		{"service": "greeter", "split": [{"version": "v1", "weight": 1}], "mirror": {"version": "v3"}}
+	]
+}`
+
+type call struct {
+	version string
+	mirror  bool
+}
+
+// testClient records the version each request would be sent to
+type testClient struct {
+	client.Client
+	calls chan call
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	var options client.CallOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	var so selector.SelectOptions
+	for _, o := range options.SelectOptions {
+		o(&so)
+	}
+
+	services := []*registry.Service{
+		{Name: "greeter", Version: "v1"},
+		{Name: "greeter", Version: "v2"},
+		{Name: "greeter", Version: "v3"},
+	}
+	for _, f := range so.Filters {
+		services = f(services)
+	}
+
+	var version string
+	if len(services) == 1 {
+		version = services[0].Version
+	}
+	_, mirror := metadata.Get(ctx, MirrorHeader)
+	c.calls <- call{version, mirror}
+
+	return nil
+}
+
+type value string
+
+func (v value) Scan(i interface{}) error {
+	return json.Unmarshal([]byte(v), i)
+}
+
+type updater interface {
+	Update(*source.ChangeSet)
+}
+
+func TestRules(t *testing.T) {
+	src := memory.NewSource(memory.WithJSON([]byte(testRules)))
+	conf, err := config.NewConfig(config.WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRules(Config(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	tc := &testClient{calls: make(chan call, 10)}
+	c := r.NewClientWrapper()(tc)
+
+	next := func() call {
+		select {
+		case c := <-tc.calls:
+			return c
+		case <-time.After(time.Second):
+			t.Fatal("expected a call")
+		}
+		return call{}
+	}
+
+	ctx := context.Background()
+	beta := metadata.NewContext(ctx, metadata.Metadata{"X-Beta": "true"})
+
+	// header based routing
+	if err := c.Call(beta, client.NewRequest("greeter", "Greeter.Hello", nil), new(string)); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); got.version != "v2" || got.mirror {
+		t.Fatalf("expected the beta request on v2, got %+v", got)
+	}
+
+	// abort fault
+	err = c.Call(ctx, client.NewRequest("greeter", "Greeter.Abort", nil), new(string))
+	if e := errors.FromError(err); e.Code != 503 {
+		t.Fatalf("expected an injected 503, got %v", err)
+	}
+
+	// delay fault
+	start := time.Now()
+	if err := c.Call(ctx, client.NewRequest("greeter", "Greeter.Delay", nil), new(string)); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("expected the request to be delayed, took %v", d)
+	}
+	next()
+
+	// split and mirror
+	if err := c.Call(ctx, client.NewRequest("greeter", "Greeter.Hello", nil), new(string)); err != nil {
+		t.Fatal(err)
+	}
+	calls := map[call]bool{next(): true, next(): true}
+	if !calls[call{"v1", false}] || !calls[call{"v3", true}] {
+		t.Fatalf("expected a call to v1 mirrored to v3, got %+v", calls)
+	}
+
+	// other services aren't routed
+	if err := c.Call(ctx, client.NewRequest("other", "Other.Hello", nil), new(string)); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); got.version != "" {
+		t.Fatalf("expected no routing, got %+v", got)
+	}
+
+	// the rules are reloaded
+	src.(updater).Update(&source.ChangeSet{
+		Data:   []byte(`{"routing": [{"service": "greeter", "split": [{"version": "v3", "weight": 1}]}]}`),
+		Format: "json",
+	})
+	for i := 0; ; i++ {
+		if rules := r.List(); len(rules) == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("expected the rules to be reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Call(beta, client.NewRequest("greeter", "Greeter.Hello", nil), new(string)); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); got.version != "v3" {
+		t.Fatalf("expected the reloaded rule, got %+v", got)
+	}
+
+	// invalid rules are rejected and the current rules kept
+	if err := r.load(value(`[{"service": "greeter", "fault": {"delay": "soon"}}]`)); err == nil {
+		t.Fatal("expected an invalid delay to be rejected")
+	}
+	if rules := r.List(); len(rules) != 1 || rules[0].Split[0].Version != "v3" {
+		t.Fatal("expected the rules to be kept")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	rule := &Rule{Split: []*Destination{{"v1", 3}, {"v2", 1}, {"v3", 0}}}
+
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		counts[rule.version()]++
+	}
+	if counts["v3"] != 0 {
+		t.Fatalf("expected no requests to v3, got %d", counts["v3"])
+	}
+	if counts["v1"] < 2700 || counts["v1"] > 3300 {
+		t.Fatalf("expected about 3000 requests to v1, got %d", counts["v1"])
+	}
+}
@@ -0,0 +1,134 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"time"
+
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+// Rule routes the requests it matches. Service and Endpoint are
+// path.Match patterns and every header must equal the request metadata.
+// Percent limits the rule to a share of the matching requests; zero
+// applies it to all of them as it does for the mirror and fault.
+type Rule struct {
+	Service  string            `json:"service"`
+	Endpoint string            `json:"endpoint,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Percent  int               `json:"percent,omitempty"`
+	// Split the requests between versions by weight
+	Split []*Destination `json:"split,omitempty"`
+	// Mirror a copy of the requests to a shadow version
+	Mirror *Mirror `json:"mirror,omitempty"`
+	// Fault injects delays and errors
+	Fault *Fault `json:"fault,omitempty"`
+}
+
+// Destination is a version requests are sent to
+type Destination struct {
+	Version string `json:"version"`
+	Weight  int    `json:"weight"`
+}
+
+// Mirror sends a copy of the requests to a version. The response is
+// discarded so the mirror never affects the caller.
+type Mirror struct {
+	Version string `json:"version"`
+	Percent int    `json:"percent,omitempty"`
+}
+
+// Fault delays the requests and or aborts them with the error code
+type Fault struct {
+	Delay   string `json:"delay,omitempty"`
+	Abort   int32  `json:"abort,omitempty"`
+	Percent int    `json:"percent,omitempty"`
+}
+
+// Match returns true if the request matches the rule
+func (r *Rule) Match(ctx context.Context, service, endpoint string) bool {
+	if ok, _ := path.Match(r.Service, service); !ok {
+		return false
+	}
+	if len(r.Endpoint) > 0 {
+		if ok, _ := path.Match(r.Endpoint, endpoint); !ok {
+			return false
+		}
+	}
+	for k, v := range r.Headers {
+		if val, ok := metadata.Get(ctx, k); !ok || val != v {
+			return false
+		}
+	}
+	return sample(r.Percent)
+}
+
+// version returns the version to send the request to or blank for any
+func (r *Rule) version() string {
+	var total int
+	for _, d := range r.Split {
+		total += d.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	n := rand.Intn(total)
+	for _, d := range r.Split {
+		if n < d.Weight {
+			return d.Version
+		}
+		n -= d.Weight
+	}
+	return ""
+}
+
+// mirror returns the version to mirror the request to
+func (r *Rule) mirror() (string, bool) {
+	if r.Mirror == nil || len(r.Mirror.Version) == 0 {
+		return "", false
+	}
+	return r.Mirror.Version, sample(r.Mirror.Percent)
+}
+
+// fault returns the delay and error to inject
+func (r *Rule) fault() (time.Duration, int32) {
+	if r.Fault == nil || !sample(r.Fault.Percent) {
+		return 0, 0
+	}
+	d, _ := time.ParseDuration(r.Fault.Delay)
+	return d, r.Fault.Abort
+}
+
+func (r *Rule) validate() error {
+	if len(r.Service) == 0 {
+		return errors.New("service is blank")
+	}
+	for _, p := range []string{r.Service, r.Endpoint} {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+	}
+	for _, d := range r.Split {
+		if d == nil || d.Weight < 0 {
+			return errors.New("invalid split")
+		}
+	}
+	if r.Fault != nil && len(r.Fault.Delay) > 0 {
+		if _, err := time.ParseDuration(r.Fault.Delay); err != nil {
+			return fmt.Errorf("invalid delay: %v", err)
+		}
+	}
+	return nil
+}
+
+// sample returns true for percent of calls. Zero is treated as all.
+func sample(percent int) bool {
+	if percent <= 0 || percent >= 100 {
+		return true
+	}
+	return rand.Intn(100) < percent
+}
@@ -0,0 +1,118 @@
+package routing
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+var (
+	// MirrorHeader is set on mirrored requests so the shadow version can tell them apart
+	MirrorHeader = "Micro-Mirror"
+	// MirrorTimeout is the timeout of mirrored requests
+	MirrorTimeout = 5 * time.Second
+)
+
+type routingWrapper struct {
+	client.Client
+	r *Rules
+}
+
+func (w *routingWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	rule, ok := w.r.Match(ctx, req.Service(), req.Endpoint())
+	if !ok {
+		return w.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	if err := inject(ctx, rule, req); err != nil {
+		return err
+	}
+
+	if version, ok := rule.mirror(); ok {
+		go w.mirror(ctx, req, rsp, version, opts)
+	}
+
+	if version := rule.version(); len(version) > 0 {
+		opts = append(opts, client.WithSelectOption(selector.WithFilter(selector.FilterVersion(version))))
+	}
+
+	return w.Client.Call(ctx, req, rsp, opts...)
+}
+
+func (w *routingWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	rule, ok := w.r.Match(ctx, req.Service(), req.Endpoint())
+	if !ok {
+		return w.Client.Stream(ctx, req, opts...)
+	}
+
+	if err := inject(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if version := rule.version(); len(version) > 0 {
+		opts = append(opts, client.WithSelectOption(selector.WithFilter(selector.FilterVersion(version))))
+	}
+
+	return w.Client.Stream(ctx, req, opts...)
+}
+
+// mirror sends a copy of the request to the version and discards the response
+func (w *routingWrapper) mirror(ctx context.Context, req client.Request, rsp interface{}, version string, opts []client.CallOption) {
+	// the mirror must outlive the request so only the metadata is kept
+	md, _ := metadata.FromContext(ctx)
+	md = metadata.Copy(md)
+	md.Set(MirrorHeader, "true")
+
+	mctx, cancel := context.WithTimeout(metadata.NewContext(context.Background(), md), MirrorTimeout)
+	defer cancel()
+
+	// decode into a new value of the same type
+	var mrsp interface{} = rsp
+	if t := reflect.TypeOf(rsp); t != nil && t.Kind() == reflect.Ptr {
+		mrsp = reflect.New(t.Elem()).Interface()
+	}
+
+	opts = append(opts[:len(opts):len(opts)],
+		client.WithSelectOption(selector.WithFilter(selector.FilterVersion(version))),
+	)
+
+	if err := w.Client.Call(mctx, req, mrsp, opts...); err != nil {
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("routing: mirror of %s %s to %s failed: %v", req.Service(), req.Endpoint(), version, err)
+		}
+	}
+}
+
+// inject applies the fault of the rule
+func inject(ctx context.Context, rule *Rule, req client.Request) error {
+	delay, code := rule.fault()
+
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Timeout("go.micro.client", "%v", ctx.Err())
+		case <-t.C:
+		}
+	}
+
+	if code > 0 {
+		return errors.New(req.Service(), "fault injected", code)
+	}
+
+	return nil
+}
+
+// NewClientWrapper returns a client wrapper which routes requests using the rules
+func (r *Rules) NewClientWrapper() client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &routingWrapper{c, r}
+	}
+}
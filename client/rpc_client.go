@@ -15,6 +15,7 @@ import (
 	"github.com/asim/go-micro/v3/selector"
 	"github.com/asim/go-micro/v3/transport"
 	"github.com/asim/go-micro/v3/util/buf"
+	"github.com/asim/go-micro/v3/util/cloudevents"
 	"github.com/asim/go-micro/v3/util/net"
 	"github.com/asim/go-micro/v3/util/pool"
 	"github.com/google/uuid"
@@ -623,10 +624,39 @@ func (r *rpcClient) Publish(ctx context.Context, msg Message, opts ...PublishOpt
 		r.once.Store(true)
 	}
 
+	// encode as a cloud event
+	mode := r.opts.CloudEvents
+	if options.CloudEvents != nil {
+		mode = *options.CloudEvents
+	}
+	if mode != cloudevents.None {
+		ev := &cloudevents.Event{
+			ID:     id,
+			Source: r.opts.EventSource,
+			Type:   msg.Topic(),
+			Time:   time.Now(),
+		}
+		if len(ev.Source) == 0 {
+			ev.Source = "go.micro.client"
+		}
+		md, body, err = ev.Marshal(mode, md, body)
+		if err != nil {
+			return errors.InternalServerError("go.micro.client", err.Error())
+		}
+	}
+
+	pubOpts := []broker.PublishOption{broker.PublishContext(options.Context)}
+	if len(options.OrderingKey) > 0 {
+		pubOpts = append(pubOpts, broker.OrderingKey(options.OrderingKey))
+	}
+	if !options.DeliverAt.IsZero() {
+		pubOpts = append(pubOpts, broker.DeliverAt(options.DeliverAt))
+	}
+
 	return r.opts.Broker.Publish(topic, &broker.Message{
 		Header: md,
 		Body:   body,
-	}, broker.PublishContext(options.Context))
+	}, pubOpts...)
 }
 
 func (r *rpcClient) NewMessage(topic string, message interface{}, opts ...MessageOption) Message {
@@ -0,0 +1,116 @@
+// micro-bridge forwards topics from the broker of the service to another
+// broker. Build it with the imports of the broker plugins it should bridge.
+//
+// Usage:
+//
+//	micro-bridge --broker=http \
+//		--bridge_target=nats --bridge_target_address=127.0.0.1:4222 \
+//		--bridge_route=orders --bridge_route=payments:legacy.payments
+package main
+
+import (
+	"strings"
+
+	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/broker/bridge"
+	"github.com/asim/go-micro/v3/cmd"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/micro/cli/v2"
+)
+
+func main() {
+	var opts []bridge.Option
+	var target broker.Broker
+
+	service := micro.NewService(
+		micro.Name("go.micro.bridge"),
+		micro.Flags(
+			&cli.StringFlag{
+				Name:    "bridge_name",
+				EnvVars: []string{"MICRO_BRIDGE_NAME"},
+				Usage:   "Name of the bridge, bridges sharing a name don't forward each other's messages",
+				Value:   bridge.DefaultName,
+			},
+			&cli.StringFlag{
+				Name:    "bridge_target",
+				EnvVars: []string{"MICRO_BRIDGE_TARGET"},
+				Usage:   "Broker the topics are forwarded to",
+				Value:   "http",
+			},
+			&cli.StringFlag{
+				Name:    "bridge_target_address",
+				EnvVars: []string{"MICRO_BRIDGE_TARGET_ADDRESS"},
+				Usage:   "Comma-separated list of target broker addresses",
+			},
+			&cli.StringSliceFlag{
+				Name:    "bridge_route",
+				EnvVars: []string{"MICRO_BRIDGE_ROUTE"},
+				Usage:   "Topic to forward as source[:target]",
+			},
+			&cli.StringSliceFlag{
+				Name:    "bridge_header",
+				EnvVars: []string{"MICRO_BRIDGE_HEADER"},
+				Usage:   "Header to set as key=value, an empty value removes it",
+			},
+			&cli.IntFlag{
+				Name:    "bridge_retries",
+				EnvVars: []string{"MICRO_BRIDGE_RETRIES"},
+				Usage:   "Retries of a failed publish before the message is redelivered",
+				Value:   bridge.DefaultRetries,
+			},
+		),
+		micro.Action(func(c *cli.Context) error {
+			var bopts []broker.Option
+			if addr := c.String("bridge_target_address"); len(addr) > 0 {
+				bopts = append(bopts, broker.Addrs(strings.Split(addr, ",")...))
+			}
+
+			if fn, ok := cmd.DefaultBrokers[c.String("bridge_target")]; ok {
+				target = fn(bopts...)
+			} else if c.String("bridge_target") == "http" {
+				target = broker.NewBroker(bopts...)
+			} else {
+				logger.Fatalf("Broker %s not found", c.String("bridge_target"))
+			}
+
+			opts = append(opts,
+				bridge.Name(c.String("bridge_name")),
+				bridge.Retries(c.Int("bridge_retries")),
+			)
+			for _, r := range c.StringSlice("bridge_route") {
+				parts := strings.SplitN(r, ":", 2)
+				if len(parts) == 1 {
+					parts = append(parts, parts[0])
+				}
+				opts = append(opts, bridge.Forward(parts[0], parts[1]))
+			}
+			for _, h := range c.StringSlice("bridge_header") {
+				parts := strings.SplitN(h, "=", 2)
+				if len(parts) == 1 {
+					parts = append(parts, "")
+				}
+				opts = append(opts, bridge.Header(parts[0], parts[1]))
+			}
+			return nil
+		}),
+	)
+
+	service.Init()
+
+	b := bridge.NewBridge(service.Options().Broker, target, opts...)
+	if len(b.Options().Routes) == 0 {
+		logger.Fatal("No routes to bridge")
+	}
+
+	service.Init(
+		// the server connects the source broker when it starts
+		micro.AfterStart(b.Start),
+		micro.BeforeStop(b.Stop),
+		micro.AfterStop(target.Disconnect),
+	)
+
+	if err := service.Run(); err != nil {
+		logger.Fatal(err)
+	}
+}
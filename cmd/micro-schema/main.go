@@ -0,0 +1,196 @@
+// micro-schema dumps and diffs the endpoint schemas of services
+// in the registry, failing when a new version breaks the old one.
+//
+// Usage:
+//
+//	micro-schema dump go.micro.srv.greeter > greeter.json
+//	micro-schema diff old.json new.json
+//	micro-schema check go.micro.srv.greeter 1.0.0 1.1.0
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/registry/schema"
+)
+
+var (
+	address  = flag.String("registry_address", "", "Comma-separated list of registry addresses")
+	all      = flag.Bool("all", false, "Print non breaking changes too")
+	jsonFlag = flag.Bool("json", false, "Print changes as json")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: micro-schema [flags] <command>
+
+Commands:
+  dump <service> [version]        print the service from the registry as json,
+                                  the version is required if there's more than one
+  diff <old.json> <new.json>      diff two dumped services
+  check <service> <old> <new>     diff two versions of a service in the registry
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func newRegistry() registry.Registry {
+	var opts []registry.Option
+	if len(*address) > 0 {
+		opts = append(opts, registry.Addrs(strings.Split(*address, ",")...))
+	}
+	return registry.NewRegistry(opts...)
+}
+
+func dump(r registry.Registry, w io.Writer, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("dump requires a service")
+	}
+
+	services, err := r.GetService(args[0])
+	if err != nil {
+		return err
+	}
+
+	var out *registry.Service
+	switch {
+	case len(args) > 1:
+		for _, s := range services {
+			if s.Version == args[1] {
+				out = s
+			}
+		}
+		if out == nil {
+			return fmt.Errorf("service %s version %s not found", args[0], args[1])
+		}
+	case len(services) == 1:
+		out = services[0]
+	default:
+		// a dump is a single service so diff can load it
+		var versions []string
+		for _, s := range services {
+			versions = append(versions, s.Version)
+		}
+		return fmt.Errorf("service %s has versions %s, dump requires one of them", args[0], strings.Join(versions, ", "))
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(b))
+	return nil
+}
+
+func load(file string) (*registry.Service, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return s, nil
+}
+
+// decode reads a dumped service, a list of a single service is accepted too
+func decode(b []byte) (*registry.Service, error) {
+	var services []*registry.Service
+	if err := json.Unmarshal(b, &services); err == nil {
+		if len(services) != 1 {
+			return nil, fmt.Errorf("expected a single service, got %d", len(services))
+		}
+		return services[0], nil
+	}
+
+	var s *registry.Service
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("no service")
+	}
+	return s, nil
+}
+
+// report prints the changes and returns an error if any are breaking
+func report(changes []*schema.Change) error {
+	breaking := schema.Breaking(changes)
+	if !*all {
+		changes = breaking
+	}
+
+	if *jsonFlag {
+		b, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, c := range changes {
+			fmt.Println(c.String())
+		}
+	}
+
+	if len(breaking) > 0 {
+		return fmt.Errorf("%d breaking changes", len(breaking))
+	}
+	return nil
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "dump":
+		return dump(newRegistry(), os.Stdout, args[1:])
+	case "diff":
+		if len(args) != 3 {
+			return fmt.Errorf("diff requires two files")
+		}
+		old, err := load(args[1])
+		if err != nil {
+			return err
+		}
+		new, err := load(args[2])
+		if err != nil {
+			return err
+		}
+		return report(schema.Diff(old, new))
+	case "check":
+		if len(args) != 4 {
+			return fmt.Errorf("check requires a service and two versions")
+		}
+		changes, err := schema.Compare(newRegistry(), args[1], args[2], args[3])
+		if err != nil {
+			return err
+		}
+		return report(changes)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/registry/schema"
+)
+
+func TestDumpLoad(t *testing.T) {
+	r := registry.NewMemoryRegistry()
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		err := r.Register(&registry.Service{
+			Name:    "greeter",
+			Version: v,
+			Endpoints: []*registry.Endpoint{{
+				Name:    "Say.Hello",
+				Request: &registry.Value{Name: "Request", Type: "Request"},
+			}},
+			Nodes: []*registry.Node{{Id: "greeter-" + v, Address: "localhost:9000"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// there's more than one version so one is required
+	if err := dump(r, ioutil.Discard, []string{"greeter"}); err == nil {
+		t.Fatal("expected dump without a version to fail")
+	}
+
+	dir, err := ioutil.TempDir("", "micro-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var files []string
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		var buf bytes.Buffer
+		if err := dump(r, &buf, []string{"greeter", v}); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, v+".json")
+		if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	old, err := load(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := load(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Version != "1.0.0" || new.Version != "1.1.0" || len(new.Endpoints) != 1 {
+		t.Fatalf("unexpected services %+v %+v", old, new)
+	}
+	if changes := schema.Breaking(schema.Diff(old, new)); len(changes) != 0 {
+		t.Fatalf("expected no breaking changes, got %v", changes)
+	}
+
+	// a list of a single service as written by older dumps
+	s, err := decode([]byte(`[{"name": "greeter", "version": "1.0.0"}]`))
+	if err != nil || s.Version != "1.0.0" {
+		t.Fatalf("expected service from list, got %v %v", s, err)
+	}
+	if _, err := decode([]byte(`[{"name": "greeter"}, {"name": "greeter"}]`)); err == nil {
+		t.Fatal("expected a list of services to fail")
+	}
+}
@@ -0,0 +1,528 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/reader"
+)
+
+// Bind tags are read from the `config` struct tag, e.g.
+//
+//	type Server struct {
+//		Address string        `json:"address" config:"required"`
+//		Port    int           `json:"port" config:"default=8080,min=1,max=65535"`
+//		Mode    string        `json:"mode" config:"default=dev,enum=dev|staging|prod"`
+//		Timeout time.Duration `json:"timeout" config:"default=5s,min=1s"`
+//	}
+//
+// Keys are matched using the json tag name or the field name. The fields of
+// embedded structs without a json name are bound at the path of the struct
+// embedding them, as encoding/json does.
+const bindTag = "config"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// FieldError is a single problem found while binding config
+type FieldError struct {
+	// Path is the dotted config path e.g server.port
+	Path string
+	// Source is the name of the source which supplied the value
+	Source string
+	// Message describes the problem
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if len(e.Source) > 0 {
+		return fmt.Sprintf("%s: %s (source: %s)", e.Path, e.Message, e.Source)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// BindError is returned by Bind and holds all the problems found
+type BindError struct {
+	Errors []*FieldError
+}
+
+func (e *BindError) Error() string {
+	errs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err.Error())
+	}
+	return "config: " + strings.Join(errs, "; ")
+}
+
+// Bind scans the config at path into v applying defaults and validation
+func Bind(v interface{}, path ...string) error {
+	return BindConfig(DefaultConfig, v, path...)
+}
+
+// BindConfig scans the value of c at path into v. Defaults are applied to
+// missing fields and every field is validated against its `config` tag.
+// All the problems found are returned at once as a *BindError.
+func BindConfig(c Config, v interface{}, path ...string) error {
+	return bindValue(c.Get(path...), v, path, c.Get)
+}
+
+// BindValue scans a reader.Value into v applying defaults and validation
+func BindValue(val reader.Value, v interface{}) error {
+	return bindValue(val, v, nil, nil)
+}
+
+// bindValue binds val found at path into v. The get func is used to
+// look up the provenance of values when reporting errors.
+func bindValue(val reader.Value, v interface{}, path []string, get func(...string) reader.Value) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: bind requires a non nil pointer to a struct, got %T", v)
+	}
+
+	var data interface{}
+	if b := val.Bytes(); len(b) > 0 {
+		if err := json.Unmarshal(b, &data); err != nil {
+			return err
+		}
+	}
+
+	b := &binder{get: get}
+	b.bindStruct(rv.Elem(), data, path)
+
+	if len(b.errs) > 0 {
+		return &BindError{Errors: b.errs}
+	}
+	return nil
+}
+
+type binder struct {
+	get  func(...string) reader.Value
+	errs []*FieldError
+}
+
+func (b *binder) fail(path []string, found bool, format string, args ...interface{}) {
+	err := &FieldError{
+		Path:    strings.Join(path, "."),
+		Message: fmt.Sprintf(format, args...),
+	}
+	if found && b.get != nil {
+		if p := provenance(b.get(path...)); p != nil {
+			err.Source = p.String()
+		}
+	}
+	b.errs = append(b.errs, err)
+}
+
+type bindRules struct {
+	def      *string
+	required bool
+	min      *string
+	max      *string
+	enum     []string
+}
+
+func parseRules(tag string) bindRules {
+	var r bindRules
+	for _, part := range strings.Split(tag, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		var val string
+		if len(kv) == 2 {
+			val = kv[1]
+		}
+		switch kv[0] {
+		case "default":
+			r.def = &val
+		case "required":
+			r.required = true
+		case "min":
+			r.min = &val
+		case "max":
+			r.max = &val
+		case "enum":
+			r.enum = strings.Split(val, "|")
+		}
+	}
+	return r
+}
+
+func fieldKey(f reflect.StructField) (string, bool) {
+	name := f.Name
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		parts := strings.Split(tag, ",")
+		if parts[0] == "-" {
+			return "", false
+		}
+		if len(parts[0]) > 0 {
+			name = parts[0]
+		}
+	}
+	return name, true
+}
+
+// lookup finds key in m the same way encoding/json does, preferring an
+// exact match before falling back to a case insensitive one
+func lookup(m map[string]interface{}, key string) (string, interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return key, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+// boundField is a field of a struct and the key it's bound to
+type boundField struct {
+	field reflect.StructField
+	value reflect.Value
+	key   string
+}
+
+// fields returns the fields of the struct in rv. The fields of embedded
+// structs are flattened into it unless the struct already has their key.
+func fields(rv reflect.Value) []boundField {
+	var list []boundField
+	var embedded []reflect.Value
+	taken := make(map[string]bool)
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+
+		key, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		if ev, ok := embed(f, rv.Field(i)); ok {
+			embedded = append(embedded, ev)
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+
+		list = append(list, boundField{f, rv.Field(i), key})
+		taken[key] = true
+	}
+
+	for _, ev := range embedded {
+		for _, bf := range fields(ev) {
+			if taken[bf.key] {
+				continue
+			}
+			list = append(list, bf)
+			taken[bf.key] = true
+		}
+	}
+
+	return list
+}
+
+// embed returns the struct of an embedded field without a json name,
+// allocating it when it's a nil pointer
+func embed(f reflect.StructField, v reflect.Value) (reflect.Value, bool) {
+	if !f.Anonymous {
+		return v, false
+	}
+	if tag := f.Tag.Get("json"); len(strings.Split(tag, ",")[0]) > 0 {
+		return v, false
+	}
+
+	if f.Type.Kind() != reflect.Ptr {
+		return v, isStruct(f.Type)
+	}
+
+	// pointers to unexported structs can't be allocated
+	if len(f.PkgPath) > 0 || !isStruct(f.Type.Elem()) {
+		return v, false
+	}
+	if v.IsNil() {
+		v.Set(reflect.New(f.Type.Elem()))
+	}
+	return v.Elem(), true
+}
+
+func (b *binder) bindStruct(rv reflect.Value, data interface{}, path []string) {
+	m, ok := data.(map[string]interface{})
+	if !ok && data != nil {
+		b.fail(path, true, "expected an object, got %s", describe(data))
+		return
+	}
+
+	seen := make(map[string]bool)
+
+	for _, bf := range fields(rv) {
+		f, key := bf.field, bf.key
+
+		k, raw, found := lookup(m, key)
+		if found {
+			seen[k] = true
+			key = k
+		}
+
+		fpath := append(append([]string{}, path...), key)
+		rules := parseRules(f.Tag.Get(bindTag))
+		field := bf.value
+
+		if !found || raw == nil {
+			switch {
+			case rules.def != nil:
+				if err := setString(field, *rules.def); err != nil {
+					b.fail(fpath, false, "invalid default %q: %v", *rules.def, err)
+					continue
+				}
+			case rules.required:
+				b.fail(fpath, false, "required value is missing")
+				continue
+			case isStruct(f.Type):
+				// apply nested defaults and required checks
+				b.bindStruct(field, nil, fpath)
+				continue
+			default:
+				continue
+			}
+			b.validate(field, rules, fpath, false)
+			continue
+		}
+
+		if isStruct(f.Type) {
+			b.bindStruct(field, raw, fpath)
+			continue
+		}
+
+		if err := setValue(field, raw); err != nil {
+			b.fail(fpath, true, "%v", err)
+			continue
+		}
+		b.validate(field, rules, fpath, true)
+	}
+
+	for k := range m {
+		if !seen[k] {
+			b.fail(append(append([]string{}, path...), k), true, "unknown key")
+		}
+	}
+}
+
+func (b *binder) validate(field reflect.Value, r bindRules, path []string, found bool) {
+	if r.min != nil || r.max != nil {
+		n, err := measure(field)
+		if err != nil {
+			b.fail(path, found, "%v", err)
+			return
+		}
+		if r.min != nil {
+			min, err := parseBound(field, *r.min)
+			if err != nil {
+				b.fail(path, found, "invalid min %q: %v", *r.min, err)
+			} else if n < min {
+				b.fail(path, found, "%s is less than min %s", display(field), *r.min)
+			}
+		}
+		if r.max != nil {
+			max, err := parseBound(field, *r.max)
+			if err != nil {
+				b.fail(path, found, "invalid max %q: %v", *r.max, err)
+			} else if n > max {
+				b.fail(path, found, "%s is greater than max %s", display(field), *r.max)
+			}
+		}
+	}
+
+	if len(r.enum) > 0 {
+		val := display(field)
+		for _, e := range r.enum {
+			if e == val {
+				return
+			}
+		}
+		b.fail(path, found, "%s is not one of [%s]", val, strings.Join(r.enum, ", "))
+	}
+}
+
+// measure returns the value compared against min/max. Numbers are compared
+// by value, strings, slices and maps by their length.
+func measure(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), nil
+	}
+	return 0, fmt.Errorf("min/max not supported for %s", v.Type())
+}
+
+func parseBound(v reflect.Value, s string) (float64, error) {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func display(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func describe(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// setString sets a field from its string form, used for defaults
+func setString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			var parts []interface{}
+			for _, p := range strings.Split(s, "|") {
+				parts = append(parts, p)
+			}
+			return setValue(v, parts)
+		}
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		raw = s
+	}
+	return setValue(v, raw)
+}
+
+// setValue sets a field from a decoded json value
+func setValue(v reflect.Value, raw interface{}) error {
+	if v.Type() == durationType {
+		switch t := raw.(type) {
+		case string:
+			d, err := time.ParseDuration(t)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", t)
+			}
+			v.SetInt(int64(d))
+			return nil
+		case float64:
+			// numbers follow encoding/json and are nanoseconds
+			v.SetInt(int64(t))
+			return nil
+		}
+		return fmt.Errorf("expected a duration, got %s", describe(raw))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), raw)
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %s", describe(raw))
+		}
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		switch t := raw.(type) {
+		case bool:
+			v.SetBool(t)
+			return nil
+		case string:
+			bv, err := strconv.ParseBool(t)
+			if err != nil {
+				return fmt.Errorf("invalid bool %q", t)
+			}
+			v.SetBool(bv)
+			return nil
+		}
+		return fmt.Errorf("expected a bool, got %s", describe(raw))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		return setNumber(v, f)
+	}
+
+	// everything else is left to encoding/json
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v.Addr().Interface()); err != nil {
+		return fmt.Errorf("expected %s, got %s", v.Type(), describe(raw))
+	}
+	return nil
+}
+
+func toFloat(raw interface{}) (float64, error) {
+	switch t := raw.(type) {
+	case float64:
+		return t, nil
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", t)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("expected a number, got %s", describe(raw))
+}
+
+func setNumber(v reflect.Value, f float64) error {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if v.OverflowFloat(f) {
+			return fmt.Errorf("%v overflows %s", f, v.Type())
+		}
+		v.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f != float64(int64(f)) {
+			return fmt.Errorf("expected an integer, got %v", f)
+		}
+		if v.OverflowInt(int64(f)) {
+			return fmt.Errorf("%v overflows %s", f, v.Type())
+		}
+		v.SetInt(int64(f))
+	default:
+		if f < 0 || f != float64(uint64(f)) {
+			return fmt.Errorf("expected an unsigned integer, got %v", f)
+		}
+		if v.OverflowUint(uint64(f)) {
+			return fmt.Errorf("%v overflows %s", f, v.Type())
+		}
+		v.SetUint(uint64(f))
+	}
+	return nil
+}
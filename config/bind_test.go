@@ -0,0 +1,226 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/memory"
+)
+
+type bindServer struct {
+	Address string        `json:"address" config:"required"`
+	Port    int           `json:"port" config:"default=8080,min=1,max=65535"`
+	Mode    string        `json:"mode" config:"default=dev,enum=dev|staging|prod"`
+	Timeout time.Duration `json:"timeout" config:"default=5s,min=1s"`
+	Tags    []string      `json:"tags" config:"max=2"`
+}
+
+type bindConfig struct {
+	Server bindServer `json:"server"`
+	Debug  bool       `json:"debug"`
+}
+
+type updater interface {
+	Update(*source.ChangeSet)
+}
+
+func newBindConfig(t *testing.T, data string, opts ...Option) (Config, updater) {
+	src := memory.NewSource(memory.WithJSON([]byte(data)))
+	c, err := NewConfig(append(opts, WithSource(src))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, src.(updater)
+}
+
+func TestBindDefaults(t *testing.T) {
+	c, _ := newBindConfig(t, `{"server": {"address": "localhost", "timeout": "10s"}}`)
+
+	var conf bindConfig
+	if err := BindConfig(c, &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Server.Address != "localhost" {
+		t.Fatalf("expected localhost, got %s", conf.Server.Address)
+	}
+	if conf.Server.Port != 8080 {
+		t.Fatalf("expected default port 8080, got %d", conf.Server.Port)
+	}
+	if conf.Server.Mode != "dev" {
+		t.Fatalf("expected default mode dev, got %s", conf.Server.Mode)
+	}
+	if conf.Server.Timeout != 10*time.Second {
+		t.Fatalf("expected timeout 10s, got %v", conf.Server.Timeout)
+	}
+
+	// bind a sub path
+	var srv bindServer
+	if err := BindConfig(c, &srv, "server"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Address != "localhost" {
+		t.Fatalf("expected localhost, got %s", srv.Address)
+	}
+}
+
+type bindBase struct {
+	Name    string `json:"name" config:"required"`
+	Version string `json:"version" config:"default=latest"`
+}
+
+// exported so the embedded pointer can be allocated
+type BindLimits struct {
+	Retries int `json:"retries" config:"default=3,max=5"`
+}
+
+type bindService struct {
+	bindBase
+	*BindLimits
+	// takes precedence over the embedded field
+	Version string `json:"version" config:"default=v1"`
+	// named so it's not flattened
+	Server bindServer `json:"server"`
+}
+
+func TestBindEmbedded(t *testing.T) {
+	c, _ := newBindConfig(t, `{"name": "greeter", "server": {"address": "localhost"}}`)
+
+	var conf bindService
+	if err := BindConfig(c, &conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Name != "greeter" {
+		t.Fatalf("expected name greeter, got %q", conf.Name)
+	}
+	if conf.Version != "v1" || len(conf.bindBase.Version) > 0 {
+		t.Fatalf("expected the outer version to be set, got %q and %q", conf.Version, conf.bindBase.Version)
+	}
+	if conf.BindLimits == nil || conf.Retries != 3 {
+		t.Fatalf("expected the default retries, got %+v", conf.BindLimits)
+	}
+
+	c, _ = newBindConfig(t, `{"retries": 10, "bindBase": {"name": "greeter"}}`)
+
+	err := BindConfig(c, &bindService{})
+	berr, ok := err.(*BindError)
+	if !ok {
+		t.Fatalf("expected *BindError, got %v", err)
+	}
+
+	expected := map[string]string{
+		"name":           "required",
+		"retries":        "greater than max",
+		"bindBase":       "unknown key",
+		"server.address": "required",
+	}
+
+	if len(berr.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(berr.Errors), berr)
+	}
+	for _, fe := range berr.Errors {
+		msg, ok := expected[fe.Path]
+		if !ok {
+			t.Fatalf("unexpected error %v", fe)
+		}
+		if !strings.Contains(fe.Message, msg) {
+			t.Fatalf("expected %s to contain %q, got %q", fe.Path, msg, fe.Message)
+		}
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	c, _ := newBindConfig(t, `{
+		"server": {"port": 0, "mode": "test", "timeout": "1ms", "tags": ["a", "b", "c"], "extra": true},
+		"debug": "yes"
+	}`)
+
+	var conf bindConfig
+	err := BindConfig(c, &conf)
+	berr, ok := err.(*BindError)
+	if !ok {
+		t.Fatalf("expected *BindError, got %v", err)
+	}
+
+	expected := map[string]string{
+		"server.address": "required",
+		"server.port":    "less than min",
+		"server.mode":    "not one of",
+		"server.timeout": "less than min",
+		"server.tags":    "greater than max",
+		"server.extra":   "unknown key",
+		"debug":          "invalid bool",
+	}
+
+	if len(berr.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(berr.Errors), berr)
+	}
+
+	for _, fe := range berr.Errors {
+		msg, ok := expected[fe.Path]
+		if !ok {
+			t.Fatalf("unexpected error %v", fe)
+		}
+		if !strings.Contains(fe.Message, msg) {
+			t.Fatalf("expected %s to contain %q, got %q", fe.Path, msg, fe.Message)
+		}
+		if fe.Path != "server.address" && fe.Source != "memory" {
+			t.Fatalf("expected source memory for %s, got %q", fe.Path, fe.Source)
+		}
+	}
+}
+
+func TestBindRejectsInvalidUpdate(t *testing.T) {
+	c, src := newBindConfig(t, `{"server": {"address": "localhost"}}`, WithSchema(&bindConfig{}))
+
+	w, err := c.Watch("server", "port")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// invalid update is not applied
+	time.Sleep(100 * time.Millisecond)
+	src.Update(&source.ChangeSet{Data: []byte(`{"server": {"address": "localhost", "port": 70000}}`)})
+	time.Sleep(100 * time.Millisecond)
+	if port := c.Get("server", "port").Int(0); port != 0 {
+		t.Fatalf("expected invalid update to be rejected, got port %d", port)
+	}
+
+	// valid update is applied
+	src.Update(&source.ChangeSet{Data: []byte(`{"server": {"address": "localhost", "port": 9090}}`)})
+
+	v, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port := v.Int(0); port != 9090 {
+		t.Fatalf("expected port 9090, got %d", port)
+	}
+
+	if err := c.Load(memory.NewSource(memory.WithJSON([]byte(`{"server": {"mode": "test"}}`)))); err == nil {
+		t.Fatal("expected load of invalid config to fail")
+	}
+	if mode := c.Get("server", "mode").String(""); mode != "" {
+		t.Fatalf("expected invalid config to be rejected, got mode %q", mode)
+	}
+
+	// the rejected source isn't kept so later updates still apply
+	src.Update(&source.ChangeSet{Data: []byte(`{"server": {"address": "localhost", "port": 9091}}`)})
+
+	v, err = w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port := v.Int(0); port != 9091 {
+		t.Fatalf("expected port 9091, got %d", port)
+	}
+	if err := c.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if port := c.Get("server", "port").Int(0); port != 9091 {
+		t.Fatalf("expected update after rejected load to be applied, got port %d", port)
+	}
+}
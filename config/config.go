@@ -38,6 +38,9 @@ type Options struct {
 	Loader loader.Loader
 	Reader reader.Reader
 	Source []source.Source
+	// Schema is a pointer to a struct which the config
+	// is bound to and validated against on every change
+	Schema interface{}
 
 	// for alternative data
 	Context context.Context
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"reflect"
 	"sync"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/asim/go-micro/v3/config/reader"
 	"github.com/asim/go-micro/v3/config/reader/json"
 	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/logger"
 )
 
 type config struct {
@@ -24,6 +26,7 @@ type config struct {
 }
 
 type watcher struct {
+	c     *config
 	lw    loader.Watcher
 	rd    reader.Reader
 	path  []string
@@ -68,7 +71,7 @@ func (c *config) Init(opts ...Option) error {
 		return err
 	}
 
-	return nil
+	return c.validate(c.vals)
 }
 
 func (c *config) Options() Options {
@@ -84,6 +87,27 @@ func (c *config) run() {
 				return err
 			}
 
+			c.RLock()
+			current := c.snap.Version
+			c.RUnlock()
+
+			if current >= snap.Version {
+				continue
+			}
+
+			vals, err := c.opts.Reader.Values(snap.ChangeSet)
+			if err != nil {
+				continue
+			}
+
+			// reject updates which fail validation
+			if err := c.validate(vals); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("config: rejecting update: %v", err)
+				}
+				continue
+			}
+
 			c.Lock()
 
 			if c.snap.Version >= snap.Version {
@@ -95,7 +119,7 @@ func (c *config) run() {
 			c.snap = snap
 
 			// set values
-			c.vals, _ = c.opts.Reader.Values(snap.ChangeSet)
+			c.vals = vals
 
 			c.Unlock()
 		}
@@ -160,14 +184,19 @@ func (c *config) Sync() error {
 		return err
 	}
 
-	c.Lock()
-	defer c.Unlock()
-
-	c.snap = snap
 	vals, err := c.opts.Reader.Values(snap.ChangeSet)
 	if err != nil {
 		return err
 	}
+
+	if err := c.validate(vals); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.snap = snap
 	c.vals = vals
 
 	return nil
@@ -230,6 +259,11 @@ func (c *config) Bytes() []byte {
 }
 
 func (c *config) Load(sources ...source.Source) error {
+	// the loader keeps the sources it loads so reject them beforehand
+	if err := c.validateSources(sources...); err != nil {
+		return err
+	}
+
 	if err := c.opts.Loader.Load(sources...); err != nil {
 		return err
 	}
@@ -239,14 +273,19 @@ func (c *config) Load(sources ...source.Source) error {
 		return err
 	}
 
-	c.Lock()
-	defer c.Unlock()
-
-	c.snap = snap
 	vals, err := c.opts.Reader.Values(snap.ChangeSet)
 	if err != nil {
 		return err
 	}
+
+	if err := c.validate(vals); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.snap = snap
 	c.vals = vals
 
 	return nil
@@ -261,6 +300,7 @@ func (c *config) Watch(path ...string) (Watcher, error) {
 	}
 
 	return &watcher{
+		c:     c,
 		lw:    w,
 		rd:    c.opts.Reader,
 		path:  path,
@@ -272,6 +312,47 @@ func (c *config) String() string {
 	return "config"
 }
 
+// validate binds vals to a new copy of the schema if one is set
+func (c *config) validate(vals reader.Values) error {
+	if c.opts.Schema == nil || vals == nil {
+		return nil
+	}
+	v := reflect.New(reflect.TypeOf(c.opts.Schema).Elem()).Interface()
+	return bindValue(vals.Get(), v, nil, vals.Get)
+}
+
+// validateSources checks the config is still valid with the sources merged in
+func (c *config) validateSources(sources ...source.Source) error {
+	if c.opts.Schema == nil {
+		return nil
+	}
+
+	snap, err := c.opts.Loader.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	sets := []*source.ChangeSet{snap.ChangeSet}
+	for _, s := range sources {
+		set, err := s.Read()
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+	}
+
+	set, err := c.opts.Reader.Merge(sets...)
+	if err != nil {
+		return err
+	}
+	vals, err := c.opts.Reader.Values(set)
+	if err != nil {
+		return err
+	}
+
+	return c.validate(vals)
+}
+
 func (w *watcher) Next() (reader.Value, error) {
 	for {
 		s, err := w.lw.Next()
@@ -289,11 +370,48 @@ func (w *watcher) Next() (reader.Value, error) {
 			return nil, err
 		}
 
+		// skip updates rejected by the schema
+		if w.c.opts.Schema != nil {
+			ok, err := w.valid(v)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
 		w.value = v.Get()
 		return w.value, nil
 	}
 }
 
+// valid checks the watched value against the schema in place of the value
+// at the path in the current config. The loader may already hold a later
+// update so its snapshot can't be used.
+func (w *watcher) valid(v reader.Values) (bool, error) {
+	if len(w.path) == 0 {
+		return w.c.validate(v) == nil, nil
+	}
+
+	w.c.RLock()
+	snap := w.c.snap
+	w.c.RUnlock()
+
+	vals, err := w.rd.Values(snap.ChangeSet)
+	if err != nil {
+		return false, err
+	}
+
+	var val interface{}
+	if err := v.Get().Scan(&val); err != nil {
+		return false, err
+	}
+	vals.Set(val, w.path...)
+
+	return w.c.validate(vals) == nil, nil
+}
+
 func (w *watcher) Stop() error {
 	return w.lw.Stop()
 }
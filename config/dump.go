@@ -0,0 +1,85 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/asim/go-micro/v3/config/reader"
+	"github.com/asim/go-micro/v3/config/source"
+)
+
+var (
+	// SecretKeys are redacted by Dump when found in a key
+	SecretKeys = []string{"password", "passwd", "secret", "token", "credential", "private", "apikey", "api_key"}
+	// Redacted replaces the value of secrets
+	Redacted = "[REDACTED]"
+)
+
+// Entry is a config value along with where it was defined
+type Entry struct {
+	Path       string             `json:"path"`
+	Value      interface{}        `json:"value"`
+	Provenance *source.Provenance `json:"provenance,omitempty"`
+}
+
+// Dump returns the effective config below path as a list of leaf values
+// sorted by path with their provenance. Secrets are redacted, those are
+// values found under a SecretKeys key or resolved by a preprocessor.
+func Dump(c Config, path ...string) []*Entry {
+	var entries []*Entry
+
+	var walk func(p []string, v interface{}, secret bool)
+	walk = func(p []string, v interface{}, secret bool) {
+		if len(p) > 0 && isSecret(p[len(p)-1]) {
+			secret = true
+		}
+
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			for k, c := range m {
+				walk(append(append([]string{}, p...), k), c, secret)
+			}
+			return
+		}
+
+		prov := provenance(c.Get(p...))
+		if secret || (prov != nil && prov.Secret) {
+			v = Redacted
+		}
+
+		entries = append(entries, &Entry{
+			Path:       strings.Join(p, "."),
+			Value:      v,
+			Provenance: prov,
+		})
+	}
+
+	var v interface{}
+	if err := c.Get(path...).Scan(&v); err != nil || v == nil {
+		return nil
+	}
+	walk(path, v, false)
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries
+}
+
+// provenance returns where the value was defined if the reader tracks it
+func provenance(v reader.Value) *source.Provenance {
+	if p, ok := v.(reader.Provenancer); ok {
+		return p.Provenance()
+	}
+	return nil
+}
+
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range SecretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
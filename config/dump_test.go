@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/asim/go-micro/v3/config/source/env"
+	"github.com/asim/go-micro/v3/config/source/file"
+)
+
+func TestDumpProvenance(t *testing.T) {
+	fh := createFileForIssue18(t, `{
+  "database": {
+    "host": "localhost",
+    "password": "hunter2"
+  },
+  "name": "test"
+}`)
+	path := fh.Name()
+	defer func() {
+		fh.Close()
+		os.Remove(path)
+	}()
+
+	os.Setenv("DATABASE_HOST", "db.local")
+	defer os.Unsetenv("DATABASE_HOST")
+
+	conf, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conf.Load(
+		file.NewSource(file.WithPath(path)),
+		env.NewSource(),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	p := provenance(conf.Get("name"))
+	if p == nil || p.Source != "file" || p.File != path || p.Line != 6 {
+		t.Fatalf("unexpected provenance for name: %+v", p)
+	}
+
+	p = provenance(conf.Get("database", "host"))
+	if p == nil || p.Source != "env" || p.Env != "DATABASE_HOST" {
+		t.Fatalf("unexpected provenance for database.host: %+v", p)
+	}
+
+	entries := Dump(conf, "database")
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Path != "database.host" || entries[0].Value != "db.local" {
+		t.Fatalf("unexpected entry %+v", entries[0])
+	}
+	if entries[1].Path != "database.password" || entries[1].Value != Redacted {
+		t.Fatalf("expected password to be redacted, got %+v", entries[1])
+	}
+	if entries[1].Provenance == nil || entries[1].Provenance.Line != 4 {
+		t.Fatalf("unexpected provenance for database.password: %+v", entries[1].Provenance)
+	}
+}
@@ -0,0 +1,274 @@
+// Package flags provides dynamic feature flags built on config
+package flags
+
+import (
+	"context"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+var (
+	// OverrideHeader is the metadata header forced flags are sent in e.g new-ui=true,beta=false
+	OverrideHeader = "Micro-Flags"
+	// DefaultPath is the config path flags are defined under
+	DefaultPath = []string{"flags"}
+	// DefaultHashKey is the metadata key used when there's no account
+	DefaultHashKey = "Micro-Flag-Key"
+)
+
+// Flag is the definition of a feature flag e.g
+//
+//	{
+//		"flags": {
+//			"new-ui": {
+//				"enabled": true,
+//				"accounts": ["admin"],
+//				"metadata": {"X-Beta": "true"},
+//				"percent": 10
+//			}
+//		}
+//	}
+//
+// A disabled flag is always off and an enabled flag without accounts,
+// metadata or a percent is always on. Otherwise it's on if the account
+// is listed, the request metadata matches or the request falls
+// within the percentage rollout.
+type Flag struct {
+	Enabled  bool              `json:"enabled"`
+	Accounts []string          `json:"accounts,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Percent  int               `json:"percent,omitempty"`
+}
+
+// Flags evaluates feature flags read from config
+type Flags struct {
+	opts Options
+
+	sync.RWMutex
+	flags map[string]*Flag
+
+	exit chan bool
+}
+
+type overridesKey struct{}
+
+// NewFlags returns flags read from the config which are updated as it changes
+func NewFlags(opts ...Option) (*Flags, error) {
+	options := Options{
+		Config:  config.DefaultConfig,
+		Path:    DefaultPath,
+		Stats:   stats.DefaultStats,
+		HashKey: DefaultHashKey,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	f := &Flags{
+		opts:  options,
+		flags: make(map[string]*Flag),
+		exit:  make(chan bool),
+	}
+
+	if err := f.load(options.Config.Get(options.Path...)); err != nil {
+		return nil, err
+	}
+
+	w, err := options.Config.Watch(options.Path...)
+	if err != nil {
+		return nil, err
+	}
+
+	go f.watch(w)
+
+	return f, nil
+}
+
+func (f *Flags) load(v interface{ Scan(interface{}) error }) error {
+	flags := make(map[string]*Flag)
+	if err := v.Scan(&flags); err != nil {
+		return err
+	}
+
+	f.Lock()
+	f.flags = flags
+	f.Unlock()
+
+	return nil
+}
+
+func (f *Flags) watch(w config.Watcher) {
+	go func() {
+		<-f.exit
+		w.Stop()
+	}()
+
+	for {
+		v, err := w.Next()
+		if err != nil {
+			return
+		}
+		if err := f.load(v); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("flags: invalid flag definitions: %v", err)
+			}
+		}
+	}
+}
+
+// Flag returns the definition of the named flag
+func (f *Flags) Flag(name string) (*Flag, bool) {
+	f.RLock()
+	defer f.RUnlock()
+	fl, ok := f.flags[name]
+	return fl, ok
+}
+
+// List returns the names of all the flags
+func (f *Flags) List() []string {
+	f.RLock()
+	defer f.RUnlock()
+
+	names := make([]string, 0, len(f.flags))
+	for name := range f.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Enabled evaluates the named flag for the request in ctx
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	on := f.evaluate(ctx, name)
+
+	if c, ok := f.opts.Stats.(stats.Counter); ok {
+		c.Count("flags."+name+"."+strconv.FormatBool(on), 1)
+	}
+
+	return on
+}
+
+func (f *Flags) evaluate(ctx context.Context, name string) bool {
+	if o, ok := Overrides(ctx)[name]; ok {
+		return o
+	}
+
+	fl, ok := f.Flag(name)
+	if !ok || !fl.Enabled {
+		return false
+	}
+
+	// enabled without any targeting is on for everyone
+	if len(fl.Accounts) == 0 && len(fl.Metadata) == 0 && fl.Percent == 0 {
+		return true
+	}
+
+	var key string
+
+	if acc, ok := auth.AccountFromContext(ctx); ok && acc != nil {
+		key = acc.ID
+		for _, id := range fl.Accounts {
+			if id == acc.ID {
+				return true
+			}
+		}
+	}
+
+	md, _ := metadata.FromContext(ctx)
+
+	if len(fl.Metadata) > 0 {
+		matched := true
+		for k, v := range fl.Metadata {
+			if val, ok := md.Get(k); !ok || val != v {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	if fl.Percent >= 100 {
+		return true
+	}
+	if fl.Percent <= 0 {
+		return false
+	}
+
+	if len(key) == 0 {
+		key, _ = md.Get(f.opts.HashKey)
+	}
+	if len(key) == 0 {
+		return false
+	}
+
+	return bucket(name, key) < fl.Percent
+}
+
+// Stop watching the config for changes
+func (f *Flags) Stop() error {
+	select {
+	case <-f.exit:
+	default:
+		close(f.exit)
+	}
+	return nil
+}
+
+// bucket places the key in one of 100 buckets, salted with
+// the flag name so keys aren't in the same rollout for every flag
+func bucket(name, key string) int {
+	return int(crc32.ChecksumIEEE([]byte(name+":"+key)) % 100)
+}
+
+// Force returns a context with the flag forced on or off. Forced flags
+// are propagated to downstream services by the client wrapper.
+func Force(ctx context.Context, name string, on bool) context.Context {
+	overrides := make(map[string]bool)
+	for k, v := range Overrides(ctx) {
+		overrides[k] = v
+	}
+	overrides[name] = on
+	return context.WithValue(ctx, overridesKey{}, overrides)
+}
+
+// Overrides returns the flags forced in the context
+func Overrides(ctx context.Context) map[string]bool {
+	o, _ := ctx.Value(overridesKey{}).(map[string]bool)
+	return o
+}
+
+func encode(overrides map[string]bool) string {
+	parts := make([]string, 0, len(overrides))
+	for k, v := range overrides {
+		parts = append(parts, k+"="+strconv.FormatBool(v))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func decode(header string) map[string]bool {
+	overrides := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v, err := strconv.ParseBool(kv[1])
+		if err != nil {
+			continue
+		}
+		overrides[kv[0]] = v
+	}
+	return overrides
+}
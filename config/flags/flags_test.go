@@ -0,0 +1,158 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/memory"
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/server"
+)
+
+const testFlags = `{
+	"flags": {
+		"off": {"enabled": false, "percent": 100},
+		"admins": {"enabled": true, "accounts": ["admin"]},
+		"beta": {"enabled": true, "metadata": {"X-Beta": "true"}},
+		"half": {"enabled": true, "percent": 50},
+		"all": {"enabled": true}
+	}
+}`
+
+type updater interface {
+	Update(*source.ChangeSet)
+}
+
+func newFlags(t *testing.T, opts ...Option) (*Flags, updater) {
+	src := memory.NewSource(memory.WithJSON([]byte(testFlags)))
+	c, err := config.NewConfig(config.WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFlags(append([]Option{Config(c), Stats(stats.NewStats())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, src.(updater)
+}
+
+func TestEnabled(t *testing.T) {
+	f, _ := newFlags(t)
+	defer f.Stop()
+
+	ctx := context.Background()
+	admin := auth.ContextWithAccount(ctx, &auth.Account{ID: "admin"})
+	beta := metadata.NewContext(ctx, metadata.Metadata{"X-Beta": "true"})
+
+	testData := []struct {
+		ctx  context.Context
+		name string
+		on   bool
+	}{
+		{ctx, "off", false},
+		{ctx, "missing", false},
+		{ctx, "admins", false},
+		{admin, "admins", true},
+		{admin, "off", false},
+		{ctx, "beta", false},
+		{beta, "beta", true},
+		{ctx, "all", true},
+		{Force(ctx, "off", true), "off", true},
+		{Force(admin, "admins", false), "admins", false},
+	}
+
+	for _, d := range testData {
+		if on := f.Enabled(d.ctx, d.name); on != d.on {
+			t.Fatalf("expected %s to be %v, got %v", d.name, d.on, on)
+		}
+	}
+
+	st, err := f.opts.Stats.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := st[0].Counters["flags.admins.true"]; n != 1 {
+		t.Fatalf("expected flags.admins.true count of 1, got %d", n)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	f, _ := newFlags(t)
+	defer f.Stop()
+
+	var on int
+	for i := 0; i < 1000; i++ {
+		ctx := auth.ContextWithAccount(context.Background(), &auth.Account{ID: fmt.Sprintf("user-%d", i)})
+		first := f.Enabled(ctx, "half")
+		if first != f.Enabled(ctx, "half") {
+			t.Fatal("expected stable evaluation for the same account")
+		}
+		if first {
+			on++
+		}
+	}
+
+	if on < 400 || on > 600 {
+		t.Fatalf("expected roughly half the accounts enabled, got %d/1000", on)
+	}
+
+	// no account or hash key is never in a rollout
+	if f.Enabled(context.Background(), "half") {
+		t.Fatal("expected half to be off without a key")
+	}
+}
+
+func TestReload(t *testing.T) {
+	f, src := newFlags(t)
+	defer f.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	src.Update(&source.ChangeSet{Data: []byte(`{"flags": {"off": {"enabled": true, "percent": 100}}}`)})
+
+	for i := 0; i < 50; i++ {
+		if f.Enabled(context.Background(), "off") {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("expected flag to be enabled after reload")
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{
+		OverrideHeader: encode(map[string]bool{"off": true, "beta": false}),
+	})
+
+	for _, allow := range []bool{true, false} {
+		f, _ := newFlags(t, AllowOverrides(allow))
+
+		var got map[string]bool
+		var header bool
+		h := f.NewHandlerWrapper()(func(ctx context.Context, req server.Request, rsp interface{}) error {
+			got = Overrides(ctx)
+			_, header = metadata.Get(ctx, OverrideHeader)
+			return nil
+		})
+		h(ctx, nil, nil)
+		f.Stop()
+
+		if header {
+			t.Fatal("expected override header to be removed")
+		}
+		if !allow {
+			if len(got) > 0 {
+				t.Fatalf("expected overrides to be ignored, got %v", got)
+			}
+			continue
+		}
+		if len(got) != 2 || !got["off"] || got["beta"] {
+			t.Fatalf("unexpected overrides %v", got)
+		}
+	}
+}
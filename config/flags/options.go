@@ -0,0 +1,58 @@
+package flags
+
+import (
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/debug/stats"
+)
+
+type Options struct {
+	// Config the flag definitions are read from
+	Config config.Config
+	// Path of the flag definitions in the config
+	Path []string
+	// Stats records flag evaluations when it's a stats.Counter
+	Stats stats.Stats
+	// HashKey is the metadata key used to place requests without
+	// an account into a percentage rollout
+	HashKey string
+	// AllowOverrides accepts forced flags from incoming requests
+	AllowOverrides bool
+}
+
+type Option func(o *Options)
+
+// Config sets the config flag definitions are read from
+func Config(c config.Config) Option {
+	return func(o *Options) {
+		o.Config = c
+	}
+}
+
+// Path sets the path of the flag definitions in the config
+func Path(p ...string) Option {
+	return func(o *Options) {
+		o.Path = p
+	}
+}
+
+// Stats sets the stats flag evaluations are recorded in
+func Stats(s stats.Stats) Option {
+	return func(o *Options) {
+		o.Stats = s
+	}
+}
+
+// HashKey sets the metadata key used for percentage rollouts when there's no account
+func HashKey(k string) Option {
+	return func(o *Options) {
+		o.HashKey = k
+	}
+}
+
+// AllowOverrides accepts forced flags from incoming requests. Only
+// enable it in test environments since callers can switch flags.
+func AllowOverrides(b bool) Option {
+	return func(o *Options) {
+		o.AllowOverrides = b
+	}
+}
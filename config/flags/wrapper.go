@@ -0,0 +1,64 @@
+package flags
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/server"
+)
+
+type clientWrapper struct {
+	client.Client
+}
+
+// setHeader propagates the flags forced in the context
+func (c *clientWrapper) setHeader(ctx context.Context) context.Context {
+	overrides := Overrides(ctx)
+	if len(overrides) == 0 {
+		return ctx
+	}
+	return metadata.Set(ctx, OverrideHeader, encode(overrides))
+}
+
+func (c *clientWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	return c.Client.Call(c.setHeader(ctx), req, rsp, opts...)
+}
+
+func (c *clientWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return c.Client.Stream(c.setHeader(ctx), req, opts...)
+}
+
+func (c *clientWrapper) Publish(ctx context.Context, p client.Message, opts ...client.PublishOption) error {
+	return c.Client.Publish(c.setHeader(ctx), p, opts...)
+}
+
+// NewClientWrapper sends the flags forced in the context to downstream services
+func NewClientWrapper() client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &clientWrapper{c}
+	}
+}
+
+// NewHandlerWrapper reads flags forced by the caller into the context
+// when overrides are allowed, otherwise the header is dropped
+func (f *Flags) NewHandlerWrapper() server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			header, ok := metadata.Get(ctx, OverrideHeader)
+			if !ok {
+				return h(ctx, req, rsp)
+			}
+
+			ctx = metadata.Delete(ctx, OverrideHeader)
+			if !f.opts.AllowOverrides {
+				return h(ctx, req, rsp)
+			}
+
+			for name, on := range decode(header) {
+				ctx = Force(ctx, name, on)
+			}
+			return h(ctx, req, rsp)
+		}
+	}
+}
@@ -33,8 +33,9 @@ type memory struct {
 }
 
 type updateValue struct {
-	version string
-	value   reader.Value
+	version    string
+	value      reader.Value
+	provenance map[string]*source.Provenance
 }
 
 type watcher struct {
@@ -58,18 +59,30 @@ func (m *memory) watch(idx int, s source.Source) {
 
 			m.Lock()
 
-			// save
-			m.sets[idx] = cs
+			// merge a copy so the sets are only changed
+			// once the change set is known to be valid
+			sets := make([]*source.ChangeSet, len(m.sets))
+			copy(sets, m.sets)
+			sets[idx] = cs
 
 			// merge sets
-			set, err := m.opts.Reader.Merge(m.sets...)
+			set, err := m.opts.Reader.Merge(sets...)
 			if err != nil {
 				m.Unlock()
 				return err
 			}
+			set.Provenance = m.provenance(m.sources, sets)
 
 			// set values
-			m.vals, _ = m.opts.Reader.Values(set)
+			vals, err := m.opts.Reader.Values(set)
+			if err != nil {
+				m.Unlock()
+				return err
+			}
+
+			// save
+			m.sets = sets
+			m.vals = vals
 			m.snap = &loader.Snapshot{
 				ChangeSet: set,
 				Version:   genVer(),
@@ -138,9 +151,15 @@ func (m *memory) reload() error {
 		m.Unlock()
 		return err
 	}
+	set.Provenance = m.provenance(m.sources, m.sets)
 
 	// set values
-	m.vals, _ = m.opts.Reader.Values(set)
+	vals, err := m.opts.Reader.Values(set)
+	if err != nil {
+		m.Unlock()
+		return err
+	}
+	m.vals = vals
 	m.snap = &loader.Snapshot{
 		ChangeSet: set,
 		Version:   genVer(),
@@ -172,8 +191,9 @@ func (m *memory) update() {
 		}
 
 		uv := updateValue{
-			version: m.snap.Version,
-			value:   vals.Get(w.path...),
+			version:    m.snap.Version,
+			value:      vals.Get(w.path...),
+			provenance: subProvenance(snap.ChangeSet.Provenance, w.path),
 		}
 
 		select {
@@ -209,6 +229,8 @@ func (m *memory) Snapshot() (*loader.Snapshot, error) {
 func (m *memory) Sync() error {
 	//nolint:prealloc
 	var sets []*source.ChangeSet
+	//nolint:prealloc
+	var sources []source.Source
 
 	m.Lock()
 
@@ -222,6 +244,7 @@ func (m *memory) Sync() error {
 			continue
 		}
 		sets = append(sets, ch)
+		sources = append(sources, source)
 	}
 
 	// merge sets
@@ -230,6 +253,7 @@ func (m *memory) Sync() error {
 		m.Unlock()
 		return err
 	}
+	set.Provenance = m.provenance(sources, sets)
 
 	// set values
 	vals, err := m.opts.Reader.Values(set)
@@ -371,14 +395,15 @@ func (m *memory) String() string {
 }
 
 func (w *watcher) Next() (*loader.Snapshot, error) {
-	update := func(v reader.Value) *loader.Snapshot {
+	update := func(v reader.Value, p map[string]*source.Provenance) *loader.Snapshot {
 		w.value = v
 
 		cs := &source.ChangeSet{
-			Data:      v.Bytes(),
-			Format:    w.reader.String(),
-			Source:    "memory",
-			Timestamp: time.Now(),
+			Data:       v.Bytes(),
+			Format:     w.reader.String(),
+			Source:     "memory",
+			Timestamp:  time.Now(),
+			Provenance: p,
 		}
 		cs.Checksum = cs.Sum()
 
@@ -407,7 +432,7 @@ func (w *watcher) Next() (*loader.Snapshot, error) {
 				continue
 			}
 
-			return update(v), nil
+			return update(v, uv.provenance), nil
 		}
 	}
 }
@@ -423,6 +448,74 @@ func (w *watcher) Stop() error {
 	return nil
 }
 
+// provenance records the source of every path in sets. Later sets
+// take precedence over earlier ones the same as when merging.
+func (m *memory) provenance(sources []source.Source, sets []*source.ChangeSet) map[string]*source.Provenance {
+	prov := make(map[string]*source.Provenance)
+
+	for i, set := range sets {
+		if set == nil || len(set.Data) == 0 || i >= len(sources) {
+			continue
+		}
+
+		// merge the single set to normalise its format
+		ch, err := m.opts.Reader.Merge(set)
+		if err != nil {
+			continue
+		}
+		vals, err := m.opts.Reader.Values(ch)
+		if err != nil {
+			continue
+		}
+
+		src := sources[i]
+		locate := func(path []string) *source.Provenance {
+			if l, ok := src.(source.Locator); ok {
+				if p := l.Locate(set, path...); p != nil {
+					return p
+				}
+			}
+			return &source.Provenance{Source: src.String()}
+		}
+
+		var walk func(path []string, v interface{})
+		walk = func(path []string, v interface{}) {
+			if len(path) > 0 {
+				prov[strings.Join(path, ".")] = locate(path)
+			}
+			if mv, ok := v.(map[string]interface{}); ok {
+				for k, c := range mv {
+					walk(append(append([]string{}, path...), k), c)
+				}
+			}
+		}
+		walk(nil, vals.Map())
+	}
+
+	return prov
+}
+
+// subProvenance returns the provenance of values below path relative to it
+func subProvenance(prov map[string]*source.Provenance, path []string) map[string]*source.Provenance {
+	if len(path) == 0 || prov == nil {
+		return prov
+	}
+
+	prefix := strings.Join(path, ".")
+	sub := make(map[string]*source.Provenance)
+
+	for k, p := range prov {
+		switch {
+		case k == prefix:
+			sub[""] = p
+		case strings.HasPrefix(k, prefix+"."):
+			sub[strings.TrimPrefix(k, prefix+".")] = p
+		}
+	}
+
+	return sub
+}
+
 func genVer() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
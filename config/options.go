@@ -26,3 +26,11 @@ func WithReader(r reader.Reader) Option {
 		o.Reader = r
 	}
 }
+
+// WithSchema validates the config against the tagged struct v on load
+// and rejects any update which fails to bind. See Bind for the tags.
+func WithSchema(v interface{}) Option {
+	return func(o *Options) {
+		o.Schema = v
+	}
+}
@@ -3,6 +3,7 @@ package json
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -15,23 +16,74 @@ import (
 type jsonValues struct {
 	ch *source.ChangeSet
 	sj *simple.Json
+	// paths of values replaced by the preprocessors
+	secret map[string]bool
 }
 
 type jsonValue struct {
 	*simple.Json
+	prov *source.Provenance
 }
 
-func newValues(ch *source.ChangeSet) (reader.Values, error) {
+func newValues(ch *source.ChangeSet, pre ...reader.Preprocessor) (reader.Values, error) {
 	sj := simple.New()
-	data, _ := reader.ReplaceEnvVars(ch.Data)
+	raw, _ := reader.ReplaceEnvVars(ch.Data)
+	data := raw
+	for _, p := range pre {
+		var err error
+		if data, err = p(data); err != nil {
+			return nil, err
+		}
+	}
 	if err := sj.UnmarshalJSON(data); err != nil {
 		sj.SetPath(nil, string(ch.Data))
 	}
-	return &jsonValues{ch, sj}, nil
+
+	// mark the values the preprocessors replaced as secret
+	secret := make(map[string]bool)
+	if string(raw) != string(data) {
+		var before, after interface{}
+		if json.Unmarshal(raw, &before) == nil && json.Unmarshal(data, &after) == nil {
+			replaced(before, after, nil, secret)
+		}
+	}
+
+	return &jsonValues{ch, sj, secret}, nil
+}
+
+// replaced records the paths of the leaf values which differ
+func replaced(before, after interface{}, path []string, secret map[string]bool) {
+	bm, bok := before.(map[string]interface{})
+	am, aok := after.(map[string]interface{})
+	if bok && aok {
+		for k, v := range am {
+			replaced(bm[k], v, append(path[:len(path):len(path)], k), secret)
+		}
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		secret[strings.Join(path, ".")] = true
+	}
 }
 
 func (j *jsonValues) Get(path ...string) reader.Value {
-	return &jsonValue{j.sj.GetPath(path...)}
+	key := strings.Join(path, ".")
+
+	var prov *source.Provenance
+	if j.ch.Provenance != nil {
+		prov = j.ch.Provenance[key]
+	}
+	if j.secret[key] {
+		p := &source.Provenance{Source: j.ch.Source}
+		if prov != nil {
+			c := *prov
+			p = &c
+		}
+		p.Secret = true
+		prov = p
+	}
+
+	return &jsonValue{j.sj.GetPath(path...), prov}
 }
 
 func (j *jsonValues) Del(path ...string) {
@@ -199,3 +251,7 @@ func (j *jsonValue) Bytes() []byte {
 	}
 	return b
 }
+
+func (j *jsonValue) Provenance() *source.Provenance {
+	return j.prov
+}
@@ -7,6 +7,9 @@ import (
 
 type Options struct {
 	Encoding map[string]encoder.Encoder
+	// Preprocessors are applied to the merged data
+	// after env vars have been replaced
+	Preprocessors []Preprocessor
 }
 
 type Option func(o *Options)
@@ -31,3 +34,10 @@ func WithEncoder(e encoder.Encoder) Option {
 		o.Encoding[e.String()] = e
 	}
 }
+
+// WithPreprocessor appends preprocessors applied to the data before values are read
+func WithPreprocessor(p ...Preprocessor) Option {
+	return func(o *Options) {
+		o.Preprocessors = append(o.Preprocessors, p...)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"regexp"
 )
 
+// Preprocessor transforms raw config data before it is parsed
+type Preprocessor func(raw []byte) ([]byte, error)
+
 func ReplaceEnvVars(raw []byte) ([]byte, error) {
 	re := regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
 	if re.Match(raw) {
@@ -36,3 +36,9 @@ type Value interface {
 	Scan(val interface{}) error
 	Bytes() []byte
 }
+
+// Provenancer is implemented by values which know where they were defined
+type Provenancer interface {
+	// Provenance returns where the value was defined, nil if unknown
+	Provenance() *source.Provenance
+}
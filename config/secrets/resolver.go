@@ -0,0 +1,292 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RefPrefix is the prefix of secret references e.g secret://db/password
+	RefPrefix = "secret://"
+	// EncPrefix is the prefix of base64 encoded encrypted values e.g enc:b64data
+	EncPrefix = "enc:"
+)
+
+var (
+	// ErrNotFound is returned by a Resolver when a secret doesn't exist
+	ErrNotFound = errors.New("secret not found")
+
+	refRe = regexp.MustCompile(`"(` + regexp.QuoteMeta(RefPrefix) + `|` + regexp.QuoteMeta(EncPrefix) + `)([^"\\]+)"`)
+)
+
+// Resolver looks up the value of a secret by name
+type Resolver interface {
+	// Resolve returns the value of the named secret or ErrNotFound
+	Resolve(name string) ([]byte, error)
+	// Resolver implementation
+	String() string
+}
+
+// ResolveOptions configure the Preprocessor
+type ResolveOptions struct {
+	// Resolvers are tried in order to resolve secret references
+	Resolvers []Resolver
+	// Secrets decrypts encrypted values
+	Secrets Secrets
+	// TTL of cached secrets, zero caches forever
+	TTL time.Duration
+	// Refresh is the interval to check for rotated secrets
+	Refresh time.Duration
+	// OnRotate is called when a refresh finds a rotated secret
+	OnRotate func() error
+}
+
+// ResolveOption sets ResolveOptions
+type ResolveOption func(*ResolveOptions)
+
+// WithResolver appends a resolver used to resolve secret references
+func WithResolver(r ...Resolver) ResolveOption {
+	return func(o *ResolveOptions) {
+		o.Resolvers = append(o.Resolvers, r...)
+	}
+}
+
+// WithSecrets sets the Secrets used to decrypt values
+func WithSecrets(s Secrets) ResolveOption {
+	return func(o *ResolveOptions) {
+		o.Secrets = s
+	}
+}
+
+// CacheTTL sets how long resolved secrets are cached for
+func CacheTTL(d time.Duration) ResolveOption {
+	return func(o *ResolveOptions) {
+		o.TTL = d
+	}
+}
+
+// RefreshInterval checks for rotated secrets every interval and calls
+// fn when one has changed, typically the Sync method of the config
+func RefreshInterval(d time.Duration, fn func() error) ResolveOption {
+	return func(o *ResolveOptions) {
+		o.Refresh = d
+		o.OnRotate = fn
+	}
+}
+
+type cached struct {
+	value   []byte
+	expires time.Time
+}
+
+// Preprocessor replaces secret references and encrypted values in
+// config data. Its Process method is a reader.Preprocessor e.g
+//
+//	p := secrets.NewPreprocessor(secrets.WithResolver(env.NewResolver()))
+//	r := json.NewReader(reader.WithPreprocessor(p.Process))
+//	c, _ := config.NewConfig(config.WithReader(r))
+type Preprocessor struct {
+	opts ResolveOptions
+
+	sync.RWMutex
+	cache map[string]*cached
+	once  sync.Once
+	exit  chan bool
+}
+
+// NewPreprocessor returns a Preprocessor for the given options
+func NewPreprocessor(opts ...ResolveOption) *Preprocessor {
+	var options ResolveOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Preprocessor{
+		opts:  options,
+		cache: make(map[string]*cached),
+		exit:  make(chan bool),
+	}
+}
+
+// Process replaces every quoted secret reference and encrypted
+// value in the json data with its json encoded plain text value
+func (p *Preprocessor) Process(raw []byte) ([]byte, error) {
+	if !refRe.Match(raw) {
+		return raw, nil
+	}
+
+	// start watching for rotated secrets once in use
+	if p.opts.Refresh > 0 && p.opts.OnRotate != nil {
+		p.once.Do(func() { go p.run() })
+	}
+
+	var gerr error
+
+	res := refRe.ReplaceAllFunc(raw, func(m []byte) []byte {
+		parts := refRe.FindSubmatch(m)
+
+		var val []byte
+		var err error
+
+		switch string(parts[1]) {
+		case RefPrefix:
+			val, err = p.resolve(string(parts[2]))
+		case EncPrefix:
+			val, err = p.decrypt(string(parts[2]))
+		}
+		if err != nil {
+			gerr = err
+			return m
+		}
+
+		b, err := json.Marshal(string(val))
+		if err != nil {
+			gerr = err
+			return m
+		}
+		return b
+	})
+
+	if gerr != nil {
+		return nil, gerr
+	}
+
+	return res, nil
+}
+
+// Refresh resolves all the cached secrets again and
+// reports whether any of them have been rotated
+func (p *Preprocessor) Refresh() (bool, error) {
+	p.RLock()
+	names := make([]string, 0, len(p.cache))
+	for name := range p.cache {
+		names = append(names, name)
+	}
+	p.RUnlock()
+
+	var rotated bool
+	var gerr []string
+
+	for _, name := range names {
+		val, err := p.lookup(name)
+		if err != nil {
+			gerr = append(gerr, err.Error())
+			continue
+		}
+
+		p.Lock()
+		if c, ok := p.cache[name]; ok && string(c.value) != string(val) {
+			rotated = true
+		}
+		p.cache[name] = p.entry(val)
+		p.Unlock()
+	}
+
+	if len(gerr) > 0 {
+		return rotated, errors.New(strings.Join(gerr, "\n"))
+	}
+
+	return rotated, nil
+}
+
+// Stop the refresh loop
+func (p *Preprocessor) Stop() error {
+	select {
+	case <-p.exit:
+	default:
+		close(p.exit)
+	}
+	return nil
+}
+
+func (p *Preprocessor) run() {
+	t := time.NewTicker(p.opts.Refresh)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.exit:
+			return
+		case <-t.C:
+			// errors keep the cached values in place
+			rotated, _ := p.Refresh()
+			if rotated {
+				p.opts.OnRotate()
+			}
+		}
+	}
+}
+
+func (p *Preprocessor) entry(val []byte) *cached {
+	c := &cached{value: val}
+	if p.opts.TTL > 0 {
+		c.expires = time.Now().Add(p.opts.TTL)
+	}
+	return c
+}
+
+func (p *Preprocessor) resolve(name string) ([]byte, error) {
+	p.RLock()
+	c, ok := p.cache[name]
+	p.RUnlock()
+
+	if ok && (c.expires.IsZero() || time.Now().Before(c.expires)) {
+		return c.value, nil
+	}
+
+	val, err := p.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	p.cache[name] = p.entry(val)
+	p.Unlock()
+
+	return val, nil
+}
+
+// lookup asks each resolver in turn for the secret
+// and decrypts the value if it's encrypted
+func (p *Preprocessor) lookup(name string) ([]byte, error) {
+	for _, r := range p.opts.Resolvers {
+		val, err := r.Resolve(name)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error resolving secret %s from %s: %v", name, r.String(), err)
+		}
+		if strings.HasPrefix(string(val), EncPrefix) {
+			return p.decrypt(strings.TrimPrefix(string(val), EncPrefix))
+		}
+		return val, nil
+	}
+	return nil, fmt.Errorf("secret %s: %v", name, ErrNotFound)
+}
+
+func (p *Preprocessor) decrypt(enc string) ([]byte, error) {
+	if p.opts.Secrets == nil {
+		return nil, errors.New("no secrets set to decrypt value")
+	}
+	b, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value: %v", err)
+	}
+	return p.opts.Secrets.Decrypt(b)
+}
+
+// Encrypt returns the value encrypted by s in the form understood by the Preprocessor
+func Encrypt(s Secrets, val []byte) (string, error) {
+	b, err := s.Encrypt(val)
+	if err != nil {
+		return "", err
+	}
+	return EncPrefix + base64.StdEncoding.EncodeToString(b), nil
+}
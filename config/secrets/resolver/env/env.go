@@ -0,0 +1,37 @@
+// Package env resolves secrets from environment variables
+package env
+
+import (
+	"os"
+	"strings"
+
+	"github.com/asim/go-micro/v3/config/secrets"
+)
+
+type env struct {
+	prefix string
+}
+
+// Resolve looks up the env var for name. The name is upper cased
+// and any / . or - are replaced so db/password reads DB_PASSWORD.
+func (e *env) Resolve(name string) ([]byte, error) {
+	key := e.prefix + strings.ToUpper(strings.NewReplacer("/", "_", ".", "_", "-", "_").Replace(name))
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return nil, secrets.ErrNotFound
+	}
+	return []byte(val), nil
+}
+
+func (e *env) String() string {
+	return "env"
+}
+
+// NewResolver returns a resolver which reads secrets from env vars with the optional prefix
+func NewResolver(prefix ...string) secrets.Resolver {
+	var p string
+	if len(prefix) > 0 {
+		p = prefix[0]
+	}
+	return &env{prefix: p}
+}
@@ -0,0 +1,71 @@
+// Package file resolves secrets from a local keyring file
+package file
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/secrets"
+)
+
+type file struct {
+	path string
+
+	sync.Mutex
+	modTime time.Time
+	keys    map[string]string
+}
+
+// Resolve returns the named secret, reloading the keyring if it has been modified
+func (f *file) Resolve(name string) ([]byte, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	val, ok := f.keys[name]
+	if !ok {
+		return nil, secrets.ErrNotFound
+	}
+	return []byte(val), nil
+}
+
+func (f *file) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if f.keys != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]string)
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return err
+	}
+
+	f.keys = keys
+	f.modTime = info.ModTime()
+	return nil
+}
+
+func (f *file) String() string {
+	return "file"
+}
+
+// NewResolver returns a resolver for the keyring file at path. The
+// keyring is a json object of secret names to values. Values may be
+// encrypted using secrets.Encrypt so the file is safe to keep on disk.
+func NewResolver(path string) secrets.Resolver {
+	return &file{path: path}
+}
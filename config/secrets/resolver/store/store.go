@@ -0,0 +1,38 @@
+// Package store resolves secrets from a store.Store
+package store
+
+import (
+	"github.com/asim/go-micro/v3/config/secrets"
+	"github.com/asim/go-micro/v3/store"
+)
+
+type storeResolver struct {
+	store  store.Store
+	prefix string
+}
+
+// Resolve reads the record for prefix + name
+func (s *storeResolver) Resolve(name string) ([]byte, error) {
+	recs, err := s.store.Read(s.prefix + name)
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return nil, secrets.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return recs[0].Value, nil
+}
+
+func (s *storeResolver) String() string {
+	return "store"
+}
+
+// NewResolver returns a resolver which reads secrets from the store.
+// An optional prefix is prepended to the name of the secret.
+func NewResolver(s store.Store, prefix ...string) secrets.Resolver {
+	var p string
+	if len(prefix) > 0 {
+		p = prefix[0]
+	}
+	return &storeResolver{store: s, prefix: p}
+}
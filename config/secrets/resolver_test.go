@@ -0,0 +1,163 @@
+package secrets_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/config/reader"
+	"github.com/asim/go-micro/v3/config/reader/json"
+	"github.com/asim/go-micro/v3/config/secrets"
+	"github.com/asim/go-micro/v3/config/secrets/resolver/env"
+	"github.com/asim/go-micro/v3/config/secrets/resolver/file"
+	"github.com/asim/go-micro/v3/config/secrets/secretbox"
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/memory"
+)
+
+func TestPreprocessor(t *testing.T) {
+	sb := secretbox.NewSecrets()
+	if err := sb.Init(secrets.Key([]byte("the-key-is-32-bytes-long-exactly"))); err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := secrets.Encrypt(sb, []byte("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "keyring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyring := filepath.Join(dir, "keyring.json")
+	if err := ioutil.WriteFile(keyring, []byte(`{"db/password": "`+enc+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("API_TOKEN", "token-1")
+	defer os.Unsetenv("API_TOKEN")
+
+	p := secrets.NewPreprocessor(
+		secrets.WithSecrets(sb),
+		secrets.WithResolver(file.NewResolver(keyring), env.NewResolver()),
+	)
+
+	c, err := config.NewConfig(
+		config.WithReader(json.NewReader(reader.WithPreprocessor(p.Process))),
+		config.WithSource(memory.NewSource(memory.WithJSON([]byte(`{
+			"db": {"password": "secret://db/password"},
+			"api": {"token": "secret://api/token", "key": "`+enc+`"}
+		}`)))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := c.Get("db", "password").String(""); v != "s3cr3t" {
+		t.Fatalf("expected keyring secret to be decrypted, got %q", v)
+	}
+	if v := c.Get("api", "token").String(""); v != "token-1" {
+		t.Fatalf("expected env secret, got %q", v)
+	}
+	if v := c.Get("api", "key").String(""); v != "s3cr3t" {
+		t.Fatalf("expected inline value to be decrypted, got %q", v)
+	}
+
+	// rotate the env secret
+	os.Setenv("API_TOKEN", "token-2")
+
+	rotated, err := p.Refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rotated {
+		t.Fatal("expected secret to be rotated")
+	}
+	if err := c.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Get("api", "token").String(""); v != "token-2" {
+		t.Fatalf("expected rotated secret, got %q", v)
+	}
+
+	// unresolvable references fail to load
+	if err := c.Load(memory.NewSource(memory.WithJSON([]byte(`{"missing": "secret://missing"}`)))); err == nil {
+		t.Fatal("expected missing secret to fail")
+	}
+}
+
+func TestDumpResolved(t *testing.T) {
+	os.Setenv("DB_DSN", "postgres://user:pass@db")
+	defer os.Unsetenv("DB_DSN")
+
+	p := secrets.NewPreprocessor(secrets.WithResolver(env.NewResolver()))
+
+	c, err := config.NewConfig(
+		config.WithReader(json.NewReader(reader.WithPreprocessor(p.Process))),
+		config.WithSource(memory.NewSource(memory.WithJSON([]byte(`{
+			"db": {"dsn": "secret://db/dsn", "host": "db.local"}
+		}`)))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dsn isn't a secret key so only the resolution marks it
+	entries := config.Dump(c, "db")
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Path != "db.dsn" || entries[0].Value != config.Redacted {
+		t.Fatalf("expected resolved secret to be redacted, got %+v", entries[0])
+	}
+	if entries[0].Provenance == nil || !entries[0].Provenance.Secret {
+		t.Fatalf("expected provenance to be marked secret, got %+v", entries[0].Provenance)
+	}
+	if entries[1].Path != "db.host" || entries[1].Value != "db.local" {
+		t.Fatalf("unexpected entry %+v", entries[1])
+	}
+}
+
+func TestInvalidChange(t *testing.T) {
+	p := secrets.NewPreprocessor(secrets.WithResolver(env.NewResolver()))
+
+	a := memory.NewSource(memory.WithJSON([]byte(`{"a": "1"}`)))
+	b := memory.NewSource(memory.WithJSON([]byte(`{"b": "1"}`)))
+
+	c, err := config.NewConfig(
+		config.WithReader(json.NewReader(reader.WithPreprocessor(p.Process))),
+		config.WithSource(a),
+		config.WithSource(b),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// let the sources be watched
+	time.Sleep(100 * time.Millisecond)
+
+	// the change of a can't be resolved so it's not applied
+	a.Write(&source.ChangeSet{Data: []byte(`{"a": "secret://missing"}`), Format: "json"})
+	time.Sleep(100 * time.Millisecond)
+
+	// and doesn't stop other changes from applying
+	b.Write(&source.ChangeSet{Data: []byte(`{"b": "2"}`), Format: "json"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Get("b").String("") != "2" {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the change of b to apply, got %q", c.Get("b").String(""))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v := c.Get("a").String(""); v != "1" {
+		t.Fatalf("expected a to be kept, got %q", v)
+	}
+}
@@ -0,0 +1,40 @@
+package env
+
+import (
+	"os"
+	"strings"
+
+	"github.com/asim/go-micro/v3/config/source"
+)
+
+// Locate returns the environment variable a value at path was read from
+func (e *env) Locate(ch *source.ChangeSet, path ...string) *source.Provenance {
+	p := &source.Provenance{Source: e.String()}
+	key := strings.Join(path, "_")
+
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+
+		if match, ok := matchPrefix(e.strippedPrefixes, env); ok {
+			if strings.EqualFold(strings.TrimPrefix(name, match), key) {
+				p.Env = name
+				return p
+			}
+			continue
+		}
+
+		if (len(e.prefixes) == 0 && len(e.strippedPrefixes) == 0) || hasPrefix(e.prefixes, env) {
+			if strings.EqualFold(name, key) {
+				p.Env = name
+				return p
+			}
+		}
+	}
+
+	return p
+}
+
+func hasPrefix(pre []string, s string) bool {
+	_, ok := matchPrefix(pre, s)
+	return ok
+}
@@ -0,0 +1,50 @@
+package file
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/asim/go-micro/v3/config/source"
+)
+
+// Locate returns the file and line a value at path was defined on
+func (f *file) Locate(ch *source.ChangeSet, path ...string) *source.Provenance {
+	return &source.Provenance{
+		Source: f.String(),
+		File:   f.path,
+		Line:   findLine(ch.Data, path),
+	}
+}
+
+// findLine does a best effort search for the line of the last key in
+// path by looking for each key in turn after the line of the previous
+// one. It works for json, yaml and toml style key value pairs.
+func findLine(data []byte, path []string) int {
+	if len(path) == 0 {
+		return 0
+	}
+
+	lines := strings.Split(string(data), "\n")
+	line := -1
+
+	for _, key := range path {
+		re, err := regexp.Compile(`(^|[\s{,\["'.])` + regexp.QuoteMeta(key) + `["']?\s*[:=\]]`)
+		if err != nil {
+			return 0
+		}
+
+		found := false
+		for i := line + 1; i < len(lines); i++ {
+			if re.MatchString(lines[i]) {
+				line = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+
+	return line + 1
+}
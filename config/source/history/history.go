@@ -0,0 +1,347 @@
+// Package history keeps a versioned history of config change sets in a
+// store so versions can be pinned, rolled back or rolled out to a
+// percentage of instances as a canary before going live everywhere.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/store"
+)
+
+var (
+	// ErrNotFound is returned when a version doesn't exist
+	ErrNotFound = errors.New("version not found")
+	// ErrNoVersions is returned when no versions have been recorded
+	ErrNoVersions = errors.New("no versions recorded")
+
+	// MetadataKey is the node metadata key the active version is advertised under
+	MetadataKey = "config_version"
+)
+
+// Version is a recorded change set
+type Version struct {
+	// Id of the version which is the checksum of its data
+	Id       string `json:"id"`
+	Checksum string `json:"checksum"`
+	// Timestamp of the last time the data was recorded
+	Timestamp time.Time `json:"timestamp"`
+	Format    string    `json:"format"`
+	Source    string    `json:"source"`
+	Data      []byte    `json:"data"`
+}
+
+// ChangeSet returns the version as a change set
+func (v *Version) ChangeSet() *source.ChangeSet {
+	return &source.ChangeSet{
+		Data:      v.Data,
+		Checksum:  v.Checksum,
+		Format:    v.Format,
+		Source:    v.Source,
+		Timestamp: v.Timestamp,
+	}
+}
+
+// Rollout decides which version each instance uses
+type Rollout struct {
+	// Active is the version used by every instance,
+	// when empty the latest version is used
+	Active string `json:"active,omitempty"`
+	// Canary is the version used by Percent of the instances
+	Canary string `json:"canary,omitempty"`
+	// Percent of instances using the canary
+	Percent int `json:"percent,omitempty"`
+	// Updated is when the rollout last changed
+	Updated time.Time `json:"updated"`
+}
+
+// History manages the versions of a named config
+type History struct {
+	store store.Store
+	name  string
+}
+
+// New returns the history of the named config kept in the store
+func New(s store.Store, name string) *History {
+	return &History{store: s, name: name}
+}
+
+func (h *History) key(parts ...string) string {
+	return strings.Join(append([]string{"config", "history", h.name}, parts...), "/")
+}
+
+// Record stores the change set as a new version. A change set which was
+// recorded before keeps its version but becomes the latest again unless it
+// already is, so reverting to earlier data makes that data the latest.
+func (h *History) Record(cs *source.ChangeSet) (*Version, error) {
+	checksum := cs.Checksum
+	if len(checksum) == 0 {
+		checksum = cs.Sum()
+	}
+
+	ts := cs.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	if v, err := h.Version(checksum); err == nil {
+		return h.touch(v, ts)
+	} else if err != ErrNotFound {
+		return nil, err
+	}
+
+	v := &Version{
+		Id:        checksum,
+		Checksum:  checksum,
+		Timestamp: ts,
+		Format:    cs.Format,
+		Source:    cs.Source,
+		Data:      cs.Data,
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.store.Write(&store.Record{Key: h.key("versions", v.Id), Value: b}); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// touch moves a recorded version after the latest version
+func (h *History) touch(v *Version, ts time.Time) (*Version, error) {
+	versions, err := h.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	latest := versions[len(versions)-1]
+	if latest.Id == v.Id {
+		return v, nil
+	}
+	if !ts.After(latest.Timestamp) {
+		ts = latest.Timestamp.Add(time.Nanosecond)
+	}
+	v.Timestamp = ts
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.store.Write(&store.Record{Key: h.key("versions", v.Id), Value: b}); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Version returns a recorded version by id
+func (h *History) Version(id string) (*Version, error) {
+	recs, err := h.store.Read(h.key("versions", id))
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(Version)
+	if err := json.Unmarshal(recs[0].Value, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Versions returns all the recorded versions oldest first
+func (h *History) Versions() ([]*Version, error) {
+	keys, err := h.store.List(store.ListPrefix(h.key("versions") + "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*Version, 0, len(keys))
+	for _, k := range keys {
+		v, err := h.Version(strings.TrimPrefix(k, h.key("versions")+"/"))
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Timestamp.Before(versions[j].Timestamp)
+	})
+
+	return versions, nil
+}
+
+// Rollout returns the current rollout
+func (h *History) Rollout() (*Rollout, error) {
+	recs, err := h.store.Read(h.key("rollout"))
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return &Rollout{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(Rollout)
+	if err := json.Unmarshal(recs[0].Value, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (h *History) update(fn func(r *Rollout) error) error {
+	r, err := h.Rollout()
+	if err != nil {
+		return err
+	}
+	if err := fn(r); err != nil {
+		return err
+	}
+	r.Updated = time.Now()
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return h.store.Write(&store.Record{Key: h.key("rollout"), Value: b})
+}
+
+// Pin makes the version active on every instance
+func (h *History) Pin(id string) error {
+	if _, err := h.Version(id); err != nil {
+		return err
+	}
+	return h.update(func(r *Rollout) error {
+		r.Active = id
+		if r.Canary == id {
+			r.Canary = ""
+			r.Percent = 0
+		}
+		return nil
+	})
+}
+
+// Unpin makes the latest version active on every instance
+func (h *History) Unpin() error {
+	return h.update(func(r *Rollout) error {
+		r.Active = ""
+		return nil
+	})
+}
+
+// Rollback pins the version recorded before the active one and
+// abandons any canary. It returns the version rolled back to.
+func (h *History) Rollback() (*Version, error) {
+	versions, err := h.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := h.Rollout()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := h.stable(r, versions)
+	if err != nil {
+		return nil, err
+	}
+
+	var prev *Version
+	for _, v := range versions {
+		if v.Id == active.Id {
+			break
+		}
+		prev = v
+	}
+	if prev == nil {
+		return nil, fmt.Errorf("no version before %s to roll back to", active.Id)
+	}
+
+	if err := h.update(func(r *Rollout) error {
+		r.Active = prev.Id
+		r.Canary = ""
+		r.Percent = 0
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return prev, nil
+}
+
+// Canary rolls the version out to a percentage of instances
+func (h *History) Canary(id string, percent int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("invalid percent %d", percent)
+	}
+	if _, err := h.Version(id); err != nil {
+		return err
+	}
+	return h.update(func(r *Rollout) error {
+		r.Canary = id
+		r.Percent = percent
+		return nil
+	})
+}
+
+// Promote makes the canary active on every instance
+func (h *History) Promote() error {
+	return h.update(func(r *Rollout) error {
+		if len(r.Canary) == 0 {
+			return errors.New("no canary to promote")
+		}
+		r.Active = r.Canary
+		r.Canary = ""
+		r.Percent = 0
+		return nil
+	})
+}
+
+// Active returns the version the instance should use
+func (h *History) Active(instance string) (*Version, error) {
+	r, err := h.Rollout()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.Canary) > 0 && bucket(instance) < r.Percent {
+		return h.Version(r.Canary)
+	}
+
+	versions, err := h.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	return h.stable(r, versions)
+}
+
+// stable returns the version used by instances outside the canary
+func (h *History) stable(r *Rollout, versions []*Version) (*Version, error) {
+	if len(r.Active) > 0 {
+		return h.Version(r.Active)
+	}
+	if len(versions) == 0 {
+		return nil, ErrNoVersions
+	}
+	return versions[len(versions)-1], nil
+}
+
+// bucket places the instance in one of 100 stable buckets
+func bucket(instance string) int {
+	return int(crc32.ChecksumIEEE([]byte(instance)) % 100)
+}
@@ -0,0 +1,202 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/memory"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+)
+
+type updater interface {
+	Update(*source.ChangeSet)
+}
+
+func TestHistory(t *testing.T) {
+	mem := memory.NewSource(memory.WithJSON([]byte(`{"version": 1}`)))
+	src := NewSource(mem,
+		WithStore(store.NewMemoryStore()),
+		WithInstance("test"),
+		WithInterval(time.Millisecond*10),
+		Staged(),
+	)
+	hist := src.(*history).History()
+
+	cs, err := src.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cs.Data) != `{"version": 1}` {
+		t.Fatalf("unexpected data %s", cs.Data)
+	}
+	v1 := cs.Checksum
+
+	w, err := src.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// staged changes are recorded but not activated
+	mem.(updater).Update(&source.ChangeSet{Data: []byte(`{"version": 2}`)})
+
+	var versions []*Version
+	for i := 0; i < 100; i++ {
+		versions, err = hist.Versions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(versions) == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	v2 := versions[1].Id
+
+	if v, err := hist.Active("test"); err != nil || v.Id != v1 {
+		t.Fatalf("expected v1 to be active, got %v %v", v, err)
+	}
+
+	// canary to every instance
+	if err := hist.Canary(v2, 100); err != nil {
+		t.Fatal(err)
+	}
+	cs, err = w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Checksum != v2 {
+		t.Fatalf("expected canary %s, got %s", v2, cs.Checksum)
+	}
+
+	// canary to no instances
+	if err := hist.Canary(v2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := hist.Active("test"); v.Id != v1 {
+		t.Fatalf("expected v1 outside the canary, got %s", v.Id)
+	}
+
+	if err := hist.Promote(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := hist.Active("test"); v.Id != v2 {
+		t.Fatalf("expected promoted v2, got %s", v.Id)
+	}
+
+	v, err := hist.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Id != v1 {
+		t.Fatalf("expected rollback to v1, got %s", v.Id)
+	}
+	if _, err := hist.Rollback(); err == nil {
+		t.Fatal("expected no version before v1")
+	}
+}
+
+func TestRevert(t *testing.T) {
+	hist := New(store.NewMemoryStore(), "test")
+
+	a := &source.ChangeSet{Data: []byte(`{"version": "a"}`)}
+	b := &source.ChangeSet{Data: []byte(`{"version": "b"}`)}
+
+	va, err := hist.Record(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hist.Record(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// reverting to a makes it the latest again
+	v, err := hist.Record(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Id != va.Id {
+		t.Fatalf("expected version %s, got %s", va.Id, v.Id)
+	}
+	if v, _ := hist.Active("test"); v.Id != va.Id {
+		t.Fatalf("expected reverted version to be active, got %s", v.Data)
+	}
+
+	// and rolling back returns to b
+	v, err = hist.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v.Data) != `{"version": "b"}` {
+		t.Fatalf("expected rollback to b, got %s", v.Data)
+	}
+}
+
+func TestAdvertise(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	br := broker.NewBroker(broker.Registry(reg))
+	srv := server.NewServer(
+		server.Name("test"),
+		server.Registry(reg),
+		server.Broker(br),
+		server.RegisterInterval(time.Hour),
+	)
+
+	ch := make(chan string, 1)
+	err := srv.Subscribe(srv.NewSubscriber("events", func(ctx context.Context, msg *map[string]string) error {
+		ch <- (*msg)["id"]
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	mem := memory.NewSource(memory.WithJSON([]byte(`{"version": 1}`)))
+	src := NewSource(mem,
+		WithStore(store.NewMemoryStore()),
+		WithInstance("test"),
+		WithServer(srv),
+	)
+	cs, err := src.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the version is advertised by the registered node
+	services, err := reg.GetService("test")
+	if err != nil || len(services) == 0 || len(services[0].Nodes) == 0 {
+		t.Fatalf("expected the service to be registered, got %v %v", services, err)
+	}
+	if v := services[0].Nodes[0].Metadata[MetadataKey]; v != cs.Checksum {
+		t.Fatalf("expected version %s to be advertised, got %q", cs.Checksum, v)
+	}
+
+	// and the subscriber still receives events
+	err = br.Publish("events", &broker.Message{
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   []byte(`{"id": "1"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-ch:
+		if id != "1" {
+			t.Fatalf("unexpected event %s", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the subscriber to receive the event")
+	}
+}
@@ -0,0 +1,58 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+)
+
+type storeKey struct{}
+type nameKey struct{}
+type instanceKey struct{}
+type stagedKey struct{}
+type intervalKey struct{}
+type serverKey struct{}
+
+func setOption(k, v interface{}) source.Option {
+	return func(o *source.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
+
+// WithStore sets the store the history is kept in
+func WithStore(s store.Store) source.Option {
+	return setOption(storeKey{}, s)
+}
+
+// WithName sets the name the history is kept under
+func WithName(n string) source.Option {
+	return setOption(nameKey{}, n)
+}
+
+// WithInstance sets the id of this instance used to select canaries
+func WithInstance(id string) source.Option {
+	return setOption(instanceKey{}, id)
+}
+
+// Staged stops new versions going live until they are activated
+// with Pin, Canary or Promote. The first version is activated.
+func Staged() source.Option {
+	return setOption(stagedKey{}, true)
+}
+
+// WithInterval sets how often the rollout is checked for changes
+func WithInterval(d time.Duration) source.Option {
+	return setOption(intervalKey{}, d)
+}
+
+// WithServer advertises the active version in the node metadata of the server,
+// the server must implement server.MetadataSetter
+func WithServer(s server.Server) source.Option {
+	return setOption(serverKey{}, s)
+}
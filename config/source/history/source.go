@@ -0,0 +1,286 @@
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/google/uuid"
+)
+
+var (
+	// DefaultName is the name the history is kept under
+	DefaultName = "config"
+	// DefaultInterval is how often the rollout is checked for changes
+	DefaultInterval = time.Second * 10
+)
+
+type history struct {
+	src      source.Source
+	opts     source.Options
+	hist     *History
+	instance string
+	staged   bool
+	interval time.Duration
+	server   server.Server
+
+	sync.Mutex
+	active string
+}
+
+type watcher struct {
+	h       *history
+	sw      source.Watcher
+	exit    chan bool
+	updates chan *source.ChangeSet
+}
+
+// Read records the change set of the underlying source and returns the
+// version active for this instance. If the source can't be read the
+// active version from the history is used instead.
+func (h *history) Read() (*source.ChangeSet, error) {
+	cs, err := h.src.Read()
+	if err != nil {
+		v, aerr := h.activate()
+		if aerr != nil {
+			return nil, err
+		}
+		return v.ChangeSet(), nil
+	}
+
+	if err := h.record(cs); err != nil {
+		return nil, err
+	}
+
+	v, err := h.activate()
+	if err != nil {
+		return nil, err
+	}
+
+	return v.ChangeSet(), nil
+}
+
+func (h *history) Write(cs *source.ChangeSet) error {
+	return h.src.Write(cs)
+}
+
+func (h *history) Watch() (source.Watcher, error) {
+	w := &watcher{
+		h:       h,
+		exit:    make(chan bool),
+		updates: make(chan *source.ChangeSet, 1),
+	}
+
+	// not all sources can be watched, the rollout is still polled
+	if sw, err := h.src.Watch(); err == nil {
+		w.sw = sw
+		go w.watch()
+	}
+
+	go w.poll()
+
+	return w, nil
+}
+
+// Locate defers to the underlying source
+func (h *history) Locate(ch *source.ChangeSet, path ...string) *source.Provenance {
+	if l, ok := h.src.(source.Locator); ok {
+		return l.Locate(ch, path...)
+	}
+	return &source.Provenance{Source: h.src.String()}
+}
+
+func (h *history) String() string {
+	return "history"
+}
+
+// History returns the history managed by the source
+func (h *history) History() *History {
+	return h.hist
+}
+
+func (h *history) record(cs *source.ChangeSet) error {
+	v, err := h.hist.Record(cs)
+	if err != nil {
+		return err
+	}
+
+	if !h.staged {
+		return nil
+	}
+
+	// a staged history activates only the first version
+	r, err := h.hist.Rollout()
+	if err != nil {
+		return err
+	}
+	if len(r.Active) == 0 {
+		return h.hist.Pin(v.Id)
+	}
+
+	return nil
+}
+
+// activate returns the active version and advertises it if it changed
+func (h *history) activate() (*Version, error) {
+	v, err := h.hist.Active(h.instance)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	changed := h.active != v.Id
+	h.active = v.Id
+	h.Unlock()
+
+	if changed && h.server != nil {
+		h.advertise(v.Id)
+	}
+
+	return v, nil
+}
+
+// advertise sets the active version in the node metadata of the server.
+// Init isn't used since it resets the router of a running server.
+func (h *history) advertise(id string) {
+	ms, ok := h.server.(server.MetadataSetter)
+	if !ok {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Config history can't advertise version %s, server %s can't set metadata", id, h.server.String())
+		}
+		return
+	}
+	if err := ms.SetMetadata(MetadataKey, id); err != nil {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Config history failed to advertise version %s: %v", id, err)
+		}
+	}
+}
+
+func (w *watcher) send(v *Version) {
+	select {
+	case w.updates <- v.ChangeSet():
+	case <-w.exit:
+	}
+}
+
+// watch records changes from the underlying source
+func (w *watcher) watch() {
+	for {
+		cs, err := w.sw.Next()
+		if err != nil {
+			return
+		}
+
+		if err := w.h.record(cs); err != nil {
+			continue
+		}
+
+		w.h.Lock()
+		last := w.h.active
+		w.h.Unlock()
+
+		v, err := w.h.activate()
+		if err != nil || v.Id == last {
+			continue
+		}
+
+		w.send(v)
+	}
+}
+
+// poll checks the rollout for a change in the active version
+func (w *watcher) poll() {
+	t := time.NewTicker(w.h.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.exit:
+			return
+		case <-t.C:
+			w.h.Lock()
+			last := w.h.active
+			w.h.Unlock()
+
+			v, err := w.h.activate()
+			if err != nil || v.Id == last {
+				continue
+			}
+
+			w.send(v)
+		}
+	}
+}
+
+func (w *watcher) Next() (*source.ChangeSet, error) {
+	select {
+	case cs := <-w.updates:
+		return cs, nil
+	case <-w.exit:
+		return nil, source.ErrWatcherStopped
+	}
+}
+
+func (w *watcher) Stop() error {
+	select {
+	case <-w.exit:
+		return nil
+	default:
+		close(w.exit)
+	}
+	if w.sw != nil {
+		return w.sw.Stop()
+	}
+	return nil
+}
+
+// NewSource wraps a source recording every change set it returns in a
+// versioned history. The version served is decided by the rollout.
+func NewSource(src source.Source, opts ...source.Option) source.Source {
+	options := source.NewOptions(opts...)
+
+	st := store.DefaultStore
+	if s, ok := options.Context.Value(storeKey{}).(store.Store); ok {
+		st = s
+	}
+
+	name := DefaultName
+	if n, ok := options.Context.Value(nameKey{}).(string); ok {
+		name = n
+	}
+
+	interval := DefaultInterval
+	if d, ok := options.Context.Value(intervalKey{}).(time.Duration); ok {
+		interval = d
+	}
+
+	h := &history{
+		src:      src,
+		opts:     options,
+		hist:     New(st, name),
+		interval: interval,
+	}
+
+	if s, ok := options.Context.Value(serverKey{}).(server.Server); ok {
+		h.server = s
+		h.instance = s.Options().Id
+	}
+
+	if id, ok := options.Context.Value(instanceKey{}).(string); ok {
+		h.instance = id
+	}
+
+	if len(h.instance) == 0 {
+		h.instance = uuid.New().String()
+	}
+
+	if s, ok := options.Context.Value(stagedKey{}).(bool); ok {
+		h.staged = s
+	}
+
+	return h
+}
@@ -3,6 +3,7 @@ package source
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -26,6 +27,41 @@ type ChangeSet struct {
 	Format    string
 	Source    string
 	Timestamp time.Time
+	// Provenance of the values keyed by their dotted path,
+	// set by the loader on the merged ChangeSet
+	Provenance map[string]*Provenance `json:"-"`
+}
+
+// Provenance describes where a config value was defined
+type Provenance struct {
+	// Source is the name of the source e.g file, env
+	Source string `json:"source"`
+	// File is the path of the file the value was read from
+	File string `json:"file,omitempty"`
+	// Line is the line of the file the value is defined on
+	Line int `json:"line,omitempty"`
+	// Env is the environment variable the value was read from
+	Env string `json:"env,omitempty"`
+	// Secret is set on values replaced by a preprocessor e.g resolved secrets
+	Secret bool `json:"secret,omitempty"`
+}
+
+func (p *Provenance) String() string {
+	switch {
+	case len(p.File) > 0 && p.Line > 0:
+		return fmt.Sprintf("%s %s:%d", p.Source, p.File, p.Line)
+	case len(p.File) > 0:
+		return fmt.Sprintf("%s %s", p.Source, p.File)
+	case len(p.Env) > 0:
+		return fmt.Sprintf("%s %s", p.Source, p.Env)
+	}
+	return p.Source
+}
+
+// Locator is implemented by sources which can locate where
+// a value was defined within a ChangeSet they returned
+type Locator interface {
+	Locate(ch *ChangeSet, path ...string) *Provenance
 }
 
 // Watcher watches a source for changes
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/asim/go-micro/v3/config/reader"
+	"github.com/asim/go-micro/v3/config/source"
 )
 
 type value struct{}
@@ -47,3 +48,7 @@ func (v *value) Scan(val interface{}) error {
 func (v *value) Bytes() []byte {
 	return nil
 }
+
+func (v *value) Provenance() *source.Provenance {
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !fault
+// +build !fault
+
+package fault
+
+// faults are only injected in builds with the fault tag
+const enabled = false
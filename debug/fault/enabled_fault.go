@@ -0,0 +1,6 @@
+//go:build fault
+// +build fault
+
+package fault
+
+const enabled = true
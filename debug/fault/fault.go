@@ -0,0 +1,177 @@
+// Package fault injects failures into requests and publishes to test resilience.
+//
+// Faults are only injected when built with the fault tag e.g
+//
+//	go build -tags fault
+//
+// so the wrappers can be left in place in production builds. The faults
+// are set at runtime through the Faults endpoint of the debug handler.
+package fault
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/google/uuid"
+)
+
+var (
+	// Enabled is true in builds with the fault tag. Faults
+	// can be set either way but are only injected if enabled.
+	Enabled = enabled
+
+	// DefaultInjector is used by the debug handler
+	DefaultInjector = NewInjector()
+)
+
+// Fault targets requests by service, endpoint and metadata or publishes
+// by topic. Service, Endpoint and Topic are path.Match patterns, blank
+// matches any service or endpoint. A fault with a Topic only applies to
+// publishes. Percent limits the fault to a share of the matches; zero
+// applies it to all of them.
+type Fault struct {
+	Id       string
+	Service  string
+	Endpoint string
+	Metadata map[string]string
+	Topic    string
+	Percent  int
+	// Delay is the latency added
+	Delay time.Duration
+	// Error is the code of the error returned e.g 503
+	Error int32
+	// Drop the response as if it never arrived
+	Drop bool
+}
+
+// Injector holds the faults to inject
+type Injector struct {
+	sync.RWMutex
+	faults map[string]*Fault
+}
+
+// NewInjector returns an injector without any faults
+func NewInjector() *Injector {
+	return &Injector{
+		faults: make(map[string]*Fault),
+	}
+}
+
+// Set adds the fault or replaces the one with the same id.
+// An id is generated if it's blank.
+func (i *Injector) Set(f *Fault) error {
+	for _, p := range []string{f.Service, f.Endpoint, f.Topic} {
+		if _, err := path.Match(p, ""); err != nil {
+			return err
+		}
+	}
+	if f.Delay < 0 || f.Error < 0 {
+		return errors.New("fault: delay and error must not be negative")
+	}
+	if f.Delay == 0 && f.Error == 0 && !f.Drop {
+		return errors.New("fault: no delay, error or drop set")
+	}
+
+	if len(f.Id) == 0 {
+		f.Id = uuid.New().String()
+	}
+
+	i.Lock()
+	i.faults[f.Id] = f
+	i.Unlock()
+
+	return nil
+}
+
+// Delete removes the fault
+func (i *Injector) Delete(id string) {
+	i.Lock()
+	delete(i.faults, id)
+	i.Unlock()
+}
+
+// Clear removes all the faults
+func (i *Injector) Clear() {
+	i.Lock()
+	i.faults = make(map[string]*Fault)
+	i.Unlock()
+}
+
+// List returns the faults ordered by id
+func (i *Injector) List() []*Fault {
+	i.RLock()
+	defer i.RUnlock()
+
+	faults := make([]*Fault, 0, len(i.faults))
+	for _, f := range i.faults {
+		faults = append(faults, f)
+	}
+	sort.Slice(faults, func(a, b int) bool { return faults[a].Id < faults[b].Id })
+	return faults
+}
+
+// request returns the fault to inject into a request
+func (i *Injector) request(ctx context.Context, service, endpoint string) (*Fault, bool) {
+	if !Enabled {
+		return nil, false
+	}
+
+	for _, f := range i.List() {
+		if len(f.Topic) > 0 || !match(f.Service, service) || !match(f.Endpoint, endpoint) {
+			continue
+		}
+		if !matchMetadata(ctx, f.Metadata) || !sample(f.Percent) {
+			continue
+		}
+		return f, true
+	}
+	return nil, false
+}
+
+// publish returns the fault to inject into a publish
+func (i *Injector) publish(ctx context.Context, topic string) (*Fault, bool) {
+	if !Enabled {
+		return nil, false
+	}
+
+	for _, f := range i.List() {
+		if len(f.Topic) == 0 || !match(f.Topic, topic) {
+			continue
+		}
+		if !matchMetadata(ctx, f.Metadata) || !sample(f.Percent) {
+			continue
+		}
+		return f, true
+	}
+	return nil, false
+}
+
+func match(pattern, name string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+func matchMetadata(ctx context.Context, md map[string]string) bool {
+	for k, v := range md {
+		if val, ok := metadata.Get(ctx, k); !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sample(percent int) bool {
+	if percent <= 0 || percent >= 100 {
+		return true
+	}
+	return rand.Intn(100) < percent
+}
@@ -0,0 +1,169 @@
+package fault
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/server"
+)
+
+// testClient counts the requests and publishes it receives
+type testClient struct {
+	client.Client
+	calls     int
+	published int
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	c.calls++
+	return nil
+}
+
+func (c *testClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	c.published++
+	return nil
+}
+
+type testRequest struct {
+	server.Request
+	service  string
+	endpoint string
+}
+
+func (r *testRequest) Service() string  { return r.service }
+func (r *testRequest) Endpoint() string { return r.endpoint }
+
+// enable injects faults in builds without the fault tag
+func enable() func() {
+	Enabled = true
+	return func() { Enabled = enabled }
+}
+
+func TestClientWrapper(t *testing.T) {
+	defer enable()()
+
+	i := NewInjector()
+	tc := &testClient{}
+	c := i.NewClientWrapper()(tc)
+
+	faults := []*Fault{
+		{Id: "abort", Service: "greeter", Endpoint: "Greeter.Abort", Error: 503},
+		{Id: "beta", Service: "greeter", Metadata: map[string]string{"X-Beta": "true"}, Error: 500},
+		{Id: "delay", Service: "greeter", Endpoint: "Greeter.Delay", Delay: 50 * time.Millisecond},
+		{Id: "drop", Service: "greeter", Endpoint: "Greeter.Drop", Drop: true},
+		{Id: "events", Topic: "events.*", Error: 500},
+		{Id: "lost", Topic: "lost", Drop: true},
+	}
+	for _, f := range faults {
+		if err := i.Set(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	call := func(ctx context.Context, endpoint string) error {
+		return c.Call(ctx, client.NewRequest("greeter", endpoint, nil), nil)
+	}
+
+	if err := call(ctx, "Greeter.Abort"); errors.FromError(err).Code != 503 || tc.calls != 0 {
+		t.Fatalf("expected an injected 503 without a call, got %v", err)
+	}
+
+	beta := metadata.NewContext(ctx, metadata.Metadata{"X-Beta": "true"})
+	if err := call(beta, "Greeter.Hello"); errors.FromError(err).Code != 500 {
+		t.Fatalf("expected an injected 500, got %v", err)
+	}
+
+	start := time.Now()
+	if err := call(ctx, "Greeter.Delay"); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("expected the request to be delayed, took %v", d)
+	}
+
+	if err := call(ctx, "Greeter.Drop"); errors.FromError(err).Code != 408 || tc.calls != 2 {
+		t.Fatalf("expected the response of a made call to be dropped, got %v", err)
+	}
+
+	if err := call(ctx, "Greeter.Hello"); err != nil || tc.calls != 3 {
+		t.Fatalf("expected the request to pass, got %v", err)
+	}
+
+	// publishes
+	if err := c.Publish(ctx, client.NewMessage("events.created", nil)); errors.FromError(err).Code != 500 {
+		t.Fatalf("expected an injected publish failure, got %v", err)
+	}
+	if err := c.Publish(ctx, client.NewMessage("lost", nil)); err != nil || tc.published != 0 {
+		t.Fatalf("expected the message to be silently dropped, got %v", err)
+	}
+	if err := c.Publish(ctx, client.NewMessage("other", nil)); err != nil || tc.published != 1 {
+		t.Fatalf("expected the message to be published, got %v", err)
+	}
+
+	// removing faults
+	i.Delete("abort")
+	if err := call(ctx, "Greeter.Abort"); err != nil {
+		t.Fatalf("expected the fault to be removed, got %v", err)
+	}
+	i.Clear()
+	if len(i.List()) != 0 {
+		t.Fatal("expected no faults")
+	}
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	defer enable()()
+
+	i := NewInjector()
+	if err := i.Set(&Fault{Service: "greeter", Endpoint: "Greeter.Drop", Drop: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var handled int
+	h := i.NewHandlerWrapper()(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		handled++
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := h(ctx, &testRequest{service: "greeter", endpoint: "Greeter.Drop"}, nil)
+	if errors.FromError(err).Code != 408 || handled != 1 {
+		t.Fatalf("expected the handled response to be dropped, got %v", err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("expected the response to be held until the deadline, took %v", d)
+	}
+
+	if err := h(context.Background(), &testRequest{service: "greeter", endpoint: "Greeter.Hello"}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	Enabled = false
+	defer func() { Enabled = enabled }()
+
+	i := NewInjector()
+	if err := i.Set(&Fault{Error: 500}); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &testClient{}
+	c := i.NewClientWrapper()(tc)
+	if err := c.Call(context.Background(), client.NewRequest("greeter", "Greeter.Hello", nil), nil); err != nil {
+		t.Fatalf("expected faults to be ignored when disabled, got %v", err)
+	}
+
+	// faults need an effect
+	if err := i.Set(&Fault{Service: "greeter"}); err == nil {
+		t.Fatal("expected a fault without an effect to be rejected")
+	}
+}
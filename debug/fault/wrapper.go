@@ -0,0 +1,128 @@
+package fault
+
+import (
+	"context"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/server"
+)
+
+type faultWrapper struct {
+	client.Client
+	i *Injector
+}
+
+func (w *faultWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	f, ok := w.i.request(ctx, req.Service(), req.Endpoint())
+	if !ok {
+		return w.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	if err := inject(ctx, f, req.Service()); err != nil {
+		return err
+	}
+
+	err := w.Client.Call(ctx, req, rsp, opts...)
+	if err == nil && f.Drop {
+		return errors.Timeout(req.Service(), "fault injected: response dropped")
+	}
+	return err
+}
+
+func (w *faultWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	f, ok := w.i.request(ctx, req.Service(), req.Endpoint())
+	if !ok {
+		return w.Client.Stream(ctx, req, opts...)
+	}
+
+	if err := inject(ctx, f, req.Service()); err != nil {
+		return nil, err
+	}
+	if f.Drop {
+		return nil, errors.Timeout(req.Service(), "fault injected: stream dropped")
+	}
+
+	return w.Client.Stream(ctx, req, opts...)
+}
+
+func (w *faultWrapper) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	f, ok := w.i.publish(ctx, msg.Topic())
+	if !ok {
+		return w.Client.Publish(ctx, msg, opts...)
+	}
+
+	if err := inject(ctx, f, msg.Topic()); err != nil {
+		return err
+	}
+	// the message is lost without the publisher knowing
+	if f.Drop {
+		return nil
+	}
+
+	return w.Client.Publish(ctx, msg, opts...)
+}
+
+// inject delays and then returns the error of the fault
+func inject(ctx context.Context, f *Fault, id string) error {
+	if f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Timeout(id, "%v", ctx.Err())
+		case <-t.C:
+		}
+	}
+
+	if f.Error > 0 {
+		return errors.New(id, "fault injected", f.Error)
+	}
+
+	return nil
+}
+
+// NewClientWrapper returns a client wrapper which injects the faults
+func (i *Injector) NewClientWrapper() client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &faultWrapper{c, i}
+	}
+}
+
+// NewHandlerWrapper returns a handler wrapper which injects the faults
+func (i *Injector) NewHandlerWrapper() server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			f, ok := i.request(ctx, req.Service(), req.Endpoint())
+			if !ok {
+				return h(ctx, req, rsp)
+			}
+
+			if err := inject(ctx, f, req.Service()); err != nil {
+				return err
+			}
+
+			err := h(ctx, req, rsp)
+			if err != nil || !f.Drop {
+				return err
+			}
+
+			// hold the response until the caller gives up
+			if _, ok := ctx.Deadline(); ok {
+				<-ctx.Done()
+			}
+			return errors.Timeout(req.Service(), "fault injected: response dropped")
+		}
+	}
+}
+
+// NewClientWrapper returns a client wrapper which injects the faults of the DefaultInjector
+func NewClientWrapper() client.Wrapper {
+	return DefaultInjector.NewClientWrapper()
+}
+
+// NewHandlerWrapper returns a handler wrapper which injects the faults of the DefaultInjector
+func NewHandlerWrapper() server.HandlerWrapper {
+	return DefaultInjector.NewHandlerWrapper()
+}
@@ -3,23 +3,51 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/debug/fault"
 	"github.com/asim/go-micro/v3/debug/log"
 	proto "github.com/asim/go-micro/v3/debug/proto"
 	"github.com/asim/go-micro/v3/debug/stats"
 	"github.com/asim/go-micro/v3/debug/trace"
+	"github.com/asim/go-micro/v3/errors"
 	"github.com/asim/go-micro/v3/server"
 )
 
+// Option sets an option on the Debug Handler
+type Option func(d *Debug)
+
+// Config sets the config served by the handler
+func Config(c config.Config) Option {
+	return func(d *Debug) {
+		d.config = c
+	}
+}
+
+// Faults sets the injector managed by the handler
+func Faults(i *fault.Injector) Option {
+	return func(d *Debug) {
+		d.faults = i
+	}
+}
+
 // NewHandler returns an instance of the Debug Handler
-func NewHandler(c client.Client) *Debug {
-	return &Debug{
-		log:   log.DefaultLog,
-		stats: stats.DefaultStats,
-		trace: trace.DefaultTracer,
+func NewHandler(c client.Client, opts ...Option) *Debug {
+	d := &Debug{
+		log:    log.DefaultLog,
+		stats:  stats.DefaultStats,
+		trace:  trace.DefaultTracer,
+		config: config.DefaultConfig,
+		faults: fault.DefaultInjector,
+	}
+	for _, o := range opts {
+		o(d)
 	}
+	return d
 }
 
 type Debug struct {
@@ -31,6 +59,10 @@ type Debug struct {
 	stats stats.Stats
 	// the tracer
 	trace trace.Tracer
+	// the config to dump
+	config config.Config
+	// the injected faults
+	faults *fault.Injector
 }
 
 func (d *Debug) Health(ctx context.Context, req *proto.HealthRequest, rsp *proto.HealthResponse) error {
@@ -57,6 +89,7 @@ func (d *Debug) Stats(ctx context.Context, req *proto.StatsRequest, rsp *proto.S
 	rsp.Threads = stats[0].Threads
 	rsp.Requests = stats[0].Requests
 	rsp.Errors = stats[0].Errors
+	rsp.Counters = stats[0].Counters
 
 	return nil
 }
@@ -90,6 +123,80 @@ func (d *Debug) Trace(ctx context.Context, req *proto.TraceRequest, rsp *proto.T
 	return nil
 }
 
+func (d *Debug) Config(ctx context.Context, req *proto.ConfigRequest, rsp *proto.ConfigResponse) error {
+	if d.config == nil {
+		return nil
+	}
+
+	var path []string
+	if len(req.Path) > 0 {
+		path = strings.Split(req.Path, ".")
+	}
+
+	for _, e := range config.Dump(d.config, path...) {
+		b, err := json.Marshal(e.Value)
+		if err != nil {
+			return err
+		}
+		v := &proto.ConfigValue{
+			Path:  e.Path,
+			Value: string(b),
+		}
+		if p := e.Provenance; p != nil {
+			v.Source = p.Source
+			v.File = p.File
+			v.Line = int64(p.Line)
+			v.Env = p.Env
+		}
+		rsp.Values = append(rsp.Values, v)
+	}
+
+	return nil
+}
+
+func (d *Debug) Faults(ctx context.Context, req *proto.FaultsRequest, rsp *proto.FaultsResponse) error {
+	if req.Clear {
+		d.faults.Clear()
+	}
+
+	for _, id := range req.Delete {
+		d.faults.Delete(id)
+	}
+
+	for _, f := range req.Set {
+		if err := d.faults.Set(&fault.Fault{
+			Id:       f.Id,
+			Service:  f.Service,
+			Endpoint: f.Endpoint,
+			Metadata: f.Metadata,
+			Topic:    f.Topic,
+			Percent:  int(f.Percent),
+			Delay:    time.Duration(f.Delay) * time.Millisecond,
+			Error:    f.Error,
+			Drop:     f.Drop,
+		}); err != nil {
+			return errors.BadRequest("go.micro.debug", "invalid fault: %v", err)
+		}
+	}
+
+	rsp.Enabled = fault.Enabled
+	for _, f := range d.faults.List() {
+		rsp.Faults = append(rsp.Faults, &proto.Fault{
+			Id:       f.Id,
+			Service:  f.Service,
+			Endpoint: f.Endpoint,
+			Metadata: f.Metadata,
+			Topic:    f.Topic,
+			Percent:  int64(f.Percent),
+			Delay:    int64(f.Delay / time.Millisecond),
+			Error:    f.Error,
+			Drop:     f.Drop,
+		})
+	}
+
+	return nil
+}
+
 func (d *Debug) Log(ctx context.Context, stream server.Stream) error {
 	req := new(proto.LogRequest)
 	if err := stream.Recv(req); err != nil {
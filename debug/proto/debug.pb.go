@@ -181,10 +181,12 @@ type StatsResponse struct {
 	// total number of requests
 	Requests uint64 `protobuf:"varint,7,opt,name=requests,proto3" json:"requests,omitempty"`
 	// total number of errors
-	Errors               uint64   `protobuf:"varint,8,opt,name=errors,proto3" json:"errors,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Errors uint64 `protobuf:"varint,8,opt,name=errors,proto3" json:"errors,omitempty"`
+	// named counters
+	Counters             map[string]uint64 `protobuf:"bytes,9,rep,name=counters,proto3" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *StatsResponse) Reset()         { *m = StatsResponse{} }
@@ -268,6 +270,13 @@ func (m *StatsResponse) GetErrors() uint64 {
 	return 0
 }
 
+func (m *StatsResponse) GetCounters() map[string]uint64 {
+	if m != nil {
+		return m.Counters
+	}
+	return nil
+}
+
 // LogRequest requests service logs
 type LogRequest struct {
 	// service to request logs for
@@ -578,12 +587,425 @@ func (m *Span) GetType() SpanType {
 	return SpanType_INBOUND
 }
 
+type ConfigRequest struct {
+	// optional service name
+	// optional service name
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// optional dotted path to scope to
+	// optional dotted path to scope to
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfigRequest) Reset()         { *m = ConfigRequest{} }
+func (m *ConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*ConfigRequest) ProtoMessage()    {}
+func (*ConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_466b588516b7ea56, []int{9}
+}
+
+func (m *ConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigRequest.Unmarshal(m, b)
+}
+func (m *ConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigRequest.Marshal(b, m, deterministic)
+}
+func (m *ConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigRequest.Merge(m, src)
+}
+func (m *ConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_ConfigRequest.Size(m)
+}
+func (m *ConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigRequest proto.InternalMessageInfo
+
+func (m *ConfigRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *ConfigRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+type ConfigResponse struct {
+	Values               []*ConfigValue `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ConfigResponse) Reset()         { *m = ConfigResponse{} }
+func (m *ConfigResponse) String() string { return proto.CompactTextString(m) }
+func (*ConfigResponse) ProtoMessage()    {}
+func (*ConfigResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_466b588516b7ea56, []int{10}
+}
+
+func (m *ConfigResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigResponse.Unmarshal(m, b)
+}
+func (m *ConfigResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigResponse.Marshal(b, m, deterministic)
+}
+func (m *ConfigResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigResponse.Merge(m, src)
+}
+func (m *ConfigResponse) XXX_Size() int {
+	return xxx_messageInfo_ConfigResponse.Size(m)
+}
+func (m *ConfigResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigResponse proto.InternalMessageInfo
+
+func (m *ConfigResponse) GetValues() []*ConfigValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// ConfigValue is an effective config value and its provenance
+// ConfigValue is an effective config value and its provenance
+type ConfigValue struct {
+	// dotted path of the value
+	// dotted path of the value
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// json encoded value, secrets are redacted
+	// json encoded value, secrets are redacted
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// name of the source e.g file, env
+	// name of the source e.g file, env
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// file the value was defined in
+	// file the value was defined in
+	File string `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`
+	// line of the file
+	// line of the file
+	Line int64 `protobuf:"varint,5,opt,name=line,proto3" json:"line,omitempty"`
+	// environment variable the value was read from
+	// environment variable the value was read from
+	Env                  string   `protobuf:"bytes,6,opt,name=env,proto3" json:"env,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfigValue) Reset()         { *m = ConfigValue{} }
+func (m *ConfigValue) String() string { return proto.CompactTextString(m) }
+func (*ConfigValue) ProtoMessage()    {}
+func (*ConfigValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_466b588516b7ea56, []int{11}
+}
+
+func (m *ConfigValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigValue.Unmarshal(m, b)
+}
+func (m *ConfigValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigValue.Marshal(b, m, deterministic)
+}
+func (m *ConfigValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigValue.Merge(m, src)
+}
+func (m *ConfigValue) XXX_Size() int {
+	return xxx_messageInfo_ConfigValue.Size(m)
+}
+func (m *ConfigValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigValue proto.InternalMessageInfo
+
+func (m *ConfigValue) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *ConfigValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *ConfigValue) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ConfigValue) GetFile() string {
+	if m != nil {
+		return m.File
+	}
+	return ""
+}
+
+func (m *ConfigValue) GetLine() int64 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ConfigValue) GetEnv() string {
+	if m != nil {
+		return m.Env
+	}
+	return ""
+}
+
+// FaultsRequest sets and removes faults then lists them
+type FaultsRequest struct {
+	// optional service name
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// faults to add or replace by id
+	Set []*Fault `protobuf:"bytes,2,rep,name=set,proto3" json:"set,omitempty"`
+	// ids of the faults to remove
+	Delete []string `protobuf:"bytes,3,rep,name=delete,proto3" json:"delete,omitempty"`
+	// remove all the faults
+	Clear                bool     `protobuf:"varint,4,opt,name=clear,proto3" json:"clear,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FaultsRequest) Reset()         { *m = FaultsRequest{} }
+func (m *FaultsRequest) String() string { return proto.CompactTextString(m) }
+func (*FaultsRequest) ProtoMessage()    {}
+func (*FaultsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_466b588516b7ea56, []int{12}
+}
+
+func (m *FaultsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FaultsRequest.Unmarshal(m, b)
+}
+func (m *FaultsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FaultsRequest.Marshal(b, m, deterministic)
+}
+func (m *FaultsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FaultsRequest.Merge(m, src)
+}
+func (m *FaultsRequest) XXX_Size() int {
+	return xxx_messageInfo_FaultsRequest.Size(m)
+}
+func (m *FaultsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FaultsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FaultsRequest proto.InternalMessageInfo
+
+func (m *FaultsRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *FaultsRequest) GetSet() []*Fault {
+	if m != nil {
+		return m.Set
+	}
+	return nil
+}
+
+func (m *FaultsRequest) GetDelete() []string {
+	if m != nil {
+		return m.Delete
+	}
+	return nil
+}
+
+func (m *FaultsRequest) GetClear() bool {
+	if m != nil {
+		return m.Clear
+	}
+	return false
+}
+
+type FaultsResponse struct {
+	// whether faults are injected by this build
+	Enabled              bool     `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Faults               []*Fault `protobuf:"bytes,2,rep,name=faults,proto3" json:"faults,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FaultsResponse) Reset()         { *m = FaultsResponse{} }
+func (m *FaultsResponse) String() string { return proto.CompactTextString(m) }
+func (*FaultsResponse) ProtoMessage()    {}
+func (*FaultsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_466b588516b7ea56, []int{13}
+}
+
+func (m *FaultsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FaultsResponse.Unmarshal(m, b)
+}
+func (m *FaultsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FaultsResponse.Marshal(b, m, deterministic)
+}
+func (m *FaultsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FaultsResponse.Merge(m, src)
+}
+func (m *FaultsResponse) XXX_Size() int {
+	return xxx_messageInfo_FaultsResponse.Size(m)
+}
+func (m *FaultsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FaultsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FaultsResponse proto.InternalMessageInfo
+
+func (m *FaultsResponse) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+func (m *FaultsResponse) GetFaults() []*Fault {
+	if m != nil {
+		return m.Faults
+	}
+	return nil
+}
+
+// Fault is a failure injected into requests or publishes
+type Fault struct {
+	// generated if blank
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// service pattern, blank for all
+	Service string `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	// endpoint pattern, blank for all
+	Endpoint string `protobuf:"bytes,3,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// metadata the request must have
+	Metadata map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// topic pattern, only publishes are targeted if set
+	Topic string `protobuf:"bytes,5,opt,name=topic,proto3" json:"topic,omitempty"`
+	// percentage of matches, 0 for all
+	Percent int64 `protobuf:"varint,6,opt,name=percent,proto3" json:"percent,omitempty"`
+	// latency in milliseconds
+	Delay int64 `protobuf:"varint,7,opt,name=delay,proto3" json:"delay,omitempty"`
+	// error code to return
+	Error int32 `protobuf:"varint,8,opt,name=error,proto3" json:"error,omitempty"`
+	// drop the response or message
+	Drop                 bool     `protobuf:"varint,9,opt,name=drop,proto3" json:"drop,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Fault) Reset()         { *m = Fault{} }
+func (m *Fault) String() string { return proto.CompactTextString(m) }
+func (*Fault) ProtoMessage()    {}
+func (*Fault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_466b588516b7ea56, []int{14}
+}
+
+func (m *Fault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Fault.Unmarshal(m, b)
+}
+func (m *Fault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Fault.Marshal(b, m, deterministic)
+}
+func (m *Fault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Fault.Merge(m, src)
+}
+func (m *Fault) XXX_Size() int {
+	return xxx_messageInfo_Fault.Size(m)
+}
+func (m *Fault) XXX_DiscardUnknown() {
+	xxx_messageInfo_Fault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Fault proto.InternalMessageInfo
+
+func (m *Fault) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Fault) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *Fault) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *Fault) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Fault) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *Fault) GetPercent() int64 {
+	if m != nil {
+		return m.Percent
+	}
+	return 0
+}
+
+func (m *Fault) GetDelay() int64 {
+	if m != nil {
+		return m.Delay
+	}
+	return 0
+}
+
+func (m *Fault) GetError() int32 {
+	if m != nil {
+		return m.Error
+	}
+	return 0
+}
+
+func (m *Fault) GetDrop() bool {
+	if m != nil {
+		return m.Drop
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterEnum("SpanType", SpanType_name, SpanType_value)
 	proto.RegisterType((*HealthRequest)(nil), "HealthRequest")
 	proto.RegisterType((*HealthResponse)(nil), "HealthResponse")
 	proto.RegisterType((*StatsRequest)(nil), "StatsRequest")
 	proto.RegisterType((*StatsResponse)(nil), "StatsResponse")
+	proto.RegisterMapType((map[string]uint64)(nil), "StatsResponse.CountersEntry")
 	proto.RegisterType((*LogRequest)(nil), "LogRequest")
 	proto.RegisterType((*Record)(nil), "Record")
 	proto.RegisterMapType((map[string]string)(nil), "Record.MetadataEntry")
@@ -591,47 +1013,74 @@ func init() {
 	proto.RegisterType((*TraceResponse)(nil), "TraceResponse")
 	proto.RegisterType((*Span)(nil), "Span")
 	proto.RegisterMapType((map[string]string)(nil), "Span.MetadataEntry")
+	proto.RegisterType((*ConfigRequest)(nil), "ConfigRequest")
+	proto.RegisterType((*ConfigResponse)(nil), "ConfigResponse")
+	proto.RegisterType((*ConfigValue)(nil), "ConfigValue")
+	proto.RegisterType((*FaultsRequest)(nil), "FaultsRequest")
+	proto.RegisterType((*FaultsResponse)(nil), "FaultsResponse")
+	proto.RegisterType((*Fault)(nil), "Fault")
+	proto.RegisterMapType((map[string]string)(nil), "Fault.MetadataEntry")
 }
 
 func init() { proto.RegisterFile("proto/debug.proto", fileDescriptor_466b588516b7ea56) }
 
 var fileDescriptor_466b588516b7ea56 = []byte{
-	// 589 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0xdb, 0x6e, 0xd3, 0x40,
-	0x10, 0x8d, 0xed, 0x38, 0xb1, 0xa7, 0x8d, 0x29, 0xcb, 0x45, 0x96, 0xb9, 0x55, 0x96, 0x90, 0xc2,
-	0x45, 0x2e, 0x94, 0x17, 0x04, 0x6f, 0xa8, 0x48, 0x20, 0x95, 0x56, 0xda, 0xb6, 0x1f, 0xb0, 0xb5,
-	0x47, 0xae, 0xa1, 0xbe, 0xb0, 0xbb, 0xae, 0x94, 0x6f, 0xe1, 0x0b, 0x78, 0xe3, 0x67, 0xf8, 0x1f,
-	0xb4, 0x17, 0xa7, 0xb1, 0x10, 0xea, 0x03, 0x6f, 0x7b, 0xce, 0xce, 0x9e, 0xcc, 0x9c, 0x1c, 0x0f,
-	0xdc, 0xee, 0x78, 0x2b, 0xdb, 0xbd, 0x02, 0xcf, 0xfb, 0x32, 0xd3, 0xe7, 0xf4, 0x19, 0x2c, 0x3e,
-	0x21, 0xbb, 0x94, 0x17, 0x14, 0xbf, 0xf7, 0x28, 0x24, 0x89, 0x61, 0x2e, 0x90, 0x5f, 0x55, 0x39,
-	0xc6, 0xce, 0xae, 0xb3, 0x0c, 0xe9, 0x00, 0xd3, 0x25, 0x44, 0x43, 0xa9, 0xe8, 0xda, 0x46, 0x20,
-	0xb9, 0x0f, 0x33, 0x21, 0x99, 0xec, 0x85, 0x2d, 0xb5, 0x28, 0x5d, 0xc2, 0xf6, 0x89, 0x64, 0x52,
-	0xdc, 0xac, 0xf9, 0xdb, 0x81, 0x85, 0x2d, 0xb5, 0x9a, 0x0f, 0x21, 0x94, 0x55, 0x8d, 0x42, 0xb2,
-	0xba, 0xd3, 0xd5, 0x53, 0x7a, 0x4d, 0x68, 0x25, 0xc9, 0xb8, 0xc4, 0x22, 0x76, 0xf5, 0xdd, 0x00,
-	0x55, 0x2f, 0x7d, 0xa7, 0x0a, 0x63, 0x4f, 0x5f, 0x58, 0xa4, 0xf8, 0x1a, 0xeb, 0x96, 0xaf, 0xe2,
-	0xa9, 0xe1, 0x0d, 0x52, 0x4a, 0xf2, 0x82, 0x23, 0x2b, 0x44, 0xec, 0x1b, 0x25, 0x0b, 0x49, 0x04,
-	0x6e, 0x99, 0xc7, 0x33, 0x4d, 0xba, 0x65, 0x4e, 0x12, 0x08, 0xb8, 0x19, 0x44, 0xc4, 0x73, 0xcd,
-	0xae, 0xb1, 0x52, 0x47, 0xce, 0x5b, 0x2e, 0xe2, 0xc0, 0xa8, 0x1b, 0x94, 0x7e, 0x05, 0x38, 0x6c,
-	0xcb, 0x1b, 0xe7, 0x37, 0x0e, 0x72, 0x64, 0xb5, 0x1e, 0x27, 0xa0, 0x16, 0x91, 0xbb, 0xe0, 0xe7,
-	0x6d, 0xdf, 0x48, 0x3d, 0x8c, 0x47, 0x0d, 0x50, 0xac, 0xa8, 0x9a, 0x1c, 0xf5, 0x28, 0x1e, 0x35,
-	0x20, 0xfd, 0xe5, 0xc0, 0x8c, 0x62, 0xde, 0xf2, 0xe2, 0x6f, 0xf3, 0xbc, 0x4d, 0xf3, 0x5e, 0x43,
-	0x50, 0xa3, 0x64, 0x05, 0x93, 0x2c, 0x76, 0x77, 0xbd, 0xe5, 0xd6, 0xfe, 0xbd, 0xcc, 0x3c, 0xcc,
-	0xbe, 0x58, 0xfe, 0x63, 0x23, 0xf9, 0x8a, 0xae, 0xcb, 0x54, 0xe7, 0x35, 0x0a, 0xc1, 0x4a, 0x63,
-	0x6b, 0x48, 0x07, 0x98, 0xbc, 0x87, 0xc5, 0xe8, 0x11, 0xd9, 0x01, 0xef, 0x1b, 0xae, 0xec, 0x80,
-	0xea, 0xa8, 0xda, 0xbd, 0x62, 0x97, 0x3d, 0xea, 0xd9, 0x42, 0x6a, 0xc0, 0x3b, 0xf7, 0xad, 0x93,
-	0x3e, 0x86, 0xed, 0x53, 0xce, 0x72, 0x1c, 0x0c, 0x8a, 0xc0, 0xad, 0x0a, 0xfb, 0xd4, 0xad, 0x8a,
-	0xf4, 0x25, 0x2c, 0xec, 0xbd, 0x4d, 0xc5, 0x03, 0xf0, 0x45, 0xc7, 0x1a, 0x15, 0x34, 0xd5, 0xb7,
-	0x9f, 0x9d, 0x74, 0xac, 0xa1, 0x86, 0x4b, 0x7f, 0xb8, 0x30, 0x55, 0x58, 0xfd, 0xa0, 0x54, 0xcf,
-	0xac, 0x92, 0x01, 0x56, 0xdc, 0x1d, 0xc4, 0x95, 0xe7, 0x1d, 0xe3, 0x68, 0xcd, 0x0d, 0xa9, 0x45,
-	0x84, 0xc0, 0xb4, 0x61, 0xb5, 0x31, 0x37, 0xa4, 0xfa, 0xbc, 0x99, 0x37, 0x7f, 0x9c, 0xb7, 0x04,
-	0x82, 0xa2, 0xe7, 0x4c, 0x56, 0x6d, 0x63, 0xb3, 0xb2, 0xc6, 0x64, 0x6f, 0xc3, 0xe8, 0xb9, 0x6e,
-	0xf8, 0x8e, 0x6e, 0xf8, 0x9f, 0x36, 0x3f, 0x82, 0xa9, 0x5c, 0x75, 0xa8, 0x43, 0x14, 0xed, 0x87,
-	0xba, 0xf8, 0x74, 0xd5, 0x21, 0xd5, 0xf4, 0x7f, 0x79, 0xfd, 0xfc, 0x29, 0x04, 0x83, 0x1c, 0xd9,
-	0x82, 0xf9, 0xe7, 0xa3, 0x0f, 0xc7, 0x67, 0x47, 0x07, 0x3b, 0x13, 0xb2, 0x0d, 0xc1, 0xf1, 0xd9,
-	0xa9, 0x41, 0xce, 0xfe, 0x4f, 0x07, 0xfc, 0x03, 0xb5, 0x18, 0xc8, 0x13, 0xf0, 0x0e, 0xdb, 0x92,
-	0x6c, 0x65, 0xd7, 0x09, 0x4e, 0xe6, 0x36, 0x28, 0xe9, 0xe4, 0x95, 0x43, 0x5e, 0xc0, 0xcc, 0x2c,
-	0x02, 0x12, 0x65, 0xa3, 0xe5, 0x91, 0xdc, 0xca, 0xc6, 0x1b, 0x22, 0x9d, 0x90, 0x25, 0xf8, 0xfa,
-	0x03, 0x27, 0x8b, 0x6c, 0x73, 0x27, 0x24, 0x51, 0x36, 0xfa, 0xee, 0x4d, 0xa5, 0xfe, 0xd3, 0xc9,
-	0x22, 0xdb, 0x0c, 0x47, 0x12, 0x65, 0xa3, 0x2c, 0xa4, 0x93, 0xf3, 0x99, 0xde, 0x5d, 0x6f, 0xfe,
-	0x04, 0x00, 0x00, 0xff, 0xff, 0x4c, 0x2d, 0xec, 0xd8, 0xd0, 0x04, 0x00, 0x00,
+	// 903 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xcf, 0x8e, 0xdc, 0xc4,
+	0x13, 0x9e, 0xb1, 0xc7, 0x33, 0x76, 0xed, 0x8e, 0x37, 0xbf, 0xfe, 0x05, 0x64, 0x99, 0x10, 0x22,
+	0x0b, 0xa4, 0xe1, 0x8f, 0x3a, 0x61, 0x91, 0x50, 0x04, 0xe2, 0x02, 0x01, 0x01, 0x0a, 0x89, 0xd4,
+	0xd9, 0x70, 0xef, 0xb5, 0x7b, 0x67, 0x0d, 0x1e, 0xdb, 0x69, 0xb7, 0x57, 0x9a, 0x33, 0x27, 0x9e,
+	0x81, 0x57, 0xe0, 0xc0, 0x83, 0xf0, 0x50, 0xa8, 0xaa, 0xdb, 0xb3, 0x76, 0x20, 0xda, 0x48, 0xb9,
+	0xf5, 0x57, 0x5d, 0x5d, 0x5d, 0xfe, 0xea, 0xab, 0x6a, 0xc3, 0xff, 0x5a, 0xdd, 0x98, 0xe6, 0x7e,
+	0xa1, 0xce, 0xfb, 0x2d, 0xa7, 0x75, 0xf6, 0x21, 0xac, 0xbf, 0x57, 0xb2, 0x32, 0x97, 0x42, 0xbd,
+	0xe8, 0x55, 0x67, 0x58, 0x02, 0xab, 0x4e, 0xe9, 0xab, 0x32, 0x57, 0xc9, 0xfc, 0xde, 0x7c, 0x13,
+	0x89, 0x01, 0x66, 0x1b, 0x88, 0x07, 0xd7, 0xae, 0x6d, 0xea, 0x4e, 0xb1, 0xb7, 0x61, 0xd9, 0x19,
+	0x69, 0xfa, 0xce, 0xb9, 0x3a, 0x94, 0x6d, 0xe0, 0xf8, 0x99, 0x91, 0xa6, 0xbb, 0x39, 0xe6, 0xdf,
+	0x1e, 0xac, 0x9d, 0xab, 0x8b, 0x79, 0x07, 0x22, 0x53, 0xee, 0x54, 0x67, 0xe4, 0xae, 0x25, 0xef,
+	0x85, 0xb8, 0x36, 0x50, 0x24, 0x23, 0xb5, 0x51, 0x45, 0xe2, 0xd1, 0xde, 0x00, 0x31, 0x97, 0xbe,
+	0x45, 0xc7, 0xc4, 0xa7, 0x0d, 0x87, 0xd0, 0xbe, 0x53, 0xbb, 0x46, 0xef, 0x93, 0x85, 0xb5, 0x5b,
+	0x84, 0x91, 0xcc, 0xa5, 0x56, 0xb2, 0xe8, 0x92, 0xc0, 0x46, 0x72, 0x90, 0xc5, 0xe0, 0x6d, 0xf3,
+	0x64, 0x49, 0x46, 0x6f, 0x9b, 0xb3, 0x14, 0x42, 0x6d, 0x3f, 0xa4, 0x4b, 0x56, 0x64, 0x3d, 0x60,
+	0x8c, 0xae, 0xb4, 0x6e, 0x74, 0x97, 0x84, 0x36, 0xba, 0x45, 0xec, 0x21, 0x84, 0x79, 0xd3, 0xd7,
+	0x46, 0xe9, 0x2e, 0x89, 0xee, 0xf9, 0x9b, 0xa3, 0xd3, 0x3b, 0x7c, 0xf2, 0x9d, 0xfc, 0x1b, 0xb7,
+	0xfd, 0x6d, 0x6d, 0xf4, 0x5e, 0x1c, 0xbc, 0xd3, 0x2f, 0x61, 0x3d, 0xd9, 0x62, 0xb7, 0xc0, 0xff,
+	0x55, 0xed, 0x1d, 0x71, 0xb8, 0x64, 0xb7, 0x21, 0xb8, 0x92, 0x55, 0xaf, 0x1c, 0x05, 0x16, 0x7c,
+	0xe1, 0x3d, 0x9c, 0x67, 0xbf, 0x00, 0x3c, 0x6e, 0xb6, 0x37, 0xd2, 0x6e, 0x0b, 0xa7, 0x95, 0xdc,
+	0x51, 0x88, 0x50, 0x38, 0x84, 0x91, 0x29, 0x11, 0xe2, 0xd0, 0x17, 0x16, 0xa0, 0xb5, 0x2b, 0xeb,
+	0x5c, 0x11, 0x83, 0xbe, 0xb0, 0x20, 0xfb, 0x6b, 0x0e, 0x4b, 0xa1, 0xf2, 0x46, 0x17, 0xff, 0xae,
+	0x99, 0x3f, 0xae, 0xd9, 0xa7, 0x10, 0xee, 0x94, 0x91, 0x85, 0x34, 0x32, 0xf1, 0x88, 0x8b, 0xb7,
+	0xb8, 0x3d, 0xc8, 0x7f, 0x72, 0x76, 0x47, 0xc2, 0xe0, 0x86, 0x99, 0xef, 0x54, 0xd7, 0xc9, 0xad,
+	0xad, 0x66, 0x24, 0x06, 0x88, 0xf4, 0x4c, 0x0e, 0xdd, 0x44, 0x4f, 0x34, 0xa6, 0xe7, 0x2e, 0x1c,
+	0x9f, 0x69, 0x99, 0xab, 0x81, 0xa0, 0x18, 0xbc, 0xb2, 0x70, 0x47, 0xbd, 0xb2, 0xc8, 0x3e, 0x81,
+	0xb5, 0xdb, 0x77, 0x62, 0x7c, 0x07, 0x82, 0xae, 0x95, 0x35, 0xea, 0x1b, 0xf3, 0x0e, 0xf8, 0xb3,
+	0x56, 0xd6, 0xc2, 0xda, 0xb2, 0x3f, 0x3c, 0x58, 0x20, 0xc6, 0x0b, 0x0d, 0x1e, 0x73, 0x91, 0x2c,
+	0x70, 0xc1, 0xbd, 0x21, 0x38, 0x72, 0xde, 0x4a, 0xad, 0x1c, 0xb9, 0x91, 0x70, 0x88, 0x31, 0x58,
+	0xd4, 0x72, 0x67, 0xc9, 0x8d, 0x04, 0xad, 0xc7, 0x32, 0x0f, 0xa6, 0x32, 0x4f, 0x21, 0x2c, 0x7a,
+	0x2d, 0x4d, 0xd9, 0xd4, 0x4e, 0xa2, 0x07, 0xcc, 0xee, 0x8f, 0x88, 0x5e, 0x51, 0xc2, 0xff, 0xa7,
+	0x84, 0x5f, 0x49, 0xf3, 0xbb, 0xb0, 0x30, 0xfb, 0x56, 0x91, 0x76, 0xe3, 0xd3, 0x88, 0x9c, 0xcf,
+	0xf6, 0xad, 0x12, 0x64, 0x7e, 0x33, 0xae, 0xbf, 0x42, 0x1d, 0xd7, 0x17, 0xe5, 0x6b, 0xa8, 0x91,
+	0xc1, 0xa2, 0x95, 0xe6, 0xd2, 0xc5, 0xa0, 0x75, 0xf6, 0x39, 0xc4, 0xc3, 0x71, 0x57, 0x8b, 0xf7,
+	0x61, 0x49, 0xd1, 0x87, 0x62, 0x1c, 0x73, 0xeb, 0xf0, 0x33, 0x1a, 0x85, 0xdb, 0xcb, 0x7e, 0x9f,
+	0xc3, 0xd1, 0xc8, 0x7e, 0x88, 0x3d, 0xbf, 0x8e, 0xfd, 0xdf, 0x49, 0x53, 0x4f, 0x34, 0xbd, 0xce,
+	0x07, 0xc9, 0x39, 0x84, 0x11, 0x2e, 0xca, 0xea, 0x50, 0x1f, 0x5c, 0xa3, 0xad, 0x2a, 0x6b, 0x45,
+	0xc5, 0xf1, 0x05, 0xad, 0x91, 0x1c, 0x55, 0x5f, 0x51, 0x51, 0x22, 0x81, 0xcb, 0xec, 0x05, 0xac,
+	0xbf, 0x93, 0x7d, 0xf5, 0x1a, 0x73, 0x90, 0x25, 0xe0, 0x77, 0xca, 0xb8, 0xf6, 0x58, 0x72, 0x3a,
+	0x26, 0xd0, 0x84, 0x69, 0x15, 0xaa, 0x52, 0x06, 0xd3, 0xf2, 0x31, 0x2d, 0x8b, 0xa8, 0x55, 0x2b,
+	0x25, 0x35, 0xe5, 0x15, 0x0a, 0x0b, 0xb2, 0x1f, 0x21, 0x1e, 0xae, 0x74, 0xb4, 0x25, 0xb0, 0x52,
+	0xb5, 0x3c, 0xaf, 0x94, 0x15, 0x7a, 0x28, 0x06, 0xc8, 0xee, 0xc2, 0xf2, 0x82, 0x7c, 0x5f, 0xba,
+	0xd6, 0x59, 0xb3, 0x3f, 0x3d, 0x08, 0xc8, 0xf2, 0x72, 0x9f, 0x8c, 0xbf, 0xc3, 0x9b, 0x7e, 0x47,
+	0x0a, 0xa1, 0xaa, 0x8b, 0xb6, 0x29, 0x0f, 0x32, 0x3f, 0x60, 0xf6, 0x60, 0x24, 0xcf, 0x05, 0xdd,
+	0x78, 0xdb, 0xde, 0xf8, 0x4a, 0x7d, 0x62, 0x63, 0x35, 0x6d, 0x99, 0x27, 0x81, 0x6b, 0x2c, 0x04,
+	0x78, 0x7b, 0xab, 0x74, 0x8e, 0x9d, 0xb4, 0x24, 0xfe, 0x07, 0x88, 0xfe, 0x85, 0xaa, 0xe4, 0x9e,
+	0xc6, 0xb4, 0x2f, 0x2c, 0x40, 0x2b, 0x4d, 0x65, 0x92, 0x79, 0x20, 0x2c, 0xc0, 0x12, 0x16, 0xba,
+	0x69, 0x93, 0x88, 0x48, 0xa1, 0xf5, 0x1b, 0x09, 0xfe, 0xa3, 0x0f, 0x20, 0x1c, 0xfa, 0x87, 0x1d,
+	0xc1, 0xea, 0x87, 0x27, 0x5f, 0x3f, 0x7d, 0xfe, 0xe4, 0xd1, 0xad, 0x19, 0x3b, 0x86, 0xf0, 0xe9,
+	0xf3, 0x33, 0x8b, 0xe6, 0xa7, 0xbf, 0x79, 0x10, 0x3c, 0xc2, 0x07, 0x98, 0xbd, 0x07, 0xfe, 0xe3,
+	0x66, 0xcb, 0x8e, 0xf8, 0xf5, 0xc8, 0x4e, 0x57, 0x6e, 0x32, 0x66, 0xb3, 0x07, 0x73, 0xf6, 0x31,
+	0x2c, 0xed, 0x83, 0xcb, 0x62, 0x3e, 0x79, 0xa4, 0xd3, 0x13, 0x3e, 0x7d, 0x89, 0xb3, 0x19, 0xdb,
+	0x40, 0x40, 0x0f, 0x0c, 0x5b, 0xf3, 0xf1, 0xdb, 0x9b, 0xc6, 0xd3, 0x77, 0xc7, 0x7a, 0xd2, 0x94,
+	0x63, 0x6b, 0x3e, 0x9e, 0x86, 0x69, 0xcc, 0x27, 0xc3, 0x2f, 0x9b, 0x61, 0x02, 0xb6, 0x97, 0x58,
+	0xcc, 0x27, 0xcd, 0x9c, 0x9e, 0xf0, 0x69, 0x77, 0x5a, 0x67, 0x2b, 0x3d, 0x16, 0xf3, 0x89, 0xec,
+	0xd3, 0x13, 0x3e, 0xd5, 0x64, 0x36, 0x3b, 0x5f, 0xd2, 0xdf, 0xc7, 0x67, 0xff, 0x0c, 0x00, 0x4c,
+	0x84, 0xea, 0xe1, 0x92, 0x08, 0x00, 0x00,
 }
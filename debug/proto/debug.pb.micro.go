@@ -46,6 +46,8 @@ type DebugService interface {
 	Health(ctx context.Context, in *HealthRequest, opts ...client.CallOption) (*HealthResponse, error)
 	Stats(ctx context.Context, in *StatsRequest, opts ...client.CallOption) (*StatsResponse, error)
 	Trace(ctx context.Context, in *TraceRequest, opts ...client.CallOption) (*TraceResponse, error)
+	Config(ctx context.Context, in *ConfigRequest, opts ...client.CallOption) (*ConfigResponse, error)
+	Faults(ctx context.Context, in *FaultsRequest, opts ...client.CallOption) (*FaultsResponse, error)
 }
 
 type debugService struct {
@@ -139,6 +141,26 @@ func (c *debugService) Trace(ctx context.Context, in *TraceRequest, opts ...clie
 	return out, nil
 }
 
+func (c *debugService) Config(ctx context.Context, in *ConfigRequest, opts ...client.CallOption) (*ConfigResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Config", in)
+	out := new(ConfigResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugService) Faults(ctx context.Context, in *FaultsRequest, opts ...client.CallOption) (*FaultsResponse, error) {
+	req := c.c.NewRequest(c.name, "Debug.Faults", in)
+	out := new(FaultsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Debug service
 
 type DebugHandler interface {
@@ -146,6 +168,8 @@ type DebugHandler interface {
 	Health(context.Context, *HealthRequest, *HealthResponse) error
 	Stats(context.Context, *StatsRequest, *StatsResponse) error
 	Trace(context.Context, *TraceRequest, *TraceResponse) error
+	Config(context.Context, *ConfigRequest, *ConfigResponse) error
+	Faults(context.Context, *FaultsRequest, *FaultsResponse) error
 }
 
 func RegisterDebugHandler(s server.Server, hdlr DebugHandler, opts ...server.HandlerOption) error {
@@ -154,6 +178,8 @@ func RegisterDebugHandler(s server.Server, hdlr DebugHandler, opts ...server.Han
 		Health(ctx context.Context, in *HealthRequest, out *HealthResponse) error
 		Stats(ctx context.Context, in *StatsRequest, out *StatsResponse) error
 		Trace(ctx context.Context, in *TraceRequest, out *TraceResponse) error
+		Config(ctx context.Context, in *ConfigRequest, out *ConfigResponse) error
+		Faults(ctx context.Context, in *FaultsRequest, out *FaultsResponse) error
 	}
 	type Debug struct {
 		debug
@@ -217,3 +243,11 @@ func (h *debugHandler) Stats(ctx context.Context, in *StatsRequest, out *StatsRe
 func (h *debugHandler) Trace(ctx context.Context, in *TraceRequest, out *TraceResponse) error {
 	return h.DebugHandler.Trace(ctx, in, out)
 }
+
+func (h *debugHandler) Config(ctx context.Context, in *ConfigRequest, out *ConfigResponse) error {
+	return h.DebugHandler.Config(ctx, in, out)
+}
+
+func (h *debugHandler) Faults(ctx context.Context, in *FaultsRequest, out *FaultsResponse) error {
+	return h.DebugHandler.Faults(ctx, in, out)
+}
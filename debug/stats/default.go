@@ -16,6 +16,7 @@ type stats struct {
 	started  int64
 	requests uint64
 	errors   uint64
+	counters map[string]uint64
 }
 
 func (s *stats) snapshot() *Stat {
@@ -27,6 +28,11 @@ func (s *stats) snapshot() *Stat {
 
 	now := time.Now().Unix()
 
+	counters := make(map[string]uint64, len(s.counters))
+	for k, v := range s.counters {
+		counters[k] = v
+	}
+
 	return &Stat{
 		Timestamp: now,
 		Started:   s.started,
@@ -36,6 +42,7 @@ func (s *stats) snapshot() *Stat {
 		Threads:   uint64(runtime.NumGoroutine()),
 		Requests:  s.requests,
 		Errors:    s.errors,
+		Counters:  counters,
 	}
 }
 
@@ -79,11 +86,19 @@ func (s *stats) Record(err error) error {
 	return nil
 }
 
+func (s *stats) Count(name string, n uint64) error {
+	s.Lock()
+	s.counters[name] += n
+	s.Unlock()
+	return nil
+}
+
 // NewStats returns a new in memory stats buffer
 // TODO add options
 func NewStats() Stats {
 	return &stats{
-		started: time.Now().Unix(),
-		buffer:  ring.New(60),
+		started:  time.Now().Unix(),
+		buffer:   ring.New(60),
+		counters: make(map[string]uint64),
 	}
 }
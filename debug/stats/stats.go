@@ -11,6 +11,12 @@ type Stats interface {
 	Record(error) error
 }
 
+// Counter is implemented by stats which keep named counters
+type Counter interface {
+	// Count adds n to the named counter
+	Count(name string, n uint64) error
+}
+
 // A runtime stat
 type Stat struct {
 	// Timestamp of recording
@@ -29,6 +35,8 @@ type Stat struct {
 	Requests uint64
 	// Total errors
 	Errors uint64
+	// Named counters
+	Counters map[string]uint64
 }
 
 var (
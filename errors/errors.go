@@ -116,6 +116,16 @@ func InternalServerError(id, format string, a ...interface{}) error {
 	}
 }
 
+// ServiceUnavailable generates a 503 error.
+func ServiceUnavailable(id, format string, a ...interface{}) error {
+	return &Error{
+		Id:     id,
+		Code:   503,
+		Detail: fmt.Sprintf(format, a...),
+		Status: http.StatusText(503),
+	}
+}
+
 // Equal tries to compare errors
 func Equal(err1 error, err2 error) bool {
 	verr1, ok1 := err1.(*Error)
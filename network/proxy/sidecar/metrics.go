@@ -0,0 +1,47 @@
+package sidecar
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/server"
+)
+
+const (
+	// DirectionInbound is the direction of requests to the local service
+	DirectionInbound = "inbound"
+	// DirectionOutbound is the direction of requests from the local service
+	DirectionOutbound = "outbound"
+)
+
+// Metric returns the name of the counter of a route e.g
+// sidecar.outbound.go.micro.srv.greeter.Say.Hello.requests. Each route
+// counts requests, errors and latency_ms, the total time in milliseconds.
+func Metric(direction, service, endpoint, name string) string {
+	return strings.Join([]string{"sidecar", direction, service, endpoint, name}, ".")
+}
+
+// metrics returns a handler wrapper which counts the requests of each route
+func (s *Sidecar) metrics(direction string) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			start := time.Now()
+			err := h(ctx, req, rsp)
+
+			st, ok := s.opts.Stats.(stats.Counter)
+			if !ok {
+				return err
+			}
+			service, endpoint := req.Service(), req.Endpoint()
+			st.Count(Metric(direction, service, endpoint, "requests"), 1)
+			st.Count(Metric(direction, service, endpoint, "latency_ms"), uint64(time.Since(start)/time.Millisecond))
+			if err != nil {
+				st.Count(Metric(direction, service, endpoint, "errors"), 1)
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package sidecar
+
+import (
+	"crypto/tls"
+
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/network/router"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+type Options struct {
+	// Name of the service the sidecar runs alongside
+	Name string
+	// Version of the service
+	Version string
+	// Address of the local service e.g 127.0.0.1:8080.
+	// The inbound listener is disabled when it's blank.
+	Address string
+	// Inbound is the address other sidecars call the service on
+	Inbound string
+	// Outbound is the address the local service sends its requests to
+	Outbound string
+	// TLSConfig secures the traffic between sidecars
+	TLSConfig *tls.Config
+	// Registry the inbound listener is registered with
+	Registry registry.Registry
+	// Router resolves the outbound requests
+	Router router.Router
+	// Config the retry and timeout policy is read from
+	Config config.Config
+	// Path of the policy in the config
+	Path []string
+	// Stats records the per route metrics when it's a stats.Counter
+	Stats stats.Stats
+}
+
+type Option func(o *Options)
+
+// Name sets the name of the local service
+func Name(n string) Option {
+	return func(o *Options) {
+		o.Name = n
+	}
+}
+
+// Version sets the version of the local service
+func Version(v string) Option {
+	return func(o *Options) {
+		o.Version = v
+	}
+}
+
+// Address sets the address of the local service
+func Address(a string) Option {
+	return func(o *Options) {
+		o.Address = a
+	}
+}
+
+// Inbound sets the address of the inbound listener
+func Inbound(a string) Option {
+	return func(o *Options) {
+		o.Inbound = a
+	}
+}
+
+// Outbound sets the address of the outbound listener
+func Outbound(a string) Option {
+	return func(o *Options) {
+		o.Outbound = a
+	}
+}
+
+// TLSConfig sets the tls config used between sidecars. Use MutualTLS
+// to require sidecars to present a certificate signed by the CA.
+func TLSConfig(t *tls.Config) Option {
+	return func(o *Options) {
+		o.TLSConfig = t
+	}
+}
+
+// Registry sets the registry
+func Registry(r registry.Registry) Option {
+	return func(o *Options) {
+		o.Registry = r
+	}
+}
+
+// Router sets the router used to resolve outbound requests
+func Router(r router.Router) Option {
+	return func(o *Options) {
+		o.Router = r
+	}
+}
+
+// Config sets the config the policy is read from
+func Config(c config.Config) Option {
+	return func(o *Options) {
+		o.Config = c
+	}
+}
+
+// Path sets the path of the policy in the config
+func Path(p ...string) Option {
+	return func(o *Options) {
+		o.Path = p
+	}
+}
+
+// Stats sets the stats the per route metrics are recorded in
+func Stats(s stats.Stats) Option {
+	return func(o *Options) {
+		o.Stats = s
+	}
+}
@@ -0,0 +1,169 @@
+package sidecar
+
+import (
+	"context"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+// Policy is the retry and timeout policy of outbound requests
+type Policy struct {
+	// Retries is the number of times a request is retried,
+	// the client's retries are kept when it's not set
+	Retries *int `json:"retries,omitempty"`
+	// Timeout of a request e.g 5s
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Policies is the format of the policy in the config e.g
+//
+//	{
+//		"sidecar": {
+//			"default": {"retries": 1, "timeout": "5s"},
+//			"routes": {
+//				"go.micro.srv.greeter": {"retries": 3},
+//				"go.micro.srv.greeter/Say.Hello": {"retries": 0, "timeout": "1s"},
+//				"go.micro.srv.greeter/Say.Stream": {"timeout": "10s"}
+//			}
+//		}
+//	}
+//
+// Routes are keyed by service or service/endpoint. The most specific
+// policy applies and requests without one use the client defaults, as
+// do the retries of a policy which only sets a timeout.
+type Policies struct {
+	Default *Policy            `json:"default,omitempty"`
+	Routes  map[string]*Policy `json:"routes,omitempty"`
+}
+
+// Lookup returns the policy of the route
+func (p *Policies) Lookup(service, endpoint string) (*Policy, bool) {
+	if p == nil {
+		return nil, false
+	}
+	if pol, ok := p.Routes[service+"/"+endpoint]; ok && pol != nil {
+		return pol, true
+	}
+	if pol, ok := p.Routes[service]; ok && pol != nil {
+		return pol, true
+	}
+	if p.Default != nil {
+		return p.Default, true
+	}
+	return nil, false
+}
+
+// validate checks the timeouts can be parsed
+func (p *Policies) validate() error {
+	check := func(pol *Policy) error {
+		if pol == nil || len(pol.Timeout) == 0 {
+			return nil
+		}
+		_, err := time.ParseDuration(pol.Timeout)
+		return err
+	}
+	if err := check(p.Default); err != nil {
+		return err
+	}
+	for _, pol := range p.Routes {
+		if err := check(pol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// options returns the call options of the policy
+func (p *Policy) options() []client.CallOption {
+	var opts []client.CallOption
+	if p.Retries != nil {
+		opts = append(opts, client.WithRetries(*p.Retries))
+	}
+	if d := p.timeout(); d > 0 {
+		opts = append(opts, client.WithRequestTimeout(d))
+	}
+	return opts
+}
+
+func (p *Policy) timeout() time.Duration {
+	d, _ := time.ParseDuration(p.Timeout)
+	return d
+}
+
+func (s *Sidecar) loadPolicy(v interface{ Scan(interface{}) error }) error {
+	p := new(Policies)
+	if err := v.Scan(p); err != nil {
+		return err
+	}
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	s.Lock()
+	s.policies = p
+	s.Unlock()
+
+	return nil
+}
+
+func (s *Sidecar) watchPolicy(w config.Watcher) {
+	go func() {
+		<-s.exit
+		w.Stop()
+	}()
+
+	for {
+		v, err := w.Next()
+		if err != nil {
+			return
+		}
+		if err := s.loadPolicy(v); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("sidecar: invalid policy: %v", err)
+			}
+		}
+	}
+}
+
+// Policy returns the policy applied to requests to the service endpoint
+func (s *Sidecar) Policy(service, endpoint string) (*Policy, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.policies.Lookup(service, endpoint)
+}
+
+type policyWrapper struct {
+	client.Client
+	s *Sidecar
+}
+
+func (p *policyWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	if pol, ok := p.s.Policy(req.Service(), req.Endpoint()); ok {
+		// the caller's deadline takes precedence over the request
+		// timeout so shorten it when the policy is stricter
+		if d := pol.timeout(); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		opts = append(opts, pol.options()...)
+	}
+	return p.Client.Call(ctx, req, rsp, opts...)
+}
+
+func (p *policyWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	if pol, ok := p.s.Policy(req.Service(), req.Endpoint()); ok {
+		opts = append(opts, pol.options()...)
+	}
+	return p.Client.Stream(ctx, req, opts...)
+}
+
+// policy returns a client wrapper which applies the policy of the route
+func (s *Sidecar) policy() client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &policyWrapper{c, s}
+	}
+}
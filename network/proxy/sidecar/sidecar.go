@@ -0,0 +1,239 @@
+// Package sidecar runs the mucp proxy alongside an unmodified service.
+//
+// The inbound listener is registered under the name of the service and
+// forwards the requests of other sidecars to the local service address.
+// The outbound listener accepts requests from the local service, which
+// is run with MICRO_PROXY set to the outbound address, and resolves them
+// through the registry and router to the sidecar of the destination.
+// Traffic between sidecars is secured with the tls config, see MutualTLS.
+//
+// The local service should register with a registry other than the one
+// used by the sidecars so requests are always routed through them.
+package sidecar
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/network/proxy"
+	"github.com/asim/go-micro/v3/network/proxy/mucp"
+	"github.com/asim/go-micro/v3/network/router"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/transport"
+)
+
+var (
+	// DefaultInbound is the address other sidecars connect to
+	DefaultInbound = ":0"
+	// DefaultOutbound is the address the local service connects to
+	DefaultOutbound = proxy.DefaultEndpoint
+	// DefaultPath is the config path the policy is read from
+	DefaultPath = []string{"sidecar"}
+)
+
+// Sidecar proxies the inbound and outbound requests of a service
+type Sidecar struct {
+	opts Options
+
+	sync.RWMutex
+	policies *Policies
+	running  bool
+
+	// the router is started and stopped by the sidecar
+	// unless it was passed in
+	ownRouter bool
+
+	inbound  server.Server
+	outbound server.Server
+
+	exit chan bool
+}
+
+// NewSidecar returns a sidecar for the service set by Name
+func NewSidecar(opts ...Option) (*Sidecar, error) {
+	options := Options{
+		Inbound:  DefaultInbound,
+		Outbound: DefaultOutbound,
+		Registry: registry.DefaultRegistry,
+		Config:   config.DefaultConfig,
+		Path:     DefaultPath,
+		Stats:    stats.DefaultStats,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if len(options.Name) == 0 {
+		return nil, errors.New("sidecar: service name is blank")
+	}
+
+	s := &Sidecar{
+		opts:     options,
+		policies: new(Policies),
+		exit:     make(chan bool),
+	}
+
+	if s.opts.Router == nil {
+		s.opts.Router = router.NewRouter(router.Registry(options.Registry))
+		s.ownRouter = true
+	}
+
+	if err := s.loadPolicy(options.Config.Get(options.Path...)); err != nil {
+		return nil, err
+	}
+
+	w, err := options.Config.Watch(options.Path...)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.watchPolicy(w)
+
+	// requests from the local service are sent to the sidecar of the destination
+	out := client.NewClient(
+		client.Registry(options.Registry),
+		client.Transport(s.transport()),
+		client.Wrap(s.policy()),
+	)
+
+	s.outbound = server.NewServer(
+		server.Name(options.Name),
+		server.Version(options.Version),
+		server.Address(options.Outbound),
+		// the outbound listener is only used by the local service
+		server.Registry(registry.NewMemoryRegistry()),
+		server.Transport(transport.NewHTTPTransport()),
+		server.WithRouter(mucp.NewProxy(
+			proxy.WithClient(out),
+			proxy.WithRouter(s.opts.Router),
+		)),
+		server.WrapHandler(s.metrics(DirectionOutbound)),
+	)
+
+	// there's nothing to forward to without the local service
+	if len(options.Address) == 0 {
+		return s, nil
+	}
+
+	local := client.NewClient(
+		client.Registry(options.Registry),
+		client.Transport(transport.NewHTTPTransport()),
+	)
+
+	s.inbound = server.NewServer(
+		server.Name(options.Name),
+		server.Version(options.Version),
+		server.Address(options.Inbound),
+		server.Registry(options.Registry),
+		server.Transport(s.transport()),
+		server.WithRouter(mucp.NewProxy(
+			proxy.WithEndpoint(options.Address),
+			proxy.WithClient(local),
+			proxy.WithRouter(s.opts.Router),
+		)),
+		server.WrapHandler(s.metrics(DirectionInbound)),
+	)
+
+	return s, nil
+}
+
+// transport returns a transport for the traffic between sidecars
+func (s *Sidecar) transport() transport.Transport {
+	if s.opts.TLSConfig == nil {
+		return transport.NewHTTPTransport()
+	}
+	// the transport modifies the config so each gets a copy
+	return transport.NewHTTPTransport(
+		transport.Secure(true),
+		transport.TLSConfig(s.opts.TLSConfig.Clone()),
+	)
+}
+
+// Options returns the options of the sidecar. The listener
+// addresses are the ones bound once the sidecar is started.
+func (s *Sidecar) Options() Options {
+	s.RLock()
+	defer s.RUnlock()
+	return s.opts
+}
+
+// Start starts the router and the listeners
+func (s *Sidecar) Start() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.running {
+		return nil
+	}
+
+	if s.ownRouter {
+		if err := s.opts.Router.Start(); err != nil {
+			return err
+		}
+	}
+
+	if s.inbound != nil {
+		if err := s.inbound.Start(); err != nil {
+			return err
+		}
+		s.opts.Inbound = s.inbound.Options().Address
+	}
+
+	if err := s.outbound.Start(); err != nil {
+		if s.inbound != nil {
+			s.inbound.Stop()
+		}
+		return err
+	}
+	s.opts.Outbound = s.outbound.Options().Address
+
+	s.running = true
+
+	return nil
+}
+
+// Stop stops the listeners, draining the inbound requests
+func (s *Sidecar) Stop() error {
+	s.Lock()
+	defer s.Unlock()
+
+	select {
+	case <-s.exit:
+		return nil
+	default:
+		close(s.exit)
+	}
+
+	if !s.running {
+		return nil
+	}
+	s.running = false
+
+	var gerr error
+
+	if err := s.outbound.Stop(); err != nil {
+		gerr = err
+	}
+
+	if s.inbound != nil {
+		if err := s.inbound.Stop(); err != nil {
+			gerr = err
+		}
+	}
+
+	if s.ownRouter {
+		if err := s.opts.Router.Stop(); err != nil {
+			gerr = err
+		}
+	}
+
+	return gerr
+}
+
+func (s *Sidecar) String() string {
+	return "sidecar"
+}
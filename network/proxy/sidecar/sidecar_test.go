@@ -0,0 +1,275 @@
+package sidecar
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/config/source/memory"
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/transport"
+)
+
+const testPolicy = `{
+	"sidecar": {
+		"default": {"retries": 1, "timeout": "5s"},
+		"routes": {
+			"greeter": {"retries": 2},
+			"greeter/Greeter.Slow": {"retries": 0, "timeout": "50ms"},
+			"greeter/Greeter.Timeout": {"timeout": "1s"}
+		}
+	}
+}`
+
+type Request struct {
+	Name string `json:"name"`
+}
+
+type Response struct {
+	Msg string `json:"msg"`
+}
+
+type Greeter struct{}
+
+func (g *Greeter) Hello(ctx context.Context, req *Request, rsp *Response) error {
+	rsp.Msg = "Hello " + req.Name
+	return nil
+}
+
+func (g *Greeter) Slow(ctx context.Context, req *Request, rsp *Response) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+type value string
+
+func (v value) Scan(i interface{}) error {
+	return json.Unmarshal([]byte(v), i)
+}
+
+// testCerts returns a CA and a certificate signed by it for 127.0.0.1
+func testCerts(t *testing.T) (ca, cert, key []byte) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sidecar-ca"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "sidecar"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, caTmpl, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(leafKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return
+}
+
+func TestPolicyLookup(t *testing.T) {
+	c, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON([]byte(testPolicy)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSidecar(Name("caller"), Registry(registry.NewMemoryRegistry()), Config(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	testData := []struct {
+		service  string
+		endpoint string
+		retries  int
+		timeout  time.Duration
+	}{
+		{"greeter", "Greeter.Slow", 0, 50 * time.Millisecond},
+		{"greeter", "Greeter.Hello", 2, 0},
+		{"other", "Other.Call", 1, 5 * time.Second},
+		// the client's retries are kept
+		{"greeter", "Greeter.Timeout", 3, time.Second},
+	}
+
+	for _, d := range testData {
+		p, ok := s.Policy(d.service, d.endpoint)
+		if !ok {
+			t.Fatalf("expected a policy for %s %s", d.service, d.endpoint)
+		}
+		opts := client.CallOptions{Retries: 3}
+		for _, o := range p.options() {
+			o(&opts)
+		}
+		if opts.Retries != d.retries || p.timeout() != d.timeout {
+			t.Fatalf("%s %s: expected %d retries %v timeout, got %+v", d.service, d.endpoint, d.retries, d.timeout, p)
+		}
+	}
+
+	// invalid timeouts are rejected and the policy is kept
+	if err := s.loadPolicy(value(`{"default": {"timeout": "soon"}}`)); err == nil {
+		t.Fatal("expected an invalid timeout to be rejected")
+	}
+	if p, _ := s.Policy("other", "Other.Call"); p.timeout() != 5*time.Second {
+		t.Fatalf("expected the policy to be kept, got %+v", p)
+	}
+}
+
+func TestSidecar(t *testing.T) {
+	ca, cert, key := testCerts(t)
+	tlsConfig, err := MutualTLS(ca, cert, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON([]byte(testPolicy)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unmodified service registers with its own registry
+	app := server.NewServer(
+		server.Name("greeter"),
+		server.Address("127.0.0.1:0"),
+		server.Registry(registry.NewMemoryRegistry()),
+		server.Transport(transport.NewHTTPTransport()),
+	)
+	if err := app.Handle(app.NewHandler(&Greeter{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop()
+
+	mesh := registry.NewMemoryRegistry()
+	st := stats.NewStats()
+
+	greeter, err := NewSidecar(
+		Name("greeter"),
+		Address(app.Options().Address),
+		Inbound("127.0.0.1:0"),
+		Outbound("127.0.0.1:0"),
+		Registry(mesh),
+		TLSConfig(tlsConfig),
+		Config(c),
+		Stats(st),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := greeter.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer greeter.Stop()
+
+	caller, err := NewSidecar(
+		Name("caller"),
+		Outbound("127.0.0.1:0"),
+		Registry(mesh),
+		TLSConfig(tlsConfig),
+		Config(c),
+		Stats(st),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := caller.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer caller.Stop()
+
+	// the calling service sends its requests to its sidecar
+	cl := client.NewClient(
+		client.Registry(registry.NewMemoryRegistry()),
+		client.Transport(transport.NewHTTPTransport()),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+	outbound := client.WithAddress(caller.Options().Outbound)
+
+	rsp := new(Response)
+	req := cl.NewRequest("greeter", "Greeter.Hello", &Request{Name: "John"})
+	if err := cl.Call(context.TODO(), req, rsp, outbound); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Msg != "Hello John" {
+		t.Fatalf("unexpected response %q", rsp.Msg)
+	}
+
+	// the route timeout applies
+	start := time.Now()
+	req = cl.NewRequest("greeter", "Greeter.Slow", &Request{})
+	if err := cl.Call(context.TODO(), req, new(Response), outbound); err == nil {
+		t.Fatal("expected the slow request to time out")
+	}
+	if d := time.Since(start); d >= time.Second {
+		t.Fatalf("expected the policy timeout, took %v", d)
+	}
+
+	// the inbound listener requires a client certificate
+	plain := client.NewClient(
+		client.Registry(registry.NewMemoryRegistry()),
+		client.Transport(transport.NewHTTPTransport(transport.Secure(true))),
+		client.ContentType("application/json"),
+		client.Retries(0),
+	)
+	req = plain.NewRequest("greeter", "Greeter.Hello", &Request{Name: "John"})
+	if err := plain.Call(context.TODO(), req, new(Response), client.WithAddress(greeter.Options().Inbound)); err == nil {
+		t.Fatal("expected a request without a client certificate to fail")
+	}
+
+	stat, err := st.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counters := stat[0].Counters
+	for _, direction := range []string{DirectionOutbound, DirectionInbound} {
+		if n := counters[Metric(direction, "greeter", "Greeter.Hello", "requests")]; n != 1 {
+			t.Fatalf("expected 1 %s request, got %d", direction, n)
+		}
+	}
+	if n := counters[Metric(DirectionOutbound, "greeter", "Greeter.Slow", "errors")]; n != 1 {
+		t.Fatalf("expected 1 outbound error, got %d", n)
+	}
+}
@@ -0,0 +1,31 @@
+package sidecar
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// MutualTLS returns a tls config which presents the PEM encoded
+// certificate and requires peers to present one signed by the CA
+func MutualTLS(ca, cert, key []byte) (*tls.Config, error) {
+	pair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("sidecar: invalid CA certificate")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		// verify the server when dialing
+		RootCAs: pool,
+		// verify the client when accepting
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
@@ -182,6 +182,17 @@ func (r *router) watchRegistry(w registry.Watcher) error {
 			break
 		}
 
+		// events were missed so reload the routes from the registry
+		if res.Action == registry.ResyncAction {
+			if err := r.manageRegistryRoutes(r.options.Registry, "update"); err != nil {
+				return err
+			}
+			continue
+		}
+		if res.Service == nil {
+			continue
+		}
+
 		if err := r.manageRoutes(res.Service, res.Action); err != nil {
 			return err
 		}
@@ -17,6 +17,7 @@ import (
 	"github.com/asim/go-micro/v3/server"
 	"github.com/asim/go-micro/v3/store"
 	"github.com/asim/go-micro/v3/transport"
+	"github.com/asim/go-micro/v3/util/cloudevents"
 	"github.com/micro/cli/v2"
 )
 
@@ -257,6 +258,34 @@ func RegisterInterval(t time.Duration) Option {
 	}
 }
 
+// DrainTimeout 指定服务停止时排空请求的最长时间
+func DrainTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.Server.Init(server.DrainTimeout(t))
+	}
+}
+
+// LivenessCheck 添加一个存活检查，失败时节点在注册中心的状态为 critical
+func LivenessCheck(name string, fn func(context.Context) error) Option {
+	return func(o *Options) {
+		o.Server.Init(server.LivenessCheck(name, fn))
+	}
+}
+
+// ReadinessCheck 添加一个就绪检查，失败时节点在注册中心的状态为 warning
+func ReadinessCheck(name string, fn func(context.Context) error) Option {
+	return func(o *Options) {
+		o.Server.Init(server.ReadinessCheck(name, fn))
+	}
+}
+
+// CloudEvents 将发布的消息编码为 CloudEvents，id、source、type 和 time 分别取自消息 ID、服务名称、主题和发布时间
+func CloudEvents(mode cloudevents.Mode) Option {
+	return func(o *Options) {
+		o.Client.Init(client.CloudEvents(mode, o.Client.Options().EventSource))
+	}
+}
+
 // WrapClient 是一种用中间件包装 Client 的方式。可以提供包装器的列表。包装器器是按照先进后出方式执行的，因此最后一个包装器是最后执行的。
 func WrapClient(w ...client.Wrapper) Option {
 	return func(o *Options) {
@@ -7,3 +7,5 @@ require (
 	github.com/asim/go-micro/v3 v3.0.0-20210120135431-d94936f6c97c
 	github.com/google/uuid v1.1.1
 )
+
+replace github.com/asim/go-micro/v3 => ../../..
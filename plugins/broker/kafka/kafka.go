@@ -162,10 +162,24 @@ func (k *kBroker) Publish(topic string, msg *broker.Message, opts ...broker.Publ
 	if err != nil {
 		return err
 	}
-	_, _, err = k.p.SendMessage(&sarama.ProducerMessage{
+
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	pm := &sarama.ProducerMessage{
 		Topic: topic,
 		Value: sarama.ByteEncoder(b),
-	})
+	}
+
+	// the key picks the partition which is consumed
+	// in order by one member of the consumer group
+	if len(options.OrderingKey) > 0 {
+		pm.Key = sarama.StringEncoder(options.OrderingKey)
+	}
+
+	_, _, err = k.p.SendMessage(pm)
 	return err
 }
 
@@ -6,3 +6,5 @@ require (
 	github.com/google/uuid v1.1.2
 	github.com/asim/go-micro/v3 v3.0.0-20210120135431-d94936f6c97c
 )
+
+replace github.com/asim/go-micro/v3 => ../../..
@@ -4,6 +4,7 @@ package memory
 import (
 	"context"
 	"errors"
+	"hash/crc32"
 	"math/rand"
 	"sync"
 	"time"
@@ -27,6 +28,15 @@ type memoryBroker struct {
 	sync.RWMutex
 	connected   bool
 	Subscribers map[string][]*memorySubscriber
+
+	// locks held while handling the messages of an ordering key
+	kmtx sync.Mutex
+	keys map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
 }
 
 type memoryEvent struct {
@@ -96,6 +106,24 @@ func (m *memoryBroker) Init(opts ...broker.Option) error {
 }
 
 func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// publish delayed messages once they're due
+	if d := time.Until(options.DeliverAt); d > 0 {
+		key := options.OrderingKey
+		time.AfterFunc(d, func() {
+			if err := m.Publish(topic, msg, broker.OrderingKey(key)); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("[memory]: failed to publish delayed message: %v", err)
+				}
+			}
+		})
+		return nil
+	}
+
 	m.RLock()
 	if !m.connected {
 		m.RUnlock()
@@ -125,6 +153,24 @@ func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker
 		opts:    m.opts,
 	}
 
+	// handle the messages of a key one at a time
+	key := options.OrderingKey
+	if len(key) > 0 {
+		p.message = withKey(msg, key)
+		if m.opts.Codec != nil {
+			buf, err := m.opts.Codec.Marshal(p.message)
+			if err != nil {
+				return err
+			}
+			p.message = buf
+		}
+		unlock := m.lock(topic + "\x00" + key)
+		defer unlock()
+
+		// keyed messages go to one subscriber of each queue
+		subs = pick(subs, key)
+	}
+
 	for _, sub := range subs {
 		if err := sub.handler(p); err != nil {
 			p.err = err
@@ -139,6 +185,71 @@ func (m *memoryBroker) Publish(topic string, msg *broker.Message, opts ...broker
 	return nil
 }
 
+// lock waits for the key and returns the func to release it
+func (m *memoryBroker) lock(key string) func() {
+	m.kmtx.Lock()
+	l, ok := m.keys[key]
+	if !ok {
+		l = new(keyLock)
+		m.keys[key] = l
+	}
+	l.refs++
+	m.kmtx.Unlock()
+
+	l.Lock()
+
+	return func() {
+		l.Unlock()
+
+		m.kmtx.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.keys, key)
+		}
+		m.kmtx.Unlock()
+	}
+}
+
+// withKey returns a copy of the message with the ordering key header set
+func withKey(msg *broker.Message, key string) *broker.Message {
+	header := make(map[string]string, len(msg.Header)+1)
+	for k, v := range msg.Header {
+		header[k] = v
+	}
+	header[broker.OrderingKeyHeader] = key
+	return &broker.Message{
+		Header: header,
+		Body:   msg.Body,
+	}
+}
+
+// pick returns the subscribers to deliver a keyed message to. Each subscriber
+// without a queue gets the message and one of each queue does, chosen by the key.
+func pick(subs []*memorySubscriber, key string) []*memorySubscriber {
+	var picked []*memorySubscriber
+	queues := make(map[string][]*memorySubscriber)
+	var order []string
+
+	for _, sub := range subs {
+		q := sub.opts.Queue
+		if len(q) == 0 {
+			picked = append(picked, sub)
+			continue
+		}
+		if _, ok := queues[q]; !ok {
+			order = append(order, q)
+		}
+		queues[q] = append(queues[q], sub)
+	}
+
+	for _, q := range order {
+		subs := queues[q]
+		picked = append(picked, subs[crc32.ChecksumIEEE([]byte(key))%uint32(len(subs))])
+	}
+
+	return picked
+}
+
 func (m *memoryBroker) Subscribe(topic string, handler broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
 	m.RLock()
 	if !m.connected {
@@ -241,5 +352,6 @@ func NewBroker(opts ...broker.Option) broker.Broker {
 	return &memoryBroker{
 		opts:        options,
 		Subscribers: make(map[string][]*memorySubscriber),
+		keys:        make(map[string]*keyLock),
 	}
 }
@@ -2,7 +2,9 @@ package memory
 
 import (
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/asim/go-micro/v3/broker"
 )
@@ -48,3 +50,142 @@ func TestMemoryBroker(t *testing.T) {
 		t.Fatalf("Unexpected connect error %v", err)
 	}
 }
+
+func TestMemoryBrokerOrderingKey(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	var mtx sync.Mutex
+	received := make(map[string][]string)
+	handlers := make(map[string]int)
+	inflight := make(map[string]bool)
+
+	handler := func(i int) broker.Handler {
+		return func(p broker.Event) error {
+			key := p.Message().Header[broker.OrderingKeyHeader]
+
+			mtx.Lock()
+			if inflight[key] {
+				t.Errorf("Unexpected message in flight for key %s", key)
+			}
+			inflight[key] = true
+			if h, ok := handlers[key]; ok && h != i {
+				t.Errorf("Expected key %s to be handled by subscriber %d, got %d", key, h, i)
+			}
+			handlers[key] = i
+			mtx.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mtx.Lock()
+			inflight[key] = false
+			received[key] = append(received[key], string(p.Message().Body))
+			mtx.Unlock()
+			return nil
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.Subscribe("test", handler(i), broker.Queue("queue")); err != nil {
+			t.Fatalf("Unexpected error subscribing %v", err)
+		}
+	}
+
+	// publish each key from several goroutines
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c"} {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				for n := 0; n < 5; n++ {
+					msg := &broker.Message{Body: []byte(fmt.Sprintf("%d", n))}
+					if err := b.Publish("test", msg, broker.OrderingKey(key)); err != nil {
+						t.Errorf("Unexpected error publishing %v", err)
+					}
+				}
+			}(key)
+		}
+	}
+	wg.Wait()
+
+	for key, msgs := range received {
+		if len(msgs) != 15 {
+			t.Fatalf("Expected 15 messages for key %s got %d", key, len(msgs))
+		}
+	}
+}
+
+func TestMemoryBrokerDelay(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	received := make(chan time.Time, 1)
+	if _, err := b.Subscribe("test", func(p broker.Event) error {
+		received <- time.Now()
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error subscribing %v", err)
+	}
+
+	start := time.Now()
+	if err := b.Publish("test", &broker.Message{Body: []byte("later")}, broker.Delay(50*time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error publishing %v", err)
+	}
+
+	select {
+	case at := <-received:
+		if d := at.Sub(start); d < 50*time.Millisecond {
+			t.Fatalf("Expected the message to be delayed, got %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the delayed message")
+	}
+}
+
+func TestMemoryBrokerUnkeyed(t *testing.T) {
+	b := NewBroker()
+
+	if err := b.Connect(); err != nil {
+		t.Fatalf("Unexpected connect error %v", err)
+	}
+	defer b.Disconnect()
+
+	var mtx sync.Mutex
+	received := make(map[int]int)
+
+	for i := 0; i < 2; i++ {
+		i := i
+		if _, err := b.Subscribe("test", func(p broker.Event) error {
+			mtx.Lock()
+			received[i]++
+			mtx.Unlock()
+			return nil
+		}, broker.Queue("queue")); err != nil {
+			t.Fatalf("Unexpected error subscribing %v", err)
+		}
+	}
+
+	// messages without a key are delivered to every subscriber as before
+	for n := 0; n < 5; n++ {
+		if err := b.Publish("test", &broker.Message{Body: []byte("foo")}); err != nil {
+			t.Fatalf("Unexpected error publishing %v", err)
+		}
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for i := 0; i < 2; i++ {
+		if received[i] != 5 {
+			t.Fatalf("Expected subscriber %d to receive 5 messages got %d", i, received[i])
+		}
+	}
+}
@@ -6,3 +6,5 @@ require (
 	github.com/asim/go-micro/v3 v3.0.0-20210120135431-d94936f6c97c
 	github.com/nats-io/nats.go v1.10.0
 )
+
+replace github.com/asim/go-micro/v3 => ../../..
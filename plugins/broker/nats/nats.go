@@ -180,6 +180,24 @@ func (n *natsBroker) Publish(topic string, msg *broker.Message, opts ...broker.P
 		return errors.New("not connected")
 	}
 
+	var options broker.PublishOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// ordering keys are unsupported, nats has no partitions so the
+	// key is only passed on. Each subscription handles its messages
+	// one at a time in the order they arrive but members of a queue
+	// group may handle messages of the same key at once.
+	if key := options.OrderingKey; len(key) > 0 {
+		header := make(map[string]string, len(msg.Header)+1)
+		for k, v := range msg.Header {
+			header[k] = v
+		}
+		header[broker.OrderingKeyHeader] = key
+		msg = &broker.Message{Header: header, Body: msg.Body}
+	}
+
 	b, err := n.opts.Codec.Marshal(msg)
 	if err != nil {
 		return err
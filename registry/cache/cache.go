@@ -17,13 +17,31 @@ import (
 type Cache interface {
 	// embed the registry interface
 	registry.Registry
+	// Status of the cached service
+	Status(service string) (*Status, bool)
 	// stop the cache watcher
 	Stop()
 }
 
+// Status describes how fresh a cached service is
+type Status struct {
+	// Updated is when the service was last read from the registry
+	Updated time.Time
+	// Stale is true once the TTL has expired
+	Stale bool
+	// Snapshot is true when loaded from a snapshot and not yet refreshed
+	Snapshot bool
+	// Error is the last registry error while stale data is being served
+	Error error
+}
+
 type Options struct {
 	// TTL is the cache TTL
 	TTL time.Duration
+	// Snapshot persists the cache across restarts
+	Snapshot Snapshot
+	// MaxStale is the max age of stale services returned on registry failure
+	MaxStale time.Duration
 }
 
 type Option func(o *Options)
@@ -37,6 +55,15 @@ type cache struct {
 	cache   map[string][]*registry.Service
 	ttls    map[string]time.Time
 	watched map[string]bool
+	updated map[string]time.Time
+	// services loaded from the snapshot
+	loaded map[string]bool
+
+	// signals the snapshot needs saving
+	save chan bool
+
+	// revision of the last watch result to resume from
+	revision uint64
 
 	// used to stop the cache
 	exit chan bool
@@ -112,6 +139,17 @@ func (c *cache) del(service string) {
 	// otherwise delete entries
 	delete(c.cache, service)
 	delete(c.ttls, service)
+	delete(c.updated, service)
+	delete(c.loaded, service)
+	c.persist()
+}
+
+// isExpired checks if stale services are too old to be returned
+func (c *cache) isExpired(updated time.Time) bool {
+	if c.opts.MaxStale <= 0 {
+		return false
+	}
+	return time.Since(updated) > c.opts.MaxStale
 }
 
 func (c *cache) get(service string) ([]*registry.Service, error) {
@@ -122,6 +160,8 @@ func (c *cache) get(service string) ([]*registry.Service, error) {
 	services := c.cache[service]
 	// get cache ttl
 	ttl := c.ttls[service]
+	// when it was last updated
+	updated := c.updated[service]
 	// make a copy
 	cp := util.Copy(services)
 
@@ -141,7 +181,7 @@ func (c *cache) get(service string) ([]*registry.Service, error) {
 		services, _ := val.([]*registry.Service)
 		if err != nil {
 			// check the cache
-			if len(cached) > 0 {
+			if len(cached) > 0 && !c.isExpired(updated) {
 				// set the error status
 				c.setStatus(err)
 
@@ -193,16 +233,103 @@ func (c *cache) get(service string) ([]*registry.Service, error) {
 func (c *cache) set(service string, services []*registry.Service) {
 	c.cache[service] = services
 	c.ttls[service] = time.Now().Add(c.opts.TTL)
+	c.updated[service] = time.Now()
+	delete(c.loaded, service)
+	c.persist()
+}
+
+// persist signals the snapshot loop to save the cache
+func (c *cache) persist() {
+	if c.save == nil {
+		return
+	}
+	select {
+	case c.save <- true:
+	default:
+	}
+}
+
+// load populates the cache from the snapshot. Services are
+// loaded expired so the registry is always asked first.
+func (c *cache) load() {
+	entries, err := c.opts.Snapshot.Load()
+	if err != nil {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("rcache: failed to load %s snapshot: %v", c.opts.Snapshot.String(), err)
+		}
+		return
+	}
+
+	for service, e := range entries {
+		if e == nil || len(e.Services) == 0 || c.isExpired(e.Updated) {
+			continue
+		}
+		c.cache[service] = e.Services
+		c.updated[service] = e.Updated
+		c.loaded[service] = true
+	}
+}
+
+// snapshot saves the cache whenever it changes
+func (c *cache) snapshot() {
+	for {
+		select {
+		case <-c.exit:
+			// flush any pending update before exiting
+			select {
+			case <-c.save:
+				c.saveSnapshot()
+			default:
+			}
+			return
+		case <-c.save:
+			c.saveSnapshot()
+		}
+	}
+}
+
+func (c *cache) saveSnapshot() {
+	c.RLock()
+	entries := make(map[string]*Entry, len(c.cache))
+	for service, services := range c.cache {
+		entries[service] = &Entry{
+			Services: util.Copy(services),
+			Updated:  c.updated[service],
+		}
+	}
+	c.RUnlock()
+
+	if err := c.opts.Snapshot.Save(entries); err != nil {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("rcache: failed to save %s snapshot: %v", c.opts.Snapshot.String(), err)
+		}
+	}
 }
 
 func (c *cache) update(res *registry.Result) {
-	if res == nil || res.Service == nil {
+	if res == nil {
 		return
 	}
 
 	c.Lock()
 	defer c.Unlock()
 
+	if res.Revision > 0 {
+		c.revision = res.Revision
+	}
+
+	// events were missed so everything is refreshed on next lookup
+	if res.Action == registry.ResyncAction {
+		for service := range c.ttls {
+			c.ttls[service] = time.Time{}
+		}
+		return
+	}
+
+	if res.Service == nil {
+		return
+	}
+
 	// only save watched services
 	if _, ok := c.watched[res.Service.Name]; !ok {
 		return
@@ -341,8 +468,12 @@ func (c *cache) run() {
 		j := rand.Int63n(100)
 		time.Sleep(time.Duration(j) * time.Millisecond)
 
-		// create new watcher
-		w, err := c.Registry.Watch()
+		// create new watcher resuming from the last revision seen
+		c.RLock()
+		rev := c.revision
+		c.RUnlock()
+
+		w, err := c.Registry.Watch(registry.WatchRevision(rev))
 		if err != nil {
 			if c.quit() {
 				return
@@ -447,6 +578,24 @@ func (c *cache) GetService(service string, opts ...registry.GetOption) ([]*regis
 	return services, nil
 }
 
+func (c *cache) Status(service string) (*Status, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	if _, ok := c.cache[service]; !ok {
+		return nil, false
+	}
+
+	ttl := c.ttls[service]
+
+	return &Status{
+		Updated:  c.updated[service],
+		Stale:    ttl.IsZero() || time.Since(ttl) > 0,
+		Snapshot: c.loaded[service],
+		Error:    c.status,
+	}, true
+}
+
 func (c *cache) Stop() {
 	c.Lock()
 	defer c.Unlock()
@@ -474,12 +623,22 @@ func New(r registry.Registry, opts ...Option) Cache {
 		o(&options)
 	}
 
-	return &cache{
+	c := &cache{
 		Registry: r,
 		opts:     options,
 		watched:  make(map[string]bool),
 		cache:    make(map[string][]*registry.Service),
 		ttls:     make(map[string]time.Time),
+		updated:  make(map[string]time.Time),
+		loaded:   make(map[string]bool),
 		exit:     make(chan bool),
 	}
+
+	if options.Snapshot != nil {
+		c.save = make(chan bool, 1)
+		c.load()
+		go c.snapshot()
+	}
+
+	return c
 }
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+var errUnavailable = errors.New("registry unavailable")
+
+// downRegistry fails every lookup as if the registry can't be reached
+type downRegistry struct {
+	registry.Registry
+}
+
+func (d *downRegistry) GetService(string, ...registry.GetOption) ([]*registry.Service, error) {
+	return nil, errUnavailable
+}
+
+func (d *downRegistry) Watch(...registry.WatchOption) (registry.Watcher, error) {
+	return nil, errUnavailable
+}
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	r := registry.NewMemoryRegistry()
+	if err := r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "foo-1", Address: "localhost:9999"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(r, WithSnapshot(NewFileSnapshot(path)))
+	if _, err := c.GetService("foo"); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop()
+
+	// wait for the snapshot to be written
+	snap := NewFileSnapshot(path)
+	var entries map[string]*Entry
+	for i := 0; i < 50 && len(entries) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		entries, _ = snap.Load()
+	}
+	if len(entries) != 1 || entries["foo"] == nil {
+		t.Fatalf("expected foo in snapshot, got %v", entries)
+	}
+
+	// start with the registry down
+	c = New(&downRegistry{r}, WithSnapshot(snap))
+	defer c.Stop()
+
+	services, err := c.GetService("foo")
+	if err != nil {
+		t.Fatalf("expected stale services from snapshot, got %v", err)
+	}
+	if len(services) != 1 || services[0].Nodes[0].Id != "foo-1" {
+		t.Fatalf("unexpected services %v", services)
+	}
+
+	status, ok := c.Status("foo")
+	if !ok {
+		t.Fatal("expected status for foo")
+	}
+	if !status.Stale || !status.Snapshot || status.Error != errUnavailable {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if _, err := c.GetService("bar"); err == nil {
+		t.Fatal("expected error for uncached service")
+	}
+
+	// snapshot data older than the max stale age isn't used
+	entries["foo"].Updated = time.Now().Add(-time.Hour)
+	if err := snap.Save(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	old := New(&downRegistry{r}, WithSnapshot(snap), WithMaxStale(time.Minute))
+	defer old.Stop()
+
+	if _, err := old.GetService("foo"); err != errUnavailable {
+		t.Fatalf("expected %v, got %v", errUnavailable, err)
+	}
+}
@@ -10,3 +10,19 @@ func WithTTL(t time.Duration) Option {
 		o.TTL = t
 	}
 }
+
+// WithSnapshot persists the cache on every update and loads it at
+// startup so services can be called while the registry is unavailable
+func WithSnapshot(s Snapshot) Option {
+	return func(o *Options) {
+		o.Snapshot = s
+	}
+}
+
+// WithMaxStale sets the max age of stale services returned when the
+// registry can't be reached. Zero serves stale services forever.
+func WithMaxStale(d time.Duration) Option {
+	return func(o *Options) {
+		o.MaxStale = d
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// Entry is a cached service persisted in a snapshot
+type Entry struct {
+	// Services are the cached versions of the service
+	Services []*registry.Service `json:"services"`
+	// Updated is when the services were last read from the registry
+	Updated time.Time `json:"updated"`
+}
+
+// Snapshot persists the cache so it can be loaded at startup
+type Snapshot interface {
+	// Load the last saved entries
+	Load() (map[string]*Entry, error)
+	// Save the entries replacing what was there before
+	Save(map[string]*Entry) error
+	// Snapshot implementation
+	String() string
+}
+
+type fileSnapshot struct {
+	path string
+}
+
+func (f *fileSnapshot) Load() (map[string]*Entry, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]*Entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (f *fileSnapshot) Save(entries map[string]*Entry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	// write to a temp file and rename so a crash never leaves a partial snapshot
+	tmp, err := ioutil.TempFile(dir, filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *fileSnapshot) String() string {
+	return "file"
+}
+
+// NewFileSnapshot returns a snapshot saved to the file at path
+func NewFileSnapshot(path string) Snapshot {
+	return &fileSnapshot{path: path}
+}
@@ -0,0 +1,55 @@
+// Package store saves a registry cache snapshot in a store.Store
+package store
+
+import (
+	"encoding/json"
+
+	"github.com/asim/go-micro/v3/registry/cache"
+	"github.com/asim/go-micro/v3/store"
+)
+
+var (
+	// DefaultKey is the store key the snapshot is written to
+	DefaultKey = "registry/cache/snapshot"
+)
+
+type storeSnapshot struct {
+	store store.Store
+	key   string
+}
+
+func (s *storeSnapshot) Load() (map[string]*cache.Entry, error) {
+	recs, err := s.store.Read(s.key)
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]*cache.Entry
+	if err := json.Unmarshal(recs[0].Value, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *storeSnapshot) Save(entries map[string]*cache.Entry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return s.store.Write(&store.Record{Key: s.key, Value: b})
+}
+
+func (s *storeSnapshot) String() string {
+	return "store"
+}
+
+// NewSnapshot returns a snapshot saved in the store under
+// key, or DefaultKey when key is empty
+func NewSnapshot(s store.Store, key string) cache.Snapshot {
+	if len(key) == 0 {
+		key = DefaultKey
+	}
+	return &storeSnapshot{store: s, key: key}
+}
@@ -0,0 +1,345 @@
+// Package federation mirrors services from one registry into another
+package federation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/util/backoff"
+)
+
+var (
+	// OriginKey is the node metadata key recording the cluster a
+	// mirrored node came from. Nodes with it are never exported
+	// again which prevents federation loops.
+	OriginKey = "federation_origin"
+
+	DefaultName     = "default"
+	DefaultTTL      = time.Second * 90
+	DefaultInterval = time.Second * 30
+)
+
+// Federation watches a source registry and mirrors the services
+// allowed by the export and import rules into a target registry
+type Federation struct {
+	opts   Options
+	source registry.Registry
+	target registry.Registry
+
+	sync.Mutex
+	// mirrored services by name
+	mirrored map[string][]*registry.Service
+	running  bool
+	exit     chan bool
+}
+
+// NewFederation returns a federation from source to target
+func NewFederation(source, target registry.Registry, opts ...Option) *Federation {
+	options := Options{
+		Name:     DefaultName,
+		TTL:      DefaultTTL,
+		Interval: DefaultInterval,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Federation{
+		opts:     options,
+		source:   source,
+		target:   target,
+		mirrored: make(map[string][]*registry.Service),
+	}
+}
+
+// Options returns the federation options
+func (f *Federation) Options() Options {
+	return f.opts
+}
+
+// Start mirroring services
+func (f *Federation) Start() error {
+	f.Lock()
+	if f.running {
+		f.Unlock()
+		return nil
+	}
+	f.running = true
+	f.exit = make(chan bool)
+	exit := f.exit
+	f.Unlock()
+
+	if err := f.Sync(); err != nil {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("federation: %s initial sync error: %v", f.opts.Name, err)
+		}
+	}
+
+	go f.watch(exit)
+	go f.refresh(exit)
+
+	return nil
+}
+
+// Stop mirroring and deregister the mirrored services from the target
+func (f *Federation) Stop() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if !f.running {
+		return nil
+	}
+
+	f.running = false
+	close(f.exit)
+
+	for name, services := range f.mirrored {
+		for _, s := range services {
+			f.target.Deregister(s)
+		}
+		delete(f.mirrored, name)
+	}
+
+	return nil
+}
+
+// Sync mirrors every service in the source
+func (f *Federation) Sync() error {
+	services, err := f.source.ListServices()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, s := range services {
+		names[s.Name] = true
+	}
+
+	// include services we mirrored which may have gone
+	f.Lock()
+	for name := range f.mirrored {
+		names[name] = true
+	}
+	f.Unlock()
+
+	var gerr error
+	for name := range names {
+		if err := f.syncService(name); err != nil {
+			gerr = err
+		}
+	}
+
+	return gerr
+}
+
+// syncService mirrors the current state of the named service
+func (f *Federation) syncService(name string) error {
+	services, err := f.source.GetService(name)
+	if err != nil && err != registry.ErrNotFound {
+		return err
+	}
+
+	exported := f.export(services)
+
+	f.Lock()
+	defer f.Unlock()
+
+	// stopped while reading the source
+	if !f.running {
+		return nil
+	}
+
+	var gerr error
+
+	for _, s := range exported {
+		if err := f.target.Register(s, registry.RegisterTTL(f.opts.TTL)); err != nil {
+			gerr = err
+		}
+	}
+
+	// deregister nodes which are no longer exported
+	for _, s := range removed(f.mirrored[name], exported) {
+		if err := f.target.Deregister(s); err != nil {
+			gerr = err
+		}
+	}
+
+	if len(exported) > 0 {
+		f.mirrored[name] = exported
+	} else {
+		delete(f.mirrored, name)
+	}
+
+	return gerr
+}
+
+// export applies the rules and returns copies of
+// the services with the allowed nodes rewritten
+func (f *Federation) export(services []*registry.Service) []*registry.Service {
+	var exported []*registry.Service
+
+	for _, s := range services {
+		var nodes []*registry.Node
+
+		for _, n := range s.Nodes {
+			// never export a node mirrored from another cluster
+			if _, ok := n.Metadata[OriginKey]; ok {
+				continue
+			}
+
+			ok, exp := apply(f.opts.Export, s, n)
+			if !ok {
+				continue
+			}
+			ok, imp := apply(f.opts.Import, s, n)
+			if !ok {
+				continue
+			}
+
+			node := &registry.Node{
+				Id:       n.Id,
+				Address:  n.Address,
+				Metadata: make(map[string]string, len(n.Metadata)+1),
+			}
+			for k, v := range n.Metadata {
+				node.Metadata[k] = v
+			}
+			node.Metadata[OriginKey] = f.opts.Name
+
+			if exp != nil && len(exp.Address) > 0 {
+				node.Address = exp.Address
+			}
+			if imp != nil && len(imp.Address) > 0 {
+				node.Address = imp.Address
+			}
+
+			nodes = append(nodes, node)
+		}
+
+		if len(nodes) == 0 {
+			continue
+		}
+
+		metadata := make(map[string]string, len(s.Metadata))
+		for k, v := range s.Metadata {
+			metadata[k] = v
+		}
+
+		exported = append(exported, &registry.Service{
+			Name:      s.Name,
+			Version:   s.Version,
+			Metadata:  metadata,
+			Endpoints: s.Endpoints,
+			Nodes:     nodes,
+		})
+	}
+
+	return exported
+}
+
+// removed returns the nodes in old which aren't in cur grouped by service version
+func removed(old, cur []*registry.Service) []*registry.Service {
+	seen := make(map[string]bool)
+	for _, s := range cur {
+		for _, n := range s.Nodes {
+			seen[s.Version+"/"+n.Id] = true
+		}
+	}
+
+	var services []*registry.Service
+	for _, s := range old {
+		var nodes []*registry.Node
+		for _, n := range s.Nodes {
+			if !seen[s.Version+"/"+n.Id] {
+				nodes = append(nodes, n)
+			}
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+		srv := new(registry.Service)
+		*srv = *s
+		srv.Nodes = nodes
+		services = append(services, srv)
+	}
+
+	return services
+}
+
+// watch resyncs services as the source changes
+func (f *Federation) watch(exit chan bool) {
+	var attempts int
+
+	for {
+		select {
+		case <-exit:
+			return
+		default:
+		}
+
+		w, err := f.source.Watch()
+		if err != nil {
+			attempts++
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("federation: %s watch error: %v", f.opts.Name, err)
+			}
+			select {
+			case <-exit:
+				return
+			case <-time.After(backoff.Do(attempts)):
+			}
+			continue
+		}
+
+		attempts = 0
+
+		// stop the watcher on exit
+		done := make(chan bool)
+		go func() {
+			select {
+			case <-exit:
+			case <-done:
+			}
+			w.Stop()
+		}()
+
+		for {
+			res, err := w.Next()
+			if err != nil {
+				break
+			}
+			if res.Service == nil {
+				continue
+			}
+			if err := f.syncService(res.Service.Name); err != nil {
+				if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+					logger.Warnf("federation: %s sync %s error: %v", f.opts.Name, res.Service.Name, err)
+				}
+			}
+		}
+
+		close(done)
+	}
+}
+
+// refresh resyncs everything on the interval to keep the TTLs alive
+func (f *Federation) refresh(exit chan bool) {
+	t := time.NewTicker(f.opts.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-t.C:
+			if err := f.Sync(); err != nil {
+				if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+					logger.Warnf("federation: %s sync error: %v", f.opts.Name, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package federation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func register(t *testing.T, r registry.Registry, name, version, id string, md map[string]string) {
+	err := r.Register(&registry.Service{
+		Name:    name,
+		Version: version,
+		Nodes: []*registry.Node{
+			{Id: id, Address: "10.0.0.1:8080", Metadata: md},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func nodes(r registry.Registry, name string) map[string]*registry.Node {
+	services, _ := r.GetService(name)
+	nodes := make(map[string]*registry.Node)
+	for _, s := range services {
+		for _, n := range s.Nodes {
+			nodes[n.Id] = n
+		}
+	}
+	return nodes
+}
+
+func TestFederation(t *testing.T) {
+	source := registry.NewMemoryRegistry()
+	target := registry.NewMemoryRegistry()
+
+	register(t, source, "go.micro.srv.foo", "1.0.0", "foo-1", map[string]string{"export": "true"})
+	register(t, source, "go.micro.srv.foo", "1.0.0", "foo-2", map[string]string{"export": "false"})
+	register(t, source, "go.micro.srv.bar", "1.0.0", "bar-1", map[string]string{"export": "true"})
+	register(t, source, "go.micro.api", "1.0.0", "api-1", map[string]string{"export": "true"})
+
+	// a node mirrored from another cluster
+	register(t, source, "go.micro.srv.baz", "1.0.0", "baz-1", map[string]string{OriginKey: "other"})
+
+	f := NewFederation(source, target,
+		Name("east"),
+		Export(
+			Rule{Service: "go.micro.srv.bar", Deny: true},
+			Rule{Service: "go.micro.srv.*", Metadata: map[string]string{"export": "true"}, Address: "gateway:443"},
+		),
+		Interval(50*time.Millisecond),
+	)
+	if err := f.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	foo := nodes(target, "go.micro.srv.foo")
+	if len(foo) != 1 || foo["foo-1"] == nil {
+		t.Fatalf("expected foo-1 to be exported, got %v", foo)
+	}
+	if foo["foo-1"].Address != "gateway:443" {
+		t.Fatalf("expected gateway address, got %s", foo["foo-1"].Address)
+	}
+	if foo["foo-1"].Metadata[OriginKey] != "east" {
+		t.Fatalf("expected origin east, got %s", foo["foo-1"].Metadata[OriginKey])
+	}
+
+	for _, name := range []string{"go.micro.srv.bar", "go.micro.api", "go.micro.srv.baz"} {
+		if n := nodes(target, name); len(n) > 0 {
+			t.Fatalf("expected %s not to be exported, got %v", name, n)
+		}
+	}
+
+	// new nodes are mirrored as they're registered
+	register(t, source, "go.micro.srv.foo", "1.0.0", "foo-3", map[string]string{"export": "true"})
+	waitFor(t, func() bool { return nodes(target, "go.micro.srv.foo")["foo-3"] != nil })
+
+	// removed nodes are deregistered
+	source.Deregister(&registry.Service{
+		Name:    "go.micro.srv.foo",
+		Version: "1.0.0",
+		Nodes:   []*registry.Node{{Id: "foo-1"}},
+	})
+	waitFor(t, func() bool { return nodes(target, "go.micro.srv.foo")["foo-1"] == nil })
+
+	// stopping removes everything mirrored
+	f.Stop()
+	if n := nodes(target, "go.micro.srv.foo"); len(n) > 0 {
+		t.Fatalf("expected mirrored nodes to be removed, got %v", n)
+	}
+}
+
+func TestImportRules(t *testing.T) {
+	source := registry.NewMemoryRegistry()
+	target := registry.NewMemoryRegistry()
+
+	register(t, source, "foo", "1.0.0", "foo-1", map[string]string{"zone": "a"})
+	register(t, source, "foo", "2.0.0", "foo-2", map[string]string{"zone": "a"})
+
+	f := NewFederation(source, target,
+		Import(Rule{Version: "2.0.0", Address: "ingress:80"}),
+	)
+	if err := f.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Stop()
+
+	foo := nodes(target, "foo")
+	if len(foo) != 1 || foo["foo-2"] == nil || foo["foo-2"].Address != "ingress:80" {
+		t.Fatalf("expected only foo-2 via ingress, got %v", foo)
+	}
+}
+
+func waitFor(t *testing.T, fn func() bool) {
+	for i := 0; i < 100; i++ {
+		if fn() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
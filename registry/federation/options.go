@@ -0,0 +1,55 @@
+package federation
+
+import (
+	"time"
+)
+
+type Options struct {
+	// Name of the source cluster, recorded on mirrored nodes
+	Name string
+	// Export rules applied to services leaving the source
+	Export []Rule
+	// Import rules applied to services entering the target
+	Import []Rule
+	// TTL of the mirrored entries in the target
+	TTL time.Duration
+	// Interval to resync and refresh the TTL
+	Interval time.Duration
+}
+
+type Option func(o *Options)
+
+// Name sets the name of the source cluster
+func Name(n string) Option {
+	return func(o *Options) {
+		o.Name = n
+	}
+}
+
+// Export appends rules deciding which services the source exports
+func Export(r ...Rule) Option {
+	return func(o *Options) {
+		o.Export = append(o.Export, r...)
+	}
+}
+
+// Import appends rules deciding which services the target accepts
+func Import(r ...Rule) Option {
+	return func(o *Options) {
+		o.Import = append(o.Import, r...)
+	}
+}
+
+// TTL sets the registration TTL of mirrored entries
+func TTL(t time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = t
+	}
+}
+
+// Interval sets how often everything is resynced
+func Interval(t time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = t
+	}
+}
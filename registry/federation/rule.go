@@ -0,0 +1,69 @@
+package federation
+
+import (
+	"path"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// Rule matches services by name, version and metadata. The first
+// matching rule in a list decides whether a node is allowed through.
+// With no rules everything is allowed, otherwise nodes which don't
+// match any rule are dropped.
+type Rule struct {
+	// Service name to match, supports path.Match patterns e.g go.micro.srv.*
+	Service string
+	// Version to match, empty matches any version
+	Version string
+	// Metadata which must match the node or service metadata
+	Metadata map[string]string
+	// Address replaces the address of matched nodes e.g a gateway
+	Address string
+	// Deny drops matched nodes instead of allowing them
+	Deny bool
+}
+
+// Match returns true if the rule matches the node of the service
+func (r Rule) Match(s *registry.Service, n *registry.Node) bool {
+	if len(r.Service) > 0 {
+		if ok, _ := path.Match(r.Service, s.Name); !ok {
+			return false
+		}
+	}
+
+	if len(r.Version) > 0 && r.Version != s.Version {
+		return false
+	}
+
+	for k, v := range r.Metadata {
+		if val, ok := n.Metadata[k]; ok && val == v {
+			continue
+		}
+		if val, ok := s.Metadata[k]; ok && val == v {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
+
+// apply finds the first matching rule and returns whether the
+// node is allowed and the rule which allowed it, if any
+func apply(rules []Rule, s *registry.Service, n *registry.Node) (bool, *Rule) {
+	if len(rules) == 0 {
+		return true, nil
+	}
+
+	for i, r := range rules {
+		if !r.Match(s, n) {
+			continue
+		}
+		if r.Deny {
+			return false, nil
+		}
+		return true, &rules[i]
+	}
+
+	return false, nil
+}
@@ -0,0 +1,492 @@
+// Package file is a static registry read from a yaml or json file e.g
+//
+//	services:
+//	  - name: go.micro.srv.greeter
+//	    version: latest
+//	    nodes:
+//	      - id: greeter-1
+//	        address: 10.0.0.1:8080
+//
+// The file is reloaded when it changes and watchers are sent the
+// differences. Registration TTLs are ignored.
+package file
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/file"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	DefaultPath = "registry.json"
+)
+
+// File is the format of the registry file
+type File struct {
+	Services []*registry.Service `json:"services"`
+}
+
+// services by name and version
+type services map[string]map[string]*registry.Service
+
+type fileRegistry struct {
+	opts      registry.Options
+	path      string
+	writeBack bool
+
+	sync.RWMutex
+	// services read from the file
+	file services
+	// services registered at runtime when not writing back
+	dynamic services
+	// the merged view
+	services services
+	watchers map[string]*watcher
+
+	// the source of the path and the exit of its watch
+	src  source.Source
+	exit chan bool
+}
+
+func (f *fileRegistry) configure(opts ...registry.Option) {
+	for _, o := range opts {
+		o(&f.opts)
+	}
+
+	if f.opts.Context != nil {
+		if p, ok := f.opts.Context.Value(pathKey{}).(string); ok {
+			f.path = p
+		}
+		if b, ok := f.opts.Context.Value(writeBackKey{}).(bool); ok {
+			f.writeBack = b
+		}
+	}
+}
+
+func (f *fileRegistry) Init(opts ...registry.Option) error {
+	f.Lock()
+	defer f.Unlock()
+
+	path := f.path
+	f.configure(opts...)
+
+	// read and watch the new file in place of the old one
+	if f.path != path {
+		f.start()
+	}
+	return nil
+}
+
+func (f *fileRegistry) Options() registry.Options {
+	return f.opts
+}
+
+// start reads the file at the path and watches it, stopping
+// the watch of any previous path. Must hold the lock.
+func (f *fileRegistry) start() {
+	if f.exit != nil {
+		close(f.exit)
+	}
+	f.exit = make(chan bool)
+	f.src = file.NewSource(file.WithPath(f.path))
+
+	// services of a previous path are removed
+	f.file = make(services)
+
+	ch, err := f.src.Read()
+	if err == nil {
+		err = f.apply(ch)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("[file] registry failed to load %s: %v", f.path, err)
+		}
+	}
+	f.update()
+
+	// start watching before returning so no change is missed
+	w, _ := f.src.Watch()
+	go f.watch(f.src, f.path, f.exit, w)
+}
+
+// load applies a change of the file unless its watch was stopped
+func (f *fileRegistry) load(exit chan bool, ch *source.ChangeSet) error {
+	f.Lock()
+	defer f.Unlock()
+
+	select {
+	case <-exit:
+		return nil
+	default:
+	}
+
+	if err := f.apply(ch); err != nil {
+		return err
+	}
+	f.update()
+	return nil
+}
+
+// apply parses the file and sets its services. Must hold the lock.
+func (f *fileRegistry) apply(ch *source.ChangeSet) error {
+	// skip files caught mid write
+	if len(strings.TrimSpace(string(ch.Data))) == 0 {
+		return nil
+	}
+
+	parsed, err := parse(ch.Data, ch.Format)
+	if err != nil {
+		return err
+	}
+	f.file = parsed
+	return nil
+}
+
+// update merges the file and dynamic services then
+// notifies watchers of any change. Must hold the lock.
+func (f *fileRegistry) update() {
+	merged := make(services)
+	for _, set := range []services{f.file, f.dynamic} {
+		for name, versions := range set {
+			for version, s := range versions {
+				cur, ok := merged[name][version]
+				if !ok {
+					merged.put(copyService(s))
+					continue
+				}
+				// merge nodes of the same version
+				for _, n := range s.Nodes {
+					if findNode(cur.Nodes, n.Id) < 0 {
+						cur.Nodes = append(cur.Nodes, copyNode(n))
+					}
+				}
+			}
+		}
+	}
+
+	results := diff(f.services, merged)
+	f.services = merged
+
+	for _, r := range results {
+		for _, w := range f.watchers {
+			w.send(r)
+		}
+	}
+}
+
+// watch reloads the file at path as it changes starting with the watcher w
+func (f *fileRegistry) watch(src source.Source, path string, exit chan bool, w source.Watcher) {
+	var attempts int
+
+	for {
+		select {
+		case <-exit:
+			return
+		default:
+		}
+
+		var err error
+		if w == nil {
+			w, err = src.Watch()
+		}
+		if err != nil {
+			attempts++
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("[file] registry watch %s error: %v", path, err)
+			}
+			select {
+			case <-exit:
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		// the file may have changed while it wasn't watched
+		if attempts > 0 {
+			if ch, err := src.Read(); err == nil {
+				if err := f.load(exit, ch); err != nil {
+					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+						logger.Errorf("[file] registry failed to load %s: %v", path, err)
+					}
+				}
+			}
+		}
+		attempts = 0
+
+		done := make(chan bool)
+		go func(w source.Watcher) {
+			select {
+			case <-exit:
+			case <-done:
+			}
+			w.Stop()
+		}(w)
+
+		for {
+			ch, err := w.Next()
+			if err != nil {
+				// reload once watching again
+				attempts++
+				break
+			}
+			if err := f.load(exit, ch); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("[file] registry failed to load %s: %v", path, err)
+				}
+			}
+		}
+
+		close(done)
+		w = nil
+	}
+}
+
+// write saves the file services. Must hold the lock.
+func (f *fileRegistry) write() error {
+	var list []*registry.Service
+	for _, versions := range f.file {
+		for _, s := range versions {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name == list[j].Name {
+			return list[i].Version < list[j].Version
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	var b []byte
+	var err error
+
+	if isYAML(f.path) {
+		b, err = yaml.Marshal(&File{Services: list})
+	} else {
+		b, err = json.MarshalIndent(&File{Services: list}, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file and rename so the file is never partially read
+	dir := filepath.Dir(f.path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *fileRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	f.Lock()
+	defer f.Unlock()
+
+	set := f.dynamic
+	if f.writeBack {
+		set = f.file
+	}
+
+	cur, ok := set[s.Name][s.Version]
+	if !ok {
+		set.put(copyService(s))
+	} else {
+		srv := copyService(s)
+		// keep the nodes we already have
+		for _, n := range cur.Nodes {
+			if findNode(srv.Nodes, n.Id) < 0 {
+				srv.Nodes = append(srv.Nodes, n)
+			}
+		}
+		set.put(srv)
+	}
+
+	if f.writeBack {
+		if err := f.write(); err != nil {
+			return err
+		}
+	}
+
+	f.update()
+	return nil
+}
+
+func (f *fileRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
+	f.Lock()
+	defer f.Unlock()
+
+	// static services in the file are only removed when writing back
+	set := f.dynamic
+	if f.writeBack {
+		set = f.file
+	}
+
+	cur, ok := set[s.Name][s.Version]
+	if !ok {
+		return nil
+	}
+
+	var nodes []*registry.Node
+	for _, n := range cur.Nodes {
+		if findNode(s.Nodes, n.Id) < 0 {
+			nodes = append(nodes, n)
+		}
+	}
+
+	// nothing to remove
+	if len(nodes) == len(cur.Nodes) {
+		return nil
+	}
+
+	if len(nodes) > 0 {
+		cur.Nodes = nodes
+	} else {
+		delete(set[s.Name], s.Version)
+		if len(set[s.Name]) == 0 {
+			delete(set, s.Name)
+		}
+	}
+
+	if f.writeBack {
+		if err := f.write(); err != nil {
+			return err
+		}
+	}
+
+	f.update()
+	return nil
+}
+
+func (f *fileRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	versions, ok := f.services[name]
+	if !ok || len(versions) == 0 {
+		return nil, registry.ErrNotFound
+	}
+
+	list := make([]*registry.Service, 0, len(versions))
+	for _, s := range versions {
+		list = append(list, copyService(s))
+	}
+	return list, nil
+}
+
+func (f *fileRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	var list []*registry.Service
+	for _, versions := range f.services {
+		for _, s := range versions {
+			list = append(list, copyService(s))
+		}
+	}
+	return list, nil
+}
+
+func (f *fileRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
+	var wo registry.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+
+	w := newWatcher(wo)
+
+	// events aren't kept so resuming always resyncs
+	if wo.Revision > 0 {
+		w.send(&registry.Result{Action: registry.ResyncAction})
+	}
+
+	f.Lock()
+	f.watchers[w.id] = w
+	f.Unlock()
+
+	go func() {
+		<-w.exit
+		f.Lock()
+		delete(f.watchers, w.id)
+		f.Unlock()
+	}()
+
+	return w, nil
+}
+
+func (f *fileRegistry) String() string {
+	return "file"
+}
+
+// NewRegistry returns a registry read from the file set by Path
+func NewRegistry(opts ...registry.Option) registry.Registry {
+	f := &fileRegistry{
+		path:     DefaultPath,
+		file:     make(services),
+		dynamic:  make(services),
+		services: make(services),
+		watchers: make(map[string]*watcher),
+	}
+	f.configure(opts...)
+
+	f.Lock()
+	f.start()
+	f.Unlock()
+
+	return f
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func parse(b []byte, format string) (services, error) {
+	var file File
+
+	var err error
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		err = yaml.Unmarshal(b, &file)
+	default:
+		err = json.Unmarshal(b, &file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(services)
+	for _, s := range file.Services {
+		if s == nil || len(s.Name) == 0 {
+			continue
+		}
+		parsed.put(s)
+	}
+	return parsed, nil
+}
+
+func (s services) put(srv *registry.Service) {
+	if _, ok := s[srv.Name]; !ok {
+		s[srv.Name] = make(map[string]*registry.Service)
+	}
+	s[srv.Name][srv.Version] = srv
+}
@@ -0,0 +1,239 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+const testYAML = `
+services:
+  - name: foo
+    version: 1.0.0
+    metadata:
+      team: a
+    nodes:
+      - id: foo-1
+        address: 10.0.0.1:8080
+      - id: foo-2
+        address: 10.0.0.2:8080
+  - name: bar
+    version: latest
+    nodes:
+      - id: bar-1
+        address: 10.0.0.3:8080
+`
+
+const testUpdate = `
+services:
+  - name: foo
+    version: 1.0.0
+    metadata:
+      team: a
+    nodes:
+      - id: foo-1
+        address: 10.0.0.1:8080
+  - name: baz
+    version: latest
+    nodes:
+      - id: baz-1
+        address: 10.0.0.4:8080
+`
+
+const testJSON = `{
+	"services": [
+		{"name": "foo", "version": "1.0.0", "metadata": {"team": "a"}, "nodes": [{"id": "foo-1", "address": "10.0.0.1:8080"}]},
+		{"name": "baz", "version": "latest", "nodes": [{"id": "baz-1", "address": "10.0.0.4:8080"}]}
+	]
+}`
+
+func write(t *testing.T, path, data string) {
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func next(t *testing.T, w registry.Watcher) *registry.Result {
+	ch := make(chan *registry.Result, 1)
+	go func() {
+		r, err := w.Next()
+		if err == nil {
+			ch <- r
+		}
+	}()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch result")
+	}
+	return nil
+}
+
+func TestFileRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	write(t, path, testYAML)
+
+	r := NewRegistry(Path(path))
+
+	services, err := r.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || len(services[0].Nodes) != 2 || services[0].Metadata["team"] != "a" {
+		t.Fatalf("unexpected services %+v", services)
+	}
+
+	list, err := r.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(list))
+	}
+
+	w, err := r.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// foo loses a node, bar is removed and baz is added
+	tmp := path + ".tmp"
+	write(t, tmp, testUpdate)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]*registry.Result)
+	for i := 0; i < 4; i++ {
+		res := next(t, w)
+		got[res.Action+" "+res.Service.Name] = res
+	}
+
+	if res := got["delete foo"]; res == nil || len(res.Service.Nodes) != 1 || res.Service.Nodes[0].Id != "foo-2" {
+		t.Fatalf("expected foo-2 to be deleted, got %+v", got)
+	}
+	if res := got["update foo"]; res == nil || len(res.Service.Nodes) != 1 {
+		t.Fatalf("expected foo update, got %+v", got)
+	}
+	if got["delete bar"] == nil || got["create baz"] == nil {
+		t.Fatalf("expected bar deleted and baz created, got %+v", got)
+	}
+
+	if _, err := r.GetService("bar"); err != registry.ErrNotFound {
+		t.Fatalf("expected bar not found, got %v", err)
+	}
+}
+
+func TestFileRegistryRegister(t *testing.T) {
+	for _, writeBack := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		write(t, path, testJSON)
+
+		r := NewRegistry(Path(path), WriteBack(writeBack))
+
+		svc := &registry.Service{
+			Name:    "foo",
+			Version: "1.0.0",
+			Nodes:   []*registry.Node{{Id: "foo-3", Address: "10.0.0.5:8080"}},
+		}
+		if err := r.Register(svc); err != nil {
+			t.Fatal(err)
+		}
+
+		services, _ := r.GetService("foo")
+		if len(services) != 1 || len(services[0].Nodes) != 2 {
+			t.Fatalf("expected 2 foo nodes, got %+v", services)
+		}
+
+		// the file is only changed when writing back
+		b, _ := ioutil.ReadFile(path)
+		saved, err := parse(b, "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(saved["foo"]["1.0.0"].Nodes); (writeBack && n != 2) || (!writeBack && n != 1) {
+			t.Fatalf("write back %v: expected file to have the right nodes, got %d", writeBack, n)
+		}
+
+		if err := r.Deregister(svc); err != nil {
+			t.Fatal(err)
+		}
+		services, _ = r.GetService("foo")
+		if len(services) != 1 || len(services[0].Nodes) != 1 || services[0].Nodes[0].Id != "foo-1" {
+			t.Fatalf("expected foo-3 to be deregistered, got %+v", services)
+		}
+	}
+}
+
+func TestFileRegistryInit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "registry.yaml")
+	write(t, path, testYAML)
+
+	r := NewRegistry(Path(path))
+
+	// the registry is read from the new path
+	path = filepath.Join(dir, "registry.json")
+	write(t, path, testJSON)
+
+	if err := r.Init(Path(path)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetService("bar"); err != registry.ErrNotFound {
+		t.Fatalf("expected bar not found, got %v", err)
+	}
+	if _, err := r.GetService("baz"); err != nil {
+		t.Fatal(err)
+	}
+
+	// and watched for changes
+	tmp := path + ".tmp"
+	write(t, tmp, `{"services": [{"name": "qux", "version": "latest"}]}`)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := r.GetService("qux"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the new file to be watched")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatcherOverflow(t *testing.T) {
+	size := watchBufferSize
+	watchBufferSize = 2
+	defer func() { watchBufferSize = size }()
+
+	w := newWatcher(registry.WatchOptions{})
+	rw := newWatcher(registry.WatchOptions{Resync: true})
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		r := &registry.Result{Action: "create", Service: &registry.Service{Name: name}}
+		w.send(r)
+		rw.send(r)
+	}
+
+	// the results are kept
+	for _, name := range []string{"foo", "bar", "baz"} {
+		if res := next(t, w); res.Service == nil || res.Service.Name != name {
+			t.Fatalf("expected %s, got %+v", name, res)
+		}
+	}
+
+	// or replaced by a resync
+	if res := next(t, rw); res.Action != registry.ResyncAction {
+		t.Fatalf("expected a resync, got %+v", res)
+	}
+}
@@ -0,0 +1,32 @@
+package file
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+type pathKey struct{}
+type writeBackKey struct{}
+
+// Path sets the file services are read from. Files ending in .yaml
+// or .yml are parsed as yaml, anything else as json. Changing it with
+// Init reads and watches the new file in place of the old one.
+func Path(p string) registry.Option {
+	return setOption(pathKey{}, p)
+}
+
+// WriteBack writes services registered at runtime to the file,
+// otherwise they're only held in memory alongside the file
+func WriteBack(b bool) registry.Option {
+	return setOption(writeBackKey{}, b)
+}
+
+func setOption(k, v interface{}) registry.Option {
+	return func(o *registry.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
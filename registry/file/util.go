@@ -0,0 +1,111 @@
+package file
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func copyNode(n *registry.Node) *registry.Node {
+	md := make(map[string]string, len(n.Metadata))
+	for k, v := range n.Metadata {
+		md[k] = v
+	}
+	return &registry.Node{
+		Id:       n.Id,
+		Address:  n.Address,
+		Metadata: md,
+	}
+}
+
+func copyService(s *registry.Service) *registry.Service {
+	md := make(map[string]string, len(s.Metadata))
+	for k, v := range s.Metadata {
+		md[k] = v
+	}
+
+	nodes := make([]*registry.Node, len(s.Nodes))
+	for i, n := range s.Nodes {
+		nodes[i] = copyNode(n)
+	}
+
+	return &registry.Service{
+		Name:      s.Name,
+		Version:   s.Version,
+		Metadata:  md,
+		Endpoints: s.Endpoints,
+		Nodes:     nodes,
+	}
+}
+
+func findNode(nodes []*registry.Node, id string) int {
+	for i, n := range nodes {
+		if n.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// equal compares services ignoring the order of nodes
+func equal(a, b interface{}) bool {
+	ab, _ := json.Marshal(a)
+	bb, _ := json.Marshal(b)
+	return string(ab) == string(bb)
+}
+
+func sorted(s *registry.Service) *registry.Service {
+	cp := *s
+	cp.Nodes = append([]*registry.Node{}, s.Nodes...)
+	sort.Slice(cp.Nodes, func(i, j int) bool {
+		return cp.Nodes[i].Id < cp.Nodes[j].Id
+	})
+	return &cp
+}
+
+// diff returns the watch results which turn old into new. Nodes
+// removed from a service are sent in a delete before its update
+// since updates only ever add nodes in a cache.
+func diff(old, new services) []*registry.Result {
+	var results []*registry.Result
+
+	for name, versions := range old {
+		for version, s := range versions {
+			if _, ok := new[name][version]; !ok {
+				results = append(results, &registry.Result{Action: "delete", Service: copyService(s)})
+			}
+		}
+	}
+
+	for name, versions := range new {
+		for version, s := range versions {
+			cur, ok := old[name][version]
+			if !ok {
+				results = append(results, &registry.Result{Action: "create", Service: copyService(s)})
+				continue
+			}
+
+			if equal(sorted(cur), sorted(s)) {
+				continue
+			}
+
+			var removed []*registry.Node
+			for _, n := range cur.Nodes {
+				if findNode(s.Nodes, n.Id) < 0 {
+					removed = append(removed, copyNode(n))
+				}
+			}
+
+			if len(removed) > 0 {
+				del := copyService(cur)
+				del.Nodes = removed
+				results = append(results, &registry.Result{Action: "delete", Service: del})
+			}
+
+			results = append(results, &registry.Result{Action: "update", Service: copyService(s)})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"sync"
+
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/google/uuid"
+)
+
+// watchBufferSize is the number of results queued before a
+// watcher which asked for resyncs is sent one in their place
+var watchBufferSize = 128
+
+type watcher struct {
+	id   string
+	wo   registry.WatchOptions
+	exit chan bool
+
+	// results queued in order
+	sync.Mutex
+	queue  []*registry.Result
+	notify chan bool
+}
+
+func newWatcher(wo registry.WatchOptions) *watcher {
+	return &watcher{
+		id:     uuid.New().String(),
+		wo:     wo,
+		exit:   make(chan bool),
+		notify: make(chan bool, 1),
+	}
+}
+
+// send queues the result without blocking the registry. A watcher which
+// falls too far behind is sent a resync in place of its queue if it asked
+// for one with WatchRevision, otherwise the results are kept until read.
+func (w *watcher) send(r *registry.Result) {
+	if r.Service != nil && len(w.wo.Service) > 0 && w.wo.Service != r.Service.Name {
+		return
+	}
+
+	w.Lock()
+	if len(w.queue) >= watchBufferSize && w.wo.Resync {
+		w.queue = []*registry.Result{{Action: registry.ResyncAction}}
+	} else {
+		w.queue = append(w.queue, r)
+	}
+	w.Unlock()
+
+	select {
+	case w.notify <- true:
+	default:
+	}
+}
+
+func (w *watcher) pop() (*registry.Result, bool) {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.queue) == 0 {
+		return nil, false
+	}
+	r := w.queue[0]
+	w.queue[0] = nil
+	w.queue = w.queue[1:]
+	return r, true
+}
+
+func (w *watcher) Next() (*registry.Result, error) {
+	for {
+		select {
+		case <-w.exit:
+			return nil, registry.ErrWatcherStopped
+		default:
+		}
+
+		if r, ok := w.pop(); ok {
+			return r, nil
+		}
+
+		select {
+		case <-w.notify:
+		case <-w.exit:
+			return nil, registry.ErrWatcherStopped
+		}
+	}
+}
+
+func (w *watcher) Stop() {
+	select {
+	case <-w.exit:
+		return
+	default:
+		close(w.exit)
+	}
+}
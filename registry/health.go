@@ -0,0 +1,44 @@
+package registry
+
+// Status is the health of a node
+type Status string
+
+const (
+	// StatusPassing nodes are healthy and receive traffic
+	StatusPassing Status = "passing"
+	// StatusWarning nodes are alive but not ready for traffic
+	StatusWarning Status = "warning"
+	// StatusCritical nodes are failing their liveness checks
+	StatusCritical Status = "critical"
+	// StatusDraining nodes are shutting down and finishing in flight requests
+	StatusDraining Status = "draining"
+)
+
+var (
+	// StatusKey is the node metadata key the status is stored under
+	// so it's carried by every registry implementation. It's namespaced
+	// so it doesn't clash with metadata services set themselves.
+	StatusKey = "micro_health"
+)
+
+// NodeStatus returns the status of the node. Nodes without a status
+// were registered without health checks and pass, as do nodes with a
+// value which isn't one of the statuses.
+func NodeStatus(n *Node) Status {
+	if n == nil || n.Metadata == nil {
+		return StatusPassing
+	}
+	switch s := Status(n.Metadata[StatusKey]); s {
+	case StatusWarning, StatusCritical, StatusDraining:
+		return s
+	}
+	return StatusPassing
+}
+
+// SetNodeStatus sets the status of the node
+func SetNodeStatus(n *Node, s Status) {
+	if n.Metadata == nil {
+		n.Metadata = make(map[string]string)
+	}
+	n.Metadata[StatusKey] = string(s)
+}
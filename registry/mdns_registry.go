@@ -35,6 +35,8 @@ type mdnsTxt struct {
 type mdnsEntry struct {
 	id   string
 	node *mdns.Server
+	// health status announced in the txt record
+	status Status
 }
 
 type mdnsRegistry struct {
@@ -55,10 +57,12 @@ type mdnsRegistry struct {
 }
 
 type mdnsWatcher struct {
-	id   string
-	wo   WatchOptions
-	ch   chan *mdns.ServiceEntry
-	exit chan struct{}
+	id string
+	wo WatchOptions
+	// resync before resuming since events aren't kept
+	resync bool
+	ch     chan *mdns.ServiceEntry
+	exit   chan struct{}
 	// the mdns domain
 	domain string
 	// the registry
@@ -207,11 +211,14 @@ func (m *mdnsRegistry) Register(service *Service, opts ...RegisterOption) error
 			}
 		}
 
-		// already registered, continue
-		if seen {
+		// already registered with the same status, continue
+		if seen && e.status == NodeStatus(node) {
 			continue
-			// doesn't exist
+		} else if seen {
+			// status changed, shutdown and announce the new txt record
+			e.node.Shutdown()
 		} else {
+			// doesn't exist
 			e = &mdnsEntry{}
 		}
 
@@ -260,7 +267,10 @@ func (m *mdnsRegistry) Register(service *Service, opts ...RegisterOption) error
 
 		e.id = node.Id
 		e.node = srv
-		entries = append(entries, e)
+		e.status = NodeStatus(node)
+		if !seen {
+			entries = append(entries, e)
+		}
 	}
 
 	// save
@@ -455,6 +465,7 @@ func (m *mdnsRegistry) Watch(opts ...WatchOption) (Watcher, error) {
 	md := &mdnsWatcher{
 		id:       uuid.New().String(),
 		wo:       wo,
+		resync:   wo.Revision > 0,
 		ch:       make(chan *mdns.ServiceEntry, 32),
 		exit:     make(chan struct{}),
 		domain:   m.domain,
@@ -546,6 +557,11 @@ func (m *mdnsRegistry) String() string {
 }
 
 func (m *mdnsWatcher) Next() (*Result, error) {
+	if m.resync {
+		m.resync = false
+		return &Result{Action: ResyncAction}, nil
+	}
+
 	for {
 		select {
 		case e := <-m.ch:
@@ -10,8 +10,9 @@ import (
 )
 
 var (
-	sendEventTime = 10 * time.Millisecond
-	ttlPruneTime  = time.Second
+	ttlPruneTime = time.Second
+	// number of recent events kept to resume watches
+	eventBufferSize = 1024
 )
 
 type node struct {
@@ -32,8 +33,13 @@ type memRegistry struct {
 	options Options
 
 	sync.RWMutex
-	records  map[string]map[string]*record
+	records map[string]map[string]*record
+
+	// guards the watchers and event history
+	wmtx     sync.Mutex
 	watchers map[string]*memWatcher
+	revision uint64
+	events   []*Result
 }
 
 func NewMemoryRegistry(opts ...Option) Registry {
@@ -77,6 +83,12 @@ func (m *memRegistry) ttlPrune() {
 								logger.Debugf("Registry TTL expired for node %s of service %s", n.Id, name)
 							}
 							delete(m.records[name][version].Nodes, id)
+							m.sendEvent(&Result{Action: "delete", Service: &Service{
+								Name:     name,
+								Version:  version,
+								Metadata: record.Metadata,
+								Nodes:    []*Node{n.Node},
+							}})
 						}
 					}
 				}
@@ -86,25 +98,27 @@ func (m *memRegistry) ttlPrune() {
 	}
 }
 
+// sendEvent assigns the next revision to the result, records it
+// to replay for resumed watches and queues it for every watcher
 func (m *memRegistry) sendEvent(r *Result) {
-	m.RLock()
-	watchers := make([]*memWatcher, 0, len(m.watchers))
-	for _, w := range m.watchers {
-		watchers = append(watchers, w)
+	m.wmtx.Lock()
+	defer m.wmtx.Unlock()
+
+	m.revision++
+	r.Revision = m.revision
+
+	m.events = append(m.events, r)
+	if len(m.events) > eventBufferSize {
+		m.events[0] = nil
+		m.events = m.events[1:]
 	}
-	m.RUnlock()
 
-	for _, w := range watchers {
+	for id, w := range m.watchers {
 		select {
 		case <-w.exit:
-			m.Lock()
-			delete(m.watchers, w.id)
-			m.Unlock()
+			delete(m.watchers, id)
 		default:
-			select {
-			case w.res <- r:
-			case <-time.After(sendEventTime):
-			}
+			w.push(r)
 		}
 	}
 }
@@ -161,7 +175,7 @@ func (m *memRegistry) Register(s *Service, opts ...RegisterOption) error {
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 			logger.Debugf("Registry added new service: %s, version: %s", s.Name, s.Version)
 		}
-		go m.sendEvent(&Result{Action: "update", Service: s})
+		m.sendEvent(&Result{Action: "update", Service: s})
 		return nil
 	}
 
@@ -189,22 +203,57 @@ func (m *memRegistry) Register(s *Service, opts ...RegisterOption) error {
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 			logger.Debugf("Registry added new node to service: %s, version: %s", s.Name, s.Version)
 		}
-		go m.sendEvent(&Result{Action: "update", Service: s})
+		m.sendEvent(&Result{Action: "update", Service: s})
 		return nil
 	}
 
 	// refresh TTL and timestamp
+	var changed bool
 	for _, n := range s.Nodes {
 		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
 			logger.Debugf("Updated registration for service: %s, version: %s", s.Name, s.Version)
 		}
-		m.records[s.Name][s.Version].Nodes[n.Id].TTL = options.TTL
-		m.records[s.Name][s.Version].Nodes[n.Id].LastSeen = time.Now()
+		rn := m.records[s.Name][s.Version].Nodes[n.Id]
+		rn.TTL = options.TTL
+		rn.LastSeen = time.Now()
+
+		// update the metadata of the node such as its health status
+		if !equalMetadata(n.Metadata, rn.Metadata) {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("Registry updated metadata of node %s of service %s", n.Id, s.Name)
+			}
+			metadata := make(map[string]string, len(n.Metadata))
+			for k, v := range n.Metadata {
+				metadata[k] = v
+			}
+			rn.Node = &Node{
+				Id:       n.Id,
+				Address:  n.Address,
+				Metadata: metadata,
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		m.sendEvent(&Result{Action: "update", Service: s})
 	}
 
 	return nil
 }
 
+func equalMetadata(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *memRegistry) Deregister(s *Service, opts ...DeregisterOption) error {
 	m.Lock()
 	defer m.Unlock()
@@ -232,7 +281,7 @@ func (m *memRegistry) Deregister(s *Service, opts ...DeregisterOption) error {
 				logger.Debugf("Registry removed service: %s", s.Name)
 			}
 		}
-		go m.sendEvent(&Result{Action: "delete", Service: s})
+		m.sendEvent(&Result{Action: "delete", Service: s})
 	}
 
 	return nil
@@ -278,15 +327,30 @@ func (m *memRegistry) Watch(opts ...WatchOption) (Watcher, error) {
 	}
 
 	w := &memWatcher{
-		exit: make(chan bool),
-		res:  make(chan *Result),
-		id:   uuid.New().String(),
-		wo:   wo,
+		exit:   make(chan bool),
+		notify: make(chan bool, 1),
+		id:     uuid.New().String(),
+		wo:     wo,
+	}
+
+	m.wmtx.Lock()
+	defer m.wmtx.Unlock()
+
+	// replay the events after the revision if we still have them
+	if wo.Revision > 0 && wo.Revision != m.revision {
+		if wo.Revision < m.revision && m.events[0].Revision <= wo.Revision+1 {
+			for _, r := range m.events {
+				if r.Revision > wo.Revision {
+					w.queue = append(w.queue, r)
+				}
+			}
+		} else {
+			w.queue = append(w.queue, &Result{Action: ResyncAction, Revision: m.revision})
+		}
+		w.notify <- true
 	}
 
-	m.Lock()
 	m.watchers[w.id] = w
-	m.Unlock()
 
 	return w, nil
 }
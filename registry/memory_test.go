@@ -242,3 +242,170 @@ func TestMemoryRegistryTTLConcurrent(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryRegistryStatus(t *testing.T) {
+	m := NewMemoryRegistry()
+
+	service := &Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes: []*Node{
+			{Id: "foo-1", Address: "localhost:9999", Metadata: map[string]string{"foo": "bar"}},
+		},
+	}
+	if err := m.Register(service); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := m.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// re-register as warning
+	update := &Service{
+		Name:    "foo",
+		Version: "1.0.0",
+		Nodes: []*Node{
+			{Id: "foo-1", Address: "localhost:9999", Metadata: map[string]string{"foo": "bar"}},
+		},
+	}
+	SetNodeStatus(update.Nodes[0], StatusWarning)
+	if err := m.Register(update); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Action != "update" || NodeStatus(res.Service.Nodes[0]) != StatusWarning {
+		t.Fatalf("expected update to warning, got %s %s", res.Action, NodeStatus(res.Service.Nodes[0]))
+	}
+
+	services, err := m.GetService("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := NodeStatus(services[0].Nodes[0]); status != StatusWarning {
+		t.Fatalf("expected warning, got %s", status)
+	}
+}
+
+func TestMemoryRegistryResume(t *testing.T) {
+	m := NewMemoryRegistry()
+
+	w, err := m.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foo := &Service{Name: "foo", Version: "1", Nodes: []*Node{{Id: "foo-1", Metadata: map[string]string{"a": "b"}}}}
+	bar := &Service{Name: "bar", Version: "1", Nodes: []*Node{{Id: "bar-1", Metadata: map[string]string{"a": "b"}}}}
+
+	if err := m.Register(foo); err != nil {
+		t.Fatal(err)
+	}
+	res, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev := res.Revision
+	if rev == 0 {
+		t.Fatal("expected a revision")
+	}
+	w.Stop()
+
+	// missed while not watching
+	m.Register(bar)
+	m.Deregister(foo)
+
+	w, err = m.Watch(WatchRevision(rev))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	for _, expect := range []string{"update bar", "delete foo"} {
+		res, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Action + " " + res.Service.Name; got != expect {
+			t.Fatalf("expected %s, got %s", expect, got)
+		}
+		if res.Revision != rev+1 {
+			t.Fatalf("expected revision %d, got %d", rev+1, res.Revision)
+		}
+		rev = res.Revision
+	}
+
+	// events no longer buffered resync
+	size := eventBufferSize
+	eventBufferSize = 1
+	defer func() { eventBufferSize = size }()
+	m.Register(foo)
+	m.Register(&Service{Name: "baz", Version: "1", Nodes: []*Node{{Id: "baz-1"}}})
+
+	for _, r := range []uint64{1, rev + 100} {
+		rw, err := m.Watch(WatchRevision(r))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := rw.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Action != ResyncAction {
+			t.Fatalf("expected resync resuming from %d, got %s", r, res.Action)
+		}
+		rw.Stop()
+	}
+}
+
+func TestMemoryRegistryOverflow(t *testing.T) {
+	size := eventBufferSize
+	eventBufferSize = 2
+	defer func() { eventBufferSize = size }()
+
+	m := NewMemoryRegistry()
+
+	// a plain watch never gets a result without a service
+	w, err := m.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// a watch resuming from now is sent a resync
+	rw, err := m.Watch(WatchRevision(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rw.Stop()
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		if err := m.Register(&Service{Name: name, Version: "1", Nodes: []*Node{{Id: name + "-1"}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, expect := range []string{"foo", "bar"} {
+		res, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Service == nil || res.Service.Name != expect {
+			t.Fatalf("expected %s, got %+v", expect, res)
+		}
+	}
+
+	res, err := rw.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Action != ResyncAction || res.Service != nil {
+		t.Fatalf("expected resync, got %+v", res)
+	}
+}
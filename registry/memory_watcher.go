@@ -2,26 +2,76 @@ package registry
 
 import (
 	"errors"
+	"sync"
 )
 
 type memWatcher struct {
 	id   string
 	wo   WatchOptions
-	res  chan *Result
 	exit chan bool
+
+	// results queued in revision order
+	sync.Mutex
+	queue  []*Result
+	notify chan bool
+}
+
+// push queues the result without blocking the registry. A watcher which
+// falls too far behind is sent a resync in place of its queue if it asked
+// for one with WatchRevision, otherwise the result is dropped.
+func (m *memWatcher) push(r *Result) {
+	m.Lock()
+	switch {
+	case len(m.queue) < eventBufferSize:
+		m.queue = append(m.queue, r)
+	case m.wo.Resync:
+		m.queue = []*Result{{Action: ResyncAction, Revision: r.Revision}}
+	default:
+		m.Unlock()
+		return
+	}
+	m.Unlock()
+
+	select {
+	case m.notify <- true:
+	default:
+	}
+}
+
+func (m *memWatcher) pop() (*Result, bool) {
+	m.Lock()
+	defer m.Unlock()
+	if len(m.queue) == 0 {
+		return nil, false
+	}
+	r := m.queue[0]
+	m.queue[0] = nil
+	m.queue = m.queue[1:]
+	return r, true
 }
 
 func (m *memWatcher) Next() (*Result, error) {
 	for {
-		select {
-		case r := <-m.res:
-			if len(m.wo.Service) > 0 && m.wo.Service != r.Service.Name {
+		r, ok := m.pop()
+		if !ok {
+			select {
+			case <-m.notify:
 				continue
+			case <-m.exit:
+				return nil, errors.New("watcher stopped")
 			}
-			return r, nil
+		}
+
+		select {
 		case <-m.exit:
 			return nil, errors.New("watcher stopped")
+		default:
+		}
+
+		if r.Service != nil && len(m.wo.Service) > 0 && m.wo.Service != r.Service.Name {
+			continue
 		}
+		return r, nil
 	}
 }
 
@@ -27,6 +27,14 @@ type WatchOptions struct {
 	// Specify a service to watch
 	// If blank, the watch is for all services
 	Service string
+	// Revision to resume the watch after. Events since are
+	// replayed if the registry supports it otherwise a resync
+	// result is sent first.
+	Revision uint64
+	// Resync is set by WatchRevision. Watchers which fall behind
+	// are then sent a resync result with no service in place of
+	// the events they missed. Other watchers miss the events.
+	Resync bool
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -90,6 +98,15 @@ func WatchService(name string) WatchOption {
 	}
 }
 
+// WatchRevision resumes a watch after the revision of the last result seen,
+// zero starts from now. The watcher must handle results with the ResyncAction.
+func WatchRevision(rev uint64) WatchOption {
+	return func(o *WatchOptions) {
+		o.Revision = rev
+		o.Resync = true
+	}
+}
+
 func WatchContext(ctx context.Context) WatchOption {
 	return func(o *WatchOptions) {
 		o.Context = ctx
@@ -0,0 +1,230 @@
+// Package schema diffs the endpoint schemas of services in the registry
+// to catch breaking changes between versions
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// ChangeType is the kind of schema change
+type ChangeType string
+
+const (
+	EndpointAdded   ChangeType = "endpoint_added"
+	EndpointRemoved ChangeType = "endpoint_removed"
+	StreamChanged   ChangeType = "stream_changed"
+	FieldAdded      ChangeType = "field_added"
+	FieldRemoved    ChangeType = "field_removed"
+	TypeChanged     ChangeType = "type_changed"
+)
+
+// Change is a difference between two endpoint schemas
+type Change struct {
+	Type ChangeType `json:"type"`
+	// Endpoint e.g Greeter.Hello
+	Endpoint string `json:"endpoint"`
+	// Path of the field e.g request.user.name
+	Path string `json:"path,omitempty"`
+	// Old and New describe what changed
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+	// Breaking is true if existing callers may fail
+	Breaking bool `json:"breaking"`
+}
+
+func (c *Change) String() string {
+	var b strings.Builder
+	if c.Breaking {
+		b.WriteString("BREAKING ")
+	}
+	b.WriteString(string(c.Type))
+	b.WriteString(" ")
+	b.WriteString(c.Endpoint)
+	if len(c.Path) > 0 {
+		b.WriteString(" ")
+		b.WriteString(c.Path)
+	}
+	if len(c.Old) > 0 || len(c.New) > 0 {
+		fmt.Fprintf(&b, " (%s -> %s)", c.Old, c.New)
+	}
+	return b.String()
+}
+
+// Diff compares the endpoints of two versions of a service. Removed
+// endpoints, removed fields, changed field types and changes between
+// unary and streaming are breaking.
+func Diff(old, new *registry.Service) []*Change {
+	var changes []*Change
+
+	oldEps := endpoints(old)
+	newEps := endpoints(new)
+
+	for _, name := range names(endpointNames(oldEps), endpointNames(newEps)) {
+		o, n := oldEps[name], newEps[name]
+
+		switch {
+		case n == nil:
+			changes = append(changes, &Change{Type: EndpointRemoved, Endpoint: name, Breaking: true})
+			continue
+		case o == nil:
+			changes = append(changes, &Change{Type: EndpointAdded, Endpoint: name})
+			continue
+		}
+
+		if os, ns := o.Metadata["stream"], n.Metadata["stream"]; os != ns {
+			changes = append(changes, &Change{
+				Type:     StreamChanged,
+				Endpoint: name,
+				Old:      stream(os),
+				New:      stream(ns),
+				Breaking: true,
+			})
+		}
+
+		changes = append(changes, diffValue(name, "request", o.Request, n.Request)...)
+		changes = append(changes, diffValue(name, "response", o.Response, n.Response)...)
+	}
+
+	return changes
+}
+
+// Breaking returns only the breaking changes
+func Breaking(changes []*Change) []*Change {
+	var breaking []*Change
+	for _, c := range changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// Compare diffs two versions of a service read from the registry
+func Compare(r registry.Registry, service, oldVersion, newVersion string) ([]*Change, error) {
+	services, err := r.GetService(service)
+	if err != nil {
+		return nil, err
+	}
+
+	var old, new *registry.Service
+	for _, s := range services {
+		switch s.Version {
+		case oldVersion:
+			old = s
+		case newVersion:
+			new = s
+		}
+	}
+
+	if old == nil {
+		return nil, fmt.Errorf("service %s version %s not found", service, oldVersion)
+	}
+	if new == nil {
+		return nil, fmt.Errorf("service %s version %s not found", service, newVersion)
+	}
+
+	return Diff(old, new), nil
+}
+
+func diffValue(endpoint, path string, o, n *registry.Value) []*Change {
+	// missing values can't be compared e.g beyond the extraction depth
+	if o == nil || n == nil {
+		return nil
+	}
+
+	// leaf values are compared by type, structs by their fields
+	if len(o.Values) == 0 || len(n.Values) == 0 {
+		if len(o.Values) != len(n.Values) || o.Type != n.Type {
+			return []*Change{{
+				Type:     TypeChanged,
+				Endpoint: endpoint,
+				Path:     path,
+				Old:      o.Type,
+				New:      n.Type,
+				Breaking: true,
+			}}
+		}
+		return nil
+	}
+
+	oldVals := values(o)
+	newVals := values(n)
+
+	var changes []*Change
+
+	for _, name := range names(valueNames(oldVals), valueNames(newVals)) {
+		ov, nv := oldVals[name], newVals[name]
+		p := path + "." + name
+
+		switch {
+		case nv == nil:
+			changes = append(changes, &Change{Type: FieldRemoved, Endpoint: endpoint, Path: p, Old: ov.Type, Breaking: true})
+		case ov == nil:
+			changes = append(changes, &Change{Type: FieldAdded, Endpoint: endpoint, Path: p, New: nv.Type})
+		default:
+			changes = append(changes, diffValue(endpoint, p, ov, nv)...)
+		}
+	}
+
+	return changes
+}
+
+func endpoints(s *registry.Service) map[string]*registry.Endpoint {
+	eps := make(map[string]*registry.Endpoint)
+	if s == nil {
+		return eps
+	}
+	for _, ep := range s.Endpoints {
+		eps[ep.Name] = ep
+	}
+	return eps
+}
+
+func values(v *registry.Value) map[string]*registry.Value {
+	vals := make(map[string]*registry.Value, len(v.Values))
+	for _, val := range v.Values {
+		vals[val.Name] = val
+	}
+	return vals
+}
+
+// names returns the sorted union of the names
+func names(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, k := range append(a, b...) {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func endpointNames(eps map[string]*registry.Endpoint) []string {
+	keys := make([]string, 0, len(eps))
+	for k := range eps {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func valueNames(vals map[string]*registry.Value) []string {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func stream(v string) string {
+	if v == "true" {
+		return "stream"
+	}
+	return "unary"
+}
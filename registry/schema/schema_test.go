@@ -0,0 +1,156 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/registry/schema"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/transport"
+)
+
+func user(fields ...*registry.Value) *registry.Value {
+	return &registry.Value{Name: "user", Type: "User", Values: fields}
+}
+
+func field(name, typ string) *registry.Value {
+	return &registry.Value{Name: name, Type: typ}
+}
+
+func TestDiff(t *testing.T) {
+	old := &registry.Service{
+		Name:    "users",
+		Version: "1.0.0",
+		Endpoints: []*registry.Endpoint{
+			{
+				Name: "Users.Update",
+				Request: &registry.Value{Name: "Request", Type: "Request", Values: []*registry.Value{
+					field("id", "string"),
+					user(field("name", "string"), field("email", "string"), field("age", "int")),
+				}},
+				Response: &registry.Value{Name: "Response", Type: "Response", Values: []*registry.Value{field("ok", "bool")}},
+			},
+			{Name: "Users.Delete"},
+			{Name: "Users.Watch", Metadata: map[string]string{"stream": "true"}},
+		},
+	}
+
+	new := &registry.Service{
+		Name:    "users",
+		Version: "2.0.0",
+		Endpoints: []*registry.Endpoint{
+			{
+				Name: "Users.Update",
+				Request: &registry.Value{Name: "UpdateRequest", Type: "UpdateRequest", Values: []*registry.Value{
+					field("id", "string"),
+					user(field("name", "string"), field("age", "string"), field("bio", "string")),
+				}},
+				Response: &registry.Value{Name: "Response", Type: "Response", Values: []*registry.Value{field("ok", "bool")}},
+			},
+			{Name: "Users.Create"},
+			{Name: "Users.Watch"},
+		},
+	}
+
+	expected := map[string]bool{
+		"endpoint_added Users.Create":                   false,
+		"endpoint_removed Users.Delete":                 true,
+		"field_added Users.Update request.user.bio":     false,
+		"field_removed Users.Update request.user.email": true,
+		"type_changed Users.Update request.user.age":    true,
+		"stream_changed Users.Watch":                    true,
+	}
+
+	changes := schema.Diff(old, new)
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+
+	for _, c := range changes {
+		key := string(c.Type) + " " + c.Endpoint
+		if len(c.Path) > 0 {
+			key += " " + c.Path
+		}
+		breaking, ok := expected[key]
+		if !ok {
+			t.Fatalf("unexpected change %s", c)
+		}
+		if breaking != c.Breaking {
+			t.Fatalf("expected %s breaking to be %v", key, breaking)
+		}
+	}
+
+	if n := len(schema.Breaking(changes)); n != 4 {
+		t.Fatalf("expected 4 breaking changes, got %d", n)
+	}
+}
+
+type Request struct {
+	Id string `json:"id"`
+}
+
+type Response struct {
+	Ok bool `json:"ok"`
+}
+
+type Users struct{}
+
+func (u *Users) Update(ctx context.Context, req *Request, rsp *Response) error { return nil }
+
+type Audit struct{}
+
+func (a *Audit) Log(ctx context.Context, req *Request, rsp *Response) error { return nil }
+
+// start runs the handlers in process registered with r
+func start(t *testing.T, r registry.Registry, version string, handlers ...interface{}) server.Server {
+	srv := server.NewServer(
+		server.Name("users"),
+		server.Version(version),
+		server.Registry(r),
+		server.Transport(transport.NewMemoryTransport()),
+	)
+	for _, h := range handlers {
+		if err := srv.Handle(srv.NewHandler(h)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func TestCompare(t *testing.T) {
+	r := registry.NewMemoryRegistry()
+
+	v1 := start(t, r, "1.0.0", &Users{})
+	defer v1.Stop()
+	v2 := start(t, r, "2.0.0", &Users{}, &Audit{})
+	defer v2.Stop()
+	v3 := start(t, r, "3.0.0", &Audit{})
+	defer v3.Stop()
+
+	// adding an endpoint is compatible
+	changes, err := schema.Compare(r, "users", "1.0.0", "2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != schema.EndpointAdded || changes[0].Endpoint != "Audit.Log" {
+		t.Fatalf("expected Audit.Log to be added, got %v", changes)
+	}
+
+	// removing one isn't
+	changes, err = schema.Compare(r, "users", "2.0.0", "3.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	breaking := schema.Breaking(changes)
+	if len(breaking) != 1 || breaking[0].Endpoint != "Users.Update" {
+		t.Fatalf("expected Users.Update to be removed, got %v", changes)
+	}
+
+	if _, err := schema.Compare(r, "users", "1.0.0", "4.0.0"); err == nil {
+		t.Fatal("expected error for missing version")
+	}
+}
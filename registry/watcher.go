@@ -12,11 +12,22 @@ type Watcher interface {
 
 // Result is returned by a call to Next on
 // the watcher. Actions can be create, update, delete
+// or resync when events may have been missed and the
+// services should be listed again.
 type Result struct {
 	Action  string
 	Service *Service
+	// Revision is the monotonic revision of the event
+	// or zero when the registry doesn't support it
+	Revision uint64
 }
 
+const (
+	// ResyncAction is the action of a synthetic result sent when a watch
+	// can't be resumed from the requested revision. It has no service.
+	ResyncAction = "resync"
+)
+
 // EventType defines registry event type
 type EventType int
 
@@ -57,6 +57,9 @@ func (c *registrySelector) Select(service string, opts ...SelectOption) (Next, e
 		return nil, err
 	}
 
+	// skip nodes which aren't passing their health checks
+	services = FilterStatus(registry.StatusPassing)(services)
+
 	// apply the filters
 	for _, filter := range sopts.Filters {
 		services = filter(services)
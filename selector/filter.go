@@ -71,3 +71,36 @@ func FilterVersion(version string) Filter {
 		return services
 	}
 }
+
+// FilterStatus is a health based Select Filter which will
+// only return nodes with one of the statuses specified.
+func FilterStatus(status ...registry.Status) Filter {
+	return func(old []*registry.Service) []*registry.Service {
+		var services []*registry.Service
+
+		for _, service := range old {
+			serv := new(registry.Service)
+			var nodes []*registry.Node
+
+			for _, node := range service.Nodes {
+				ns := registry.NodeStatus(node)
+				for _, s := range status {
+					if ns == s {
+						nodes = append(nodes, node)
+						break
+					}
+				}
+			}
+
+			// only add service if there's some nodes
+			if len(nodes) > 0 {
+				// copy
+				*serv = *service
+				serv.Nodes = nodes
+				services = append(services, serv)
+			}
+		}
+
+		return services
+	}
+}
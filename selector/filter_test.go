@@ -237,3 +237,46 @@ func TestFilterVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterStatus(t *testing.T) {
+	services := []*registry.Service{
+		{
+			Name:    "test",
+			Version: "1.0.0",
+			Nodes: []*registry.Node{
+				{Id: "no-status"},
+				{Id: "passing", Metadata: map[string]string{registry.StatusKey: "passing"}},
+				{Id: "warning", Metadata: map[string]string{registry.StatusKey: "warning"}},
+				{Id: "unknown", Metadata: map[string]string{registry.StatusKey: "active"}},
+				{Id: "own-status", Metadata: map[string]string{"status": "critical"}},
+			},
+		},
+		{
+			Name:    "test",
+			Version: "1.1.0",
+			Nodes: []*registry.Node{
+				{Id: "draining", Metadata: map[string]string{registry.StatusKey: "draining"}},
+			},
+		},
+	}
+
+	passing := FilterStatus(registry.StatusPassing)(services)
+	if len(passing) != 1 || len(passing[0].Nodes) != 4 {
+		t.Fatalf("expected 1 service with 4 passing nodes, got %v", passing)
+	}
+	for _, node := range passing[0].Nodes {
+		if node.Id == "warning" {
+			t.Fatalf("unexpected node %s", node.Id)
+		}
+	}
+
+	// the original services are left as they were
+	if len(services[0].Nodes) != 5 {
+		t.Fatal("expected filter to copy services")
+	}
+
+	rest := FilterStatus(registry.StatusWarning, registry.StatusDraining)(services)
+	if len(rest) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(rest))
+	}
+}
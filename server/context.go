@@ -7,6 +7,8 @@ import (
 
 type serverKey struct{}
 
+type subscriberKey struct{}
+
 func wait(ctx context.Context) *sync.WaitGroup {
 	if ctx == nil {
 		return nil
@@ -26,3 +28,15 @@ func FromContext(ctx context.Context) (Server, bool) {
 func NewContext(ctx context.Context, s Server) context.Context {
 	return context.WithValue(ctx, serverKey{}, s)
 }
+
+// SubscriberFromContext returns the name of the subscriber handling a message
+// e.g. Example.Handle for a method or the package qualified name of a func.
+// Closures created from the same func literal share a name.
+func SubscriberFromContext(ctx context.Context) (string, bool) {
+	s, ok := ctx.Value(subscriberKey{}).(string)
+	return s, ok
+}
+
+func newSubscriberContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, subscriberKey{}, name)
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+)
+
+// DefaultBatchWait is how long a batch waits to fill when no wait is set
+var DefaultBatchWait = time.Millisecond * 100
+
+// dispatcher applies the concurrency, prefetch and
+// batching options of a subscriber to its events
+type dispatcher struct {
+	handle func(broker.Event) error
+	batch  func([]broker.Event) error
+
+	// messages received but not yet handled
+	inflight chan struct{}
+	// handlers running
+	running chan struct{}
+
+	size int
+	wait time.Duration
+
+	sync.Mutex
+	pending []*pendingEvent
+	// the batch being filled
+	gen int
+}
+
+type pendingEvent struct {
+	event broker.Event
+	done  chan error
+}
+
+func (s *rpcServer) newDispatcher(sb Subscriber) *dispatcher {
+	opts := sb.Options()
+
+	d := &dispatcher{
+		handle: s.HandleEvent,
+		size:   opts.BatchSize,
+		wait:   opts.BatchWait,
+	}
+
+	if opts.Prefetch > 0 {
+		d.inflight = make(chan struct{}, opts.Prefetch)
+	}
+	if opts.Concurrency > 0 {
+		d.running = make(chan struct{}, opts.Concurrency)
+	}
+	if d.wait <= 0 {
+		d.wait = DefaultBatchWait
+	}
+
+	if sub, ok := sb.(*subscriber); ok && d.size > 0 {
+		d.batch = func(events []broker.Event) error {
+			return s.handleBatch(sub, events)
+		}
+	}
+
+	return d
+}
+
+// Handle is the broker handler of the subscriber
+func (d *dispatcher) Handle(e broker.Event) error {
+	if d.inflight != nil {
+		d.inflight <- struct{}{}
+		defer func() { <-d.inflight }()
+	}
+
+	if d.batch != nil {
+		return d.add(e)
+	}
+
+	if d.running != nil {
+		d.running <- struct{}{}
+		defer func() { <-d.running }()
+	}
+
+	return d.handle(e)
+}
+
+// add adds the event to the batch and waits for the batch to be handled
+func (d *dispatcher) add(e broker.Event) error {
+	p := &pendingEvent{
+		event: e,
+		done:  make(chan error, 1),
+	}
+
+	d.Lock()
+	d.pending = append(d.pending, p)
+
+	switch {
+	case len(d.pending) >= d.size:
+		batch := d.pending
+		d.pending = nil
+		d.gen++
+		d.Unlock()
+		go d.flush(batch)
+	case len(d.pending) == 1:
+		gen := d.gen
+		d.Unlock()
+		time.AfterFunc(d.wait, func() { d.expire(gen) })
+	default:
+		d.Unlock()
+	}
+
+	return <-p.done
+}
+
+// expire handles the batch if it hasn't filled in time
+func (d *dispatcher) expire(gen int) {
+	d.Lock()
+	if d.gen != gen || len(d.pending) == 0 {
+		d.Unlock()
+		return
+	}
+	batch := d.pending
+	d.pending = nil
+	d.gen++
+	d.Unlock()
+
+	d.flush(batch)
+}
+
+func (d *dispatcher) flush(batch []*pendingEvent) {
+	if d.running != nil {
+		d.running <- struct{}{}
+		defer func() { <-d.running }()
+	}
+
+	events := make([]broker.Event, len(batch))
+	for i, p := range batch {
+		events[i] = p.event
+	}
+
+	err := d.batch(events)
+	for _, p := range batch {
+		p.done <- err
+	}
+}
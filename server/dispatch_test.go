@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+type Order struct {
+	Id int `json:"id"`
+}
+
+func newOrderEvent(t *testing.T, id int) broker.Event {
+	b, err := json.Marshal(&Order{Id: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &event{message: &broker.Message{
+		Header: map[string]string{
+			"Content-Type": "application/json",
+			"Micro-Topic":  "orders",
+			"Order-Id":     strconv.Itoa(id),
+		},
+		Body: b,
+	}}
+}
+
+// dispatch sends the events to the subscriber concurrently and returns their errors
+func dispatch(t *testing.T, s *rpcServer, sb Subscriber, count int) []error {
+	if err := s.Subscribe(sb); err != nil {
+		t.Fatal(err)
+	}
+	d := s.newDispatcher(sb)
+
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = d.Handle(newOrderEvent(t, i))
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func TestSubscriberConcurrency(t *testing.T) {
+	s := newRpcServer(Registry(registry.NewMemoryRegistry())).(*rpcServer)
+
+	var running, max int32
+	sb := s.NewSubscriber("orders", func(ctx context.Context, o *Order) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}, SubscriberConcurrency(2), SubscriberPrefetch(4))
+
+	for _, err := range dispatch(t, s, sb, 10) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if max != 2 {
+		t.Fatalf("expected 2 handlers at most, got %d", max)
+	}
+}
+
+func TestSubscriberBatch(t *testing.T) {
+	s := newRpcServer(Registry(registry.NewMemoryRegistry())).(*rpcServer)
+
+	var mtx sync.Mutex
+	var batches [][]*Order
+
+	sb := s.NewSubscriber("orders", func(ctx context.Context, orders []*Order) error {
+		mtx.Lock()
+		batches = append(batches, orders)
+		mtx.Unlock()
+
+		for _, o := range orders {
+			if o.Id == 6 {
+				return errors.New("failed")
+			}
+		}
+		return nil
+	}, SubscriberBatch(4, 50*time.Millisecond))
+
+	errs := dispatch(t, s, sb, 7)
+
+	// a full batch and a partial one once the wait passed
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(batches))
+	}
+
+	seen := make(map[int]bool)
+	for _, b := range batches {
+		if len(b) > 4 {
+			t.Fatalf("expected batches of 4 at most, got %d", len(b))
+		}
+		for _, o := range b {
+			seen[o.Id] = true
+		}
+	}
+	if len(seen) != 7 {
+		t.Fatalf("expected every message to be handled, got %v", seen)
+	}
+
+	// the messages of the failed batch get its error
+	var failed int
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == 0 || failed > 4 {
+		t.Fatalf("expected the failed batch to return errors, got %d", failed)
+	}
+}
+
+func TestSubscriberBatchWrappers(t *testing.T) {
+	var mtx sync.Mutex
+	var wrapped int
+
+	// skips the messages with an odd id
+	wrapper := func(fn SubscriberFunc) SubscriberFunc {
+		return func(ctx context.Context, msg Message) error {
+			var o Order
+			if err := json.Unmarshal(msg.Body(), &o); err != nil {
+				return err
+			}
+			// each message has its own context
+			if id, _ := metadata.Get(ctx, "Order-Id"); id != strconv.Itoa(o.Id) {
+				return errors.New("context of message " + id + " passed with order " + strconv.Itoa(o.Id))
+			}
+			mtx.Lock()
+			wrapped++
+			mtx.Unlock()
+			if o.Id%2 == 1 {
+				return nil
+			}
+			return fn(ctx, msg)
+		}
+	}
+
+	s := newRpcServer(
+		Registry(registry.NewMemoryRegistry()),
+		WrapSubscriber(wrapper),
+	).(*rpcServer)
+
+	var handled []int
+	sb := s.NewSubscriber("orders", func(ctx context.Context, orders []*Order) error {
+		mtx.Lock()
+		for _, o := range orders {
+			handled = append(handled, o.Id)
+		}
+		mtx.Unlock()
+		return nil
+	}, SubscriberBatch(4, 50*time.Millisecond))
+
+	for _, err := range dispatch(t, s, sb, 7) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if wrapped != 7 {
+		t.Fatalf("expected the wrapper to see 7 messages, got %d", wrapped)
+	}
+	if len(handled) != 4 {
+		t.Fatalf("expected 4 messages to be handled, got %v", handled)
+	}
+	for _, id := range handled {
+		if id%2 == 1 {
+			t.Fatalf("expected message %d to be skipped", id)
+		}
+	}
+}
+
+func TestSubscriberBatchSignature(t *testing.T) {
+	s := newRpcServer(Registry(registry.NewMemoryRegistry())).(*rpcServer)
+
+	sb := s.NewSubscriber("orders", func(ctx context.Context, o *Order) error {
+		return nil
+	}, SubscriberBatch(4, 50*time.Millisecond))
+
+	if err := s.Subscribe(sb); err == nil {
+		t.Fatal("expected a batched subscriber not taking a slice to be rejected")
+	}
+}
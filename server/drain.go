@@ -0,0 +1,122 @@
+package server
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+var (
+	// drainPollInterval is how often in flight requests are checked while draining
+	drainPollInterval = 10 * time.Millisecond
+	// drainLogInterval is how often drain progress is logged
+	drainLogInterval = time.Second
+)
+
+// errDraining is returned for requests received while draining. The
+// 503 is retried by the client on another node.
+func errDraining(service string) error {
+	return errors.ServiceUnavailable(service, "server is draining")
+}
+
+// begin marks the start of a request, returning false if draining
+func (s *rpcServer) begin() bool {
+	atomic.AddInt64(&s.active, 1)
+	// checked after counting the request so drain never misses it
+	if atomic.LoadInt32(&s.draining) == 1 {
+		atomic.AddInt64(&s.active, -1)
+		count("server.drain.rejected", 1)
+		return false
+	}
+	return true
+}
+
+// end marks the end of a request
+func (s *rpcServer) end() {
+	atomic.AddInt64(&s.active, -1)
+}
+
+// drain unsubscribes from the broker, marks the node as draining in the
+// registry so clients stop selecting it, then waits for in flight requests
+// and streams to finish up to the drain timeout.
+func (s *rpcServer) drain() {
+	config := s.Options()
+	if config.DrainTimeout <= 0 {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.draining, 0, 1) {
+		return
+	}
+
+	node := config.Name + "-" + config.Id
+	start := time.Now()
+	count("server.drain.started", 1)
+
+	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+		log.Infof("Server %s draining for up to %v", node, config.DrainTimeout)
+	}
+
+	// stop receiving messages first
+	s.unsubscribe(node)
+
+	// announce the node is draining
+	s.RLock()
+	registered := s.registered
+	s.RUnlock()
+	if registered {
+		if err := s.Register(); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				log.Errorf("Server %s register draining error: %v", node, err)
+			}
+		}
+	}
+
+	deadline := time.NewTimer(config.DrainTimeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(drainPollInterval)
+	defer poll.Stop()
+	progress := time.NewTicker(drainLogInterval)
+	defer progress.Stop()
+
+	for {
+		active := atomic.LoadInt64(&s.active)
+		if active <= 0 {
+			count("server.drain.completed", 1)
+			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+				log.Infof("Server %s drained in %v", node, time.Since(start))
+			}
+			return
+		}
+
+		select {
+		case <-poll.C:
+		case <-progress.C:
+			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+				log.Infof("Server %s draining: %d requests in flight", node, active)
+			}
+		case <-deadline.C:
+			count("server.drain.timeout", 1)
+			count("server.drain.abandoned", uint64(active))
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				log.Warnf("Server %s drain timeout after %v: %d requests in flight", node, config.DrainTimeout, active)
+			}
+			return
+		}
+	}
+}
+
+// isDraining reports whether the server is draining
+func (s *rpcServer) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
+
+// count adds n to the named counter of the default stats if it keeps counters
+func count(name string, n uint64) {
+	if c, ok := stats.DefaultStats.(stats.Counter); ok {
+		c.Count(name, n)
+	}
+}
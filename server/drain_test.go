@@ -0,0 +1,87 @@
+package server
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestDrain(t *testing.T) {
+	s := newRpcServer(
+		Registry(registry.NewMemoryRegistry()),
+		DrainTimeout(time.Second),
+	).(*rpcServer)
+
+	// a request in flight
+	if !s.begin() {
+		t.Fatal("expected request to be accepted")
+	}
+
+	done := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		s.drain()
+		done <- time.Since(start)
+	}()
+
+	for atomic.LoadInt32(&s.draining) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// new requests are rejected with a retryable error
+	if s.begin() {
+		t.Fatal("expected request to be rejected while draining")
+	}
+	err := s.HandleEvent(&testEvent{})
+	if e := errors.FromError(err); e.Code != 503 {
+		t.Fatalf("expected 503, got %v", err)
+	}
+
+	// the node registers as draining
+	if status := s.health(s.Options()); status != registry.StatusDraining {
+		t.Fatalf("expected draining, got %s", status)
+	}
+
+	// finish the request in flight
+	time.Sleep(50 * time.Millisecond)
+	s.end()
+
+	select {
+	case d := <-done:
+		if d >= time.Second {
+			t.Fatalf("expected drain to finish with the request, took %v", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("drain didn't finish")
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	s := newRpcServer(
+		Registry(registry.NewMemoryRegistry()),
+		DrainTimeout(50*time.Millisecond),
+	).(*rpcServer)
+
+	// a request that never finishes
+	s.begin()
+
+	start := time.Now()
+	s.drain()
+	if d := time.Since(start); d < 50*time.Millisecond || d > time.Second {
+		t.Fatalf("expected drain to stop at the timeout, took %v", d)
+	}
+}
+
+type testEvent struct{}
+
+func (e *testEvent) Topic() string { return "test" }
+
+func (e *testEvent) Message() *broker.Message { return &broker.Message{} }
+
+func (e *testEvent) Ack() error { return nil }
+
+func (e *testEvent) Error() error { return nil }
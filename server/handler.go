@@ -1,6 +1,9 @@
 package server
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type HandlerOption func(*HandlerOptions)
 
@@ -17,7 +20,17 @@ type SubscriberOptions struct {
 	AutoAck  bool
 	Queue    string
 	Internal bool
-	Context  context.Context
+	// Concurrency limits the handlers running at once
+	Concurrency int
+	// Prefetch limits the messages received but not yet handled.
+	// Brokers are held back while the limit is reached.
+	Prefetch int
+	// BatchSize is the most messages passed at once
+	// to a subscriber which takes a slice of them
+	BatchSize int
+	// BatchWait is how long to wait for a batch to fill
+	BatchWait time.Duration
+	Context   context.Context
 }
 
 // EndpointMetadata is a Handler option that allows metadata to be added to
@@ -78,3 +91,29 @@ func SubscriberContext(ctx context.Context) SubscriberOption {
 		o.Context = ctx
 	}
 }
+
+// SubscriberConcurrency limits the number of handlers running at once
+func SubscriberConcurrency(n int) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.Concurrency = n
+	}
+}
+
+// SubscriberPrefetch limits the number of messages received but not yet handled
+func SubscriberPrefetch(n int) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.Prefetch = n
+	}
+}
+
+// SubscriberBatch passes up to size messages at once to a subscriber taking a
+// slice of them, Subscribe rejects subscribers which don't. A batch is handled
+// when it's full or wait has passed since its first message. Every message of the batch gets the error it returns.
+// Subscriber wrappers are called for each message of the batch and only the
+// messages passed on by all of them are handed to the subscriber.
+func SubscriberBatch(size int, wait time.Duration) SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.BatchSize = size
+		o.BatchWait = wait
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// CheckType is the kind of health check
+type CheckType int
+
+const (
+	// Liveness checks fail when the server is broken,
+	// marking the node critical
+	Liveness CheckType = iota
+	// Readiness checks fail when the server can't serve
+	// requests yet, marking the node warning
+	Readiness
+)
+
+// String returns human readable check type
+func (t CheckType) String() string {
+	switch t {
+	case Liveness:
+		return "liveness"
+	case Readiness:
+		return "readiness"
+	default:
+		return "unknown"
+	}
+}
+
+// Check is a health check run each time the server registers
+type Check struct {
+	Name  string
+	Type  CheckType
+	Check func(context.Context) error
+}
+
+// CheckResult is the outcome of a health check
+type CheckResult struct {
+	Name  string
+	Type  CheckType
+	Error error
+}
+
+// Health runs the checks and returns the resulting node status along
+// with the result of each check. A failing liveness check makes the
+// node critical, otherwise a failing readiness check makes it warning.
+func Health(ctx context.Context, checks []*Check) (registry.Status, []*CheckResult) {
+	status := registry.StatusPassing
+	results := make([]*CheckResult, 0, len(checks))
+
+	for _, c := range checks {
+		err := c.Check(ctx)
+		results = append(results, &CheckResult{
+			Name:  c.Name,
+			Type:  c.Type,
+			Error: err,
+		})
+
+		if err == nil {
+			continue
+		}
+
+		switch c.Type {
+		case Liveness:
+			status = registry.StatusCritical
+		case Readiness:
+			if status == registry.StatusPassing {
+				status = registry.StatusWarning
+			}
+		}
+	}
+
+	return status, results
+}
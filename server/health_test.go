@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestHealth(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("failed") }
+
+	testData := []struct {
+		checks []*Check
+		status registry.Status
+	}{
+		{nil, registry.StatusPassing},
+		{[]*Check{{"db", Liveness, ok}, {"cache", Readiness, ok}}, registry.StatusPassing},
+		{[]*Check{{"db", Liveness, ok}, {"cache", Readiness, fail}}, registry.StatusWarning},
+		{[]*Check{{"db", Liveness, fail}, {"cache", Readiness, fail}}, registry.StatusCritical},
+		{[]*Check{{"cache", Readiness, fail}, {"db", Liveness, fail}}, registry.StatusCritical},
+	}
+
+	for _, d := range testData {
+		status, results := Health(context.Background(), d.checks)
+		if status != d.status {
+			t.Fatalf("expected %s, got %s", d.status, status)
+		}
+		if len(results) != len(d.checks) {
+			t.Fatalf("expected %d results, got %d", len(d.checks), len(results))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package inbox deduplicates the messages handled by subscribers.
+//
+// Brokers deliver messages at least once. The inbox records the id of every
+// message a subscriber handled in a store so a redelivered message is acked
+// without running the subscriber again. Duplicates arriving at the same time
+// wait on a lock for the first one to finish.
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/sync"
+	"github.com/asim/go-micro/v3/util/keylock"
+)
+
+var (
+	DefaultPrefix  = "inbox/"
+	DefaultTTL     = 24 * time.Hour
+	DefaultHeader  = "Micro-Id"
+	DefaultLockTTL = time.Minute
+)
+
+// result is the record of a processed message
+type result struct {
+	Id         string    `json:"id"`
+	Topic      string    `json:"topic"`
+	Subscriber string    `json:"subscriber,omitempty"`
+	Processed  time.Time `json:"processed"`
+}
+
+type inbox struct {
+	opts  Options
+	locks keylock.Locks
+}
+
+// NewSubscriberWrapper returns a subscriber wrapper which handles each message
+// once per subscriber. Only messages handled without an error are recorded so
+// failed messages are handled again when redelivered.
+func NewSubscriberWrapper(opts ...Option) server.SubscriberWrapper {
+	options := Options{
+		Store:   store.DefaultStore,
+		Prefix:  DefaultPrefix,
+		TTL:     DefaultTTL,
+		Header:  DefaultHeader,
+		LockTTL: DefaultLockTTL,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	i := &inbox{opts: options}
+
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			return i.handle(ctx, msg, fn)
+		}
+	}
+}
+
+func (i *inbox) handle(ctx context.Context, msg server.Message, fn server.SubscriberFunc) error {
+	id := msg.Header()[i.opts.Header]
+	if len(id) == 0 {
+		return fn(ctx, msg)
+	}
+
+	sub, _ := server.SubscriberFromContext(ctx)
+	key := i.opts.Prefix + msg.Topic() + "/"
+	if len(sub) > 0 {
+		key += sub + "/"
+	}
+	key += id
+
+	if ok, err := i.processed(key); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	unlock, err := i.lock(key)
+	if err != nil {
+		// left unacked to be redelivered
+		return err
+	}
+	defer unlock()
+
+	// a duplicate may have been processed while waiting
+	if ok, err := i.processed(key); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	if err := fn(ctx, msg); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&result{
+		Id:         id,
+		Topic:      msg.Topic(),
+		Subscriber: sub,
+		Processed:  time.Now(),
+	})
+	if err == nil {
+		err = i.opts.Store.Write(&store.Record{Key: key, Value: b}, store.WriteTTL(i.opts.TTL))
+	}
+	if err != nil && logger.V(logger.WarnLevel, logger.DefaultLogger) {
+		// processed so it's acked even though a redelivery won't be caught
+		logger.Warnf("Inbox failed to record message %s on %s: %v", id, msg.Topic(), err)
+	}
+
+	return nil
+}
+
+func (i *inbox) processed(key string) (bool, error) {
+	recs, err := i.opts.Store.Read(key)
+	if err == store.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return len(recs) > 0, nil
+}
+
+func (i *inbox) lock(key string) (func(), error) {
+	if i.opts.Sync == nil {
+		i.locks.Lock(key)
+		return func() { i.locks.Unlock(key) }, nil
+	}
+
+	if err := i.opts.Sync.Lock(key, sync.LockTTL(i.opts.LockTTL)); err != nil {
+		return nil, err
+	}
+	return func() { i.opts.Sync.Unlock(key) }, nil
+}
@@ -0,0 +1,153 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/codec"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/transport"
+)
+
+type testMessage struct {
+	id string
+}
+
+func (m *testMessage) Topic() string             { return "orders" }
+func (m *testMessage) Payload() interface{}      { return nil }
+func (m *testMessage) ContentType() string       { return "application/json" }
+func (m *testMessage) Header() map[string]string { return map[string]string{"Micro-Id": m.id} }
+func (m *testMessage) Body() []byte              { return []byte("{}") }
+func (m *testMessage) Codec() codec.Reader       { return nil }
+
+func TestInbox(t *testing.T) {
+	var calls int32
+	var fail int32 = 1
+
+	fn := NewSubscriberWrapper(Store(store.NewMemoryStore()), TTL(time.Millisecond*200))(func(ctx context.Context, msg server.Message) error {
+		atomic.AddInt32(&calls, 1)
+		// processing takes a while so duplicates overlap
+		time.Sleep(time.Millisecond * 20)
+		if atomic.CompareAndSwapInt32(&fail, 1, 0) {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	// failures aren't recorded so the redelivery runs again
+	if err := fn(context.TODO(), &testMessage{"1"}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(context.TODO(), &testMessage{"1"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected 2 calls got %d", n)
+	}
+
+	// messages without an id are always handled
+	for i := 0; i < 2; i++ {
+		if err := fn(context.TODO(), &testMessage{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Fatalf("expected 4 calls got %d", n)
+	}
+
+	// handled again once the record expires
+	time.Sleep(time.Millisecond * 300)
+	if err := fn(context.TODO(), &testMessage{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 5 {
+		t.Fatalf("expected 5 calls got %d", n)
+	}
+}
+
+type Order struct {
+	Id int `json:"id"`
+}
+
+func TestSubscribers(t *testing.T) {
+	r := registry.NewMemoryRegistry()
+	b := broker.NewBroker(broker.Registry(r))
+
+	srv := server.NewServer(
+		server.Name("orders"),
+		server.Registry(r),
+		server.Broker(b),
+		server.Transport(transport.NewMemoryTransport()),
+		server.WrapSubscriber(NewSubscriberWrapper(Store(store.NewMemoryStore()))),
+	)
+
+	// each subscriber of the topic handles the message once
+	var mtx sync.Mutex
+	calls := make(map[string][]int)
+	handler := func(name string, o *Order) error {
+		mtx.Lock()
+		calls[name] = append(calls[name], o.Id)
+		mtx.Unlock()
+		return nil
+	}
+	billing := func(ctx context.Context, o *Order) error {
+		return handler("billing", o)
+	}
+	shipping := func(ctx context.Context, o *Order) error {
+		return handler("shipping", o)
+	}
+	for _, fn := range []interface{}{billing, shipping} {
+		if err := srv.Subscribe(srv.NewSubscriber("orders", fn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	for _, id := range []int{1, 1, 2} {
+		body, _ := json.Marshal(&Order{Id: id})
+		err := b.Publish("orders", &broker.Message{
+			Header: map[string]string{
+				"Content-Type": "application/json",
+				"Micro-Topic":  "orders",
+				"Micro-Id":     strconv.Itoa(id),
+			},
+			Body: body,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// wait for any duplicates to arrive
+	time.Sleep(time.Millisecond * 500)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, name := range []string{"billing", "shipping"} {
+		if len(calls[name]) != 2 {
+			t.Fatalf("expected %s to handle 2 messages got %v", name, calls[name])
+		}
+	}
+}
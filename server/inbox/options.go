@@ -0,0 +1,68 @@
+package inbox
+
+import (
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/sync"
+)
+
+type Options struct {
+	// Store the processed messages are recorded in
+	Store store.Store
+	// Prefix of the keys in the store
+	Prefix string
+	// TTL is how long processed messages are remembered
+	TTL time.Duration
+	// Header holding the id of the message
+	Header string
+	// Sync locks messages across instances while they're processed.
+	// Messages are only locked within the process when it's not set.
+	Sync sync.Sync
+	// LockTTL is how long a lock is held when the instance holding it dies
+	LockTTL time.Duration
+}
+
+type Option func(o *Options)
+
+// Store sets the store processed messages are recorded in
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Prefix sets the prefix of the keys in the store
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// TTL sets how long processed messages are remembered
+func TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// Header sets the header holding the id of the message
+func Header(h string) Option {
+	return func(o *Options) {
+		o.Header = h
+	}
+}
+
+// Sync sets the sync used to lock messages across instances
+func Sync(s sync.Sync) Option {
+	return func(o *Options) {
+		o.Sync = s
+	}
+}
+
+// LockTTL sets how long a lock is held when the instance holding it dies
+func LockTTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.LockTTL = d
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// MetadataSetter is implemented by servers which can change the metadata of
+// their node while running. Init must not be used for it once the server has
+// started since it resets the handlers and subscribers of the router.
+type MetadataSetter interface {
+	// SetMetadata sets the node metadata key and registers the node again
+	SetMetadata(key, value string) error
+}
+
+func (s *rpcServer) SetMetadata(key, value string) error {
+	s.Lock()
+	md := metadata.Copy(s.opts.Metadata)
+	md[key] = value
+	s.opts.Metadata = md
+
+	// update the cached service registered on each interval
+	if s.rsvc != nil {
+		srv := new(registry.Service)
+		*srv = *s.rsvc
+		srv.Nodes = make([]*registry.Node, len(s.rsvc.Nodes))
+		for i, n := range s.rsvc.Nodes {
+			node := &registry.Node{
+				Id:       n.Id,
+				Address:  n.Address,
+				Metadata: metadata.Copy(n.Metadata),
+			}
+			node.Metadata[key] = value
+			srv.Nodes[i] = node
+		}
+		s.rsvc = srv
+	}
+	registered := s.registered
+	s.Unlock()
+
+	if !registered {
+		return nil
+	}
+	return s.Register()
+}
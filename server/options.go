@@ -34,6 +34,10 @@ type Options struct {
 	RegisterTTL time.Duration
 	// 注册的间隔事件
 	RegisterInterval time.Duration
+	// Checks 健康检查，每次注册时运行以确定节点的状态
+	Checks []*Check
+	// DrainTimeout 停止时等待正在处理的请求完成的最长时间
+	DrainTimeout time.Duration
 
 	// 请求的路由器
 	Router Router
@@ -51,6 +55,7 @@ func newOptions(opt ...Option) Options {
 		Metadata:         map[string]string{},
 		RegisterInterval: DefaultRegisterInterval,
 		RegisterTTL:      DefaultRegisterTTL,
+		DrainTimeout:     DefaultDrainTimeout,
 	}
 
 	for _, o := range opt {
@@ -183,6 +188,27 @@ func RegisterCheck(fn func(context.Context) error) Option {
 	}
 }
 
+// LivenessCheck 添加一个存活检查，失败时节点状态为 critical
+func LivenessCheck(name string, fn func(context.Context) error) Option {
+	return func(o *Options) {
+		o.Checks = append(o.Checks, &Check{Name: name, Type: Liveness, Check: fn})
+	}
+}
+
+// ReadinessCheck 添加一个就绪检查，失败时节点状态为 warning
+func ReadinessCheck(name string, fn func(context.Context) error) Option {
+	return func(o *Options) {
+		o.Checks = append(o.Checks, &Check{Name: name, Type: Readiness, Check: fn})
+	}
+}
+
+// DrainTimeout 指定停止时排空请求的最长时间，为 0 时不排空
+func DrainTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = t
+	}
+}
+
 // 指定注册服务的 TTL
 func RegisterTTL(t time.Duration) Option {
 	return func(o *Options) {
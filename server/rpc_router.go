@@ -527,6 +527,11 @@ func (router *router) ProcessMessage(ctx context.Context, msg Message) (err erro
 
 	// we may have multiple subscribers for the topic
 	for _, sub := range subs {
+		// batches are dispatched separately
+		if sub.opts.BatchSize > 0 {
+			continue
+		}
+
 		// we may have multiple handlers per subscriber
 		for i := 0; i < len(sub.handlers); i++ {
 			// get the handler
@@ -597,7 +602,7 @@ func (router *router) ProcessMessage(ctx context.Context, msg Message) (err erro
 			}
 
 			// execute the message handler
-			if err = fn(ctx, rpcMsg); err != nil {
+			if err = fn(newSubscriberContext(ctx, handler.name), rpcMsg); err != nil {
 				errResults = append(errResults, err.Error())
 			}
 		}
@@ -610,3 +615,173 @@ func (router *router) ProcessMessage(ctx context.Context, msg Message) (err erro
 
 	return err
 }
+
+// wrapBatch passes each message through the subscriber wrappers on its own and
+// calls fn once with the messages which passed all of them, so wrappers can skip
+// or reject single messages of a batch. The wrappers get the context of their
+// message and the error of the batch, fn gets the context of the first message.
+func (router *router) wrapBatch(name string, ctxs []context.Context, typ reflect.Type, msgs []*rpcMessage, reqs []reflect.Value, fn func(context.Context, reflect.Value) error) []string {
+	var arrived, finished sync.WaitGroup
+	arrived.Add(len(msgs))
+	finished.Add(len(msgs))
+
+	reached := make([]bool, len(msgs))
+	errs := make([]error, len(msgs))
+	done := make(chan struct{})
+	var batchErr error
+
+	for i := range msgs {
+		go func(i int) {
+			defer finished.Done()
+
+			var once sync.Once
+			arrive := func() { once.Do(arrived.Done) }
+			// not every wrapper calls the handler
+			defer arrive()
+
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("panic recovered: %v", r)
+					log.Error(string(debug.Stack()))
+					errs[i] = merrors.InternalServerError("go.micro.server", "panic recovered: %v", r)
+				}
+			}()
+
+			h := func(ctx context.Context, msg Message) error {
+				reached[i] = true
+				arrive()
+				<-done
+				return batchErr
+			}
+			for j := len(router.subWrappers); j > 0; j-- {
+				h = router.subWrappers[j-1](h)
+			}
+
+			msg := msgs[i]
+			errs[i] = h(newSubscriberContext(ctxs[i], name), &rpcMessage{
+				topic:       msg.Topic(),
+				contentType: msg.ContentType(),
+				payload:     reqs[i].Interface(),
+				codec:       msg.codec,
+				header:      msg.Header(),
+				body:        msg.Body(),
+			})
+		}(i)
+	}
+
+	arrived.Wait()
+
+	batch := reflect.MakeSlice(typ, 0, len(reqs))
+	for i, req := range reqs {
+		if reached[i] {
+			batch = reflect.Append(batch, req)
+		}
+	}
+	if batch.Len() > 0 {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("panic recovered: %v", r)
+					log.Error(string(debug.Stack()))
+					batchErr = merrors.InternalServerError("go.micro.server", "panic recovered: %v", r)
+				}
+			}()
+			batchErr = fn(newSubscriberContext(ctxs[0], name), batch)
+		}()
+	}
+	close(done)
+
+	finished.Wait()
+
+	// the error of the batch is returned for every message so only report it once
+	var results []string
+	seen := make(map[string]bool)
+	for _, err := range errs {
+		if err == nil || seen[err.Error()] {
+			continue
+		}
+		seen[err.Error()] = true
+		results = append(results, err.Error())
+	}
+	return results
+}
+
+// processBatch decodes the messages into the slice taken by the subscriber.
+// ctxs holds the context of each message.
+func (router *router) processBatch(ctxs []context.Context, sub *subscriber, msgs []*rpcMessage) (err error) {
+	defer func() {
+		// recover any panics
+		if r := recover(); r != nil {
+			log.Errorf("panic recovered: %v", r)
+			log.Error(string(debug.Stack()))
+			err = merrors.InternalServerError("go.micro.server", "panic recovered: %v", r)
+		}
+	}()
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	var errResults []string
+
+	for i := 0; i < len(sub.handlers); i++ {
+		handler := sub.handlers[i]
+		elemType := handler.reqType.Elem()
+		reqs := make([]reflect.Value, 0, len(msgs))
+
+		for _, msg := range msgs {
+			var req reflect.Value
+
+			// check whether the element is a pointer
+			if elemType.Kind() == reflect.Ptr {
+				req = reflect.New(elemType.Elem())
+			} else {
+				req = reflect.New(elemType)
+			}
+
+			cc := msg.Codec()
+
+			// read the header. mostly a noop
+			if err = cc.ReadHeader(&codec.Message{}, codec.Event); err != nil {
+				return err
+			}
+
+			// read the body into the message value
+			if err = cc.ReadBody(req.Interface()); err != nil {
+				return err
+			}
+
+			if elemType.Kind() != reflect.Ptr {
+				req = req.Elem()
+			}
+
+			reqs = append(reqs, req)
+		}
+
+		fn := func(ctx context.Context, batch reflect.Value) error {
+			var vals []reflect.Value
+			if sub.typ.Kind() != reflect.Func {
+				vals = append(vals, sub.rcvr)
+			}
+			if handler.ctxType != nil {
+				vals = append(vals, reflect.ValueOf(ctx))
+			}
+
+			vals = append(vals, batch)
+
+			returnValues := handler.method.Call(vals)
+			if rerr := returnValues[0].Interface(); rerr != nil {
+				return rerr.(error)
+			}
+			return nil
+		}
+
+		errResults = append(errResults, router.wrapBatch(handler.name, ctxs, handler.reqType, msgs, reqs, fn)...)
+	}
+
+	if len(errResults) > 0 {
+		err = merrors.InternalServerError("go.micro.server", "subscriber error: %v", strings.Join(errResults, "\n"))
+	}
+
+	return err
+}
@@ -10,22 +10,30 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asim/go-micro/v3/broker"
 	"github.com/asim/go-micro/v3/codec"
 	raw "github.com/asim/go-micro/v3/codec/bytes"
+	"github.com/asim/go-micro/v3/errors"
 	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/transport"
 	"github.com/asim/go-micro/v3/util/addr"
 	"github.com/asim/go-micro/v3/util/backoff"
+	"github.com/asim/go-micro/v3/util/cloudevents"
 	mnet "github.com/asim/go-micro/v3/util/net"
 	"github.com/asim/go-micro/v3/util/socket"
 )
 
 type rpcServer struct {
+	// requests being processed, accessed atomically
+	active int64
+	// set while draining, accessed atomically
+	draining int32
+
 	router *router
 	exit   chan chan error
 
@@ -43,6 +51,8 @@ type rpcServer struct {
 	wg *sync.WaitGroup
 
 	rsvc *registry.Service
+	// last health status registered
+	status registry.Status
 }
 
 func newRpcServer(opts ...Option) Server {
@@ -64,14 +74,74 @@ func newRpcServer(opts ...Option) Server {
 // HandleEvent handles inbound messages to the service directly
 // TODO: handle requests from an event. We won't send a response.
 func (s *rpcServer) HandleEvent(e broker.Event) error {
-	// formatting horrible cruft
-	msg := e.Message()
+	if !s.begin() {
+		return errDraining(s.Options().Name)
+	}
+	defer s.end()
+
+	ctx, rpcMsg, err := s.newMessage(e.Message())
+	if err != nil {
+		return err
+	}
+
+	// existing router
+	r := Router(s.router)
+
+	// if the router is present then execute it
+	if s.opts.Router != nil {
+		// create a wrapped function
+		handler := s.opts.Router.ProcessMessage
+
+		// execute the wrapper for it
+		for i := len(s.opts.SubWrappers); i > 0; i-- {
+			handler = s.opts.SubWrappers[i-1](handler)
+		}
+
+		// set the router
+		r = rpcRouter{m: handler}
+	}
+
+	return r.ProcessMessage(ctx, rpcMsg)
+}
 
+// handleBatch passes the events to a subscriber taking a batch of messages
+func (s *rpcServer) handleBatch(sb *subscriber, events []broker.Event) error {
+	if !s.begin() {
+		return errDraining(s.Options().Name)
+	}
+	defer s.end()
+
+	ctxs := make([]context.Context, 0, len(events))
+	msgs := make([]*rpcMessage, 0, len(events))
+
+	for _, e := range events {
+		ctx, rpcMsg, err := s.newMessage(e.Message())
+		if err != nil {
+			return err
+		}
+		ctxs = append(ctxs, ctx)
+		msgs = append(msgs, rpcMsg)
+	}
+
+	return s.router.processBatch(ctxs, sb, msgs)
+}
+
+// newMessage decodes a broker message and returns it with its context
+func (s *rpcServer) newMessage(msg *broker.Message) (context.Context, *rpcMessage, error) {
 	if msg.Header == nil {
 		// create empty map in case of headers empty to avoid panic later
 		msg.Header = make(map[string]string)
 	}
 
+	// unwrap cloud events
+	ev, hdr, body, err := cloudevents.Unmarshal(msg.Header, msg.Body)
+	if err != nil {
+		return nil, nil, errors.BadRequest("go.micro.server", "invalid cloud event: %v", err)
+	}
+	if ev != nil {
+		msg = &broker.Message{Header: hdr, Body: body}
+	}
+
 	// get codec
 	ct := msg.Header["Content-Type"]
 
@@ -84,17 +154,20 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 	// get codec
 	cf, err := s.newCodec(ct)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// copy headers
-	hdr := make(map[string]string, len(msg.Header))
+	hdr = make(map[string]string, len(msg.Header))
 	for k, v := range msg.Header {
 		hdr[k] = v
 	}
 
 	// create context
 	ctx := metadata.NewContext(context.Background(), hdr)
+	if ev != nil {
+		ctx = cloudevents.NewContext(ctx, ev)
+	}
 
 	// TODO: inspect message header
 	// Micro-Service means a request
@@ -109,24 +182,7 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 		body:        msg.Body,
 	}
 
-	// existing router
-	r := Router(s.router)
-
-	// if the router is present then execute it
-	if s.opts.Router != nil {
-		// create a wrapped function
-		handler := s.opts.Router.ProcessMessage
-
-		// execute the wrapper for it
-		for i := len(s.opts.SubWrappers); i > 0; i-- {
-			handler = s.opts.SubWrappers[i-1](handler)
-		}
-
-		// set the router
-		r = rpcRouter{m: handler}
-	}
-
-	return r.ProcessMessage(ctx, rpcMsg)
+	return ctx, rpcMsg, nil
 }
 
 // ServeConn serves a single connection
@@ -419,8 +475,18 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 				}
 			}()
 
-			// serve the actual request using the request router
-			if serveRequestError := r.ServeRequest(ctx, request, response); serveRequestError != nil {
+			var serveRequestError error
+
+			// reject new requests while draining so the client retries another node
+			if s.begin() {
+				// serve the actual request using the request router
+				serveRequestError = r.ServeRequest(ctx, request, response)
+				s.end()
+			} else {
+				serveRequestError = errDraining(request.Service())
+			}
+
+			if serveRequestError != nil {
 				// write an error response
 				writeError := rcodec.Write(&codec.Message{
 					Header: msg.Header,
@@ -541,9 +607,12 @@ func (s *rpcServer) Register() error {
 		return regErr
 	}
 
+	// run the health checks
+	status := s.health(config)
+
 	// have we registered before?
 	if rsvc != nil {
-		if err := regFunc(rsvc); err != nil {
+		if err := regFunc(withStatus(rsvc, status)); err != nil {
 			return err
 		}
 		return nil
@@ -601,6 +670,7 @@ func (s *rpcServer) Register() error {
 	node.Metadata["server"] = s.String()
 	node.Metadata["registry"] = config.Registry.String()
 	node.Metadata["protocol"] = "mucp"
+	registry.SetNodeStatus(node, status)
 
 	s.RLock()
 
@@ -698,7 +768,10 @@ func (s *rpcServer) Register() error {
 			opts = append(opts, broker.DisableAutoAck())
 		}
 
-		sub, err := config.Broker.Subscribe(sb.Topic(), s.HandleEvent, opts...)
+		// the concurrency and prefetch limits are applied by the dispatcher
+		// rather than the broker so they're the same with every broker and
+		// count batched messages once
+		sub, err := config.Broker.Subscribe(sb.Topic(), s.newDispatcher(sb).Handle, opts...)
 		if err != nil {
 			return err
 		}
@@ -715,6 +788,65 @@ func (s *rpcServer) Register() error {
 	return nil
 }
 
+// health runs the health checks and logs any change in status
+func (s *rpcServer) health(config Options) registry.Status {
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// draining nodes stay draining whatever their checks say
+	if s.isDraining() {
+		s.Lock()
+		s.status = registry.StatusDraining
+		s.Unlock()
+		return registry.StatusDraining
+	}
+
+	status, results := Health(ctx, config.Checks)
+
+	s.Lock()
+	prev := s.status
+	s.status = status
+	s.Unlock()
+
+	if status != prev && len(prev) > 0 {
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			log.Infof("Server %s-%s health changed from %s to %s", config.Name, config.Id, prev, status)
+		}
+	}
+
+	for _, r := range results {
+		if r.Error == nil {
+			continue
+		}
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			log.Warnf("Server %s-%s %s check %s failed: %v", config.Name, config.Id, r.Type, r.Name, r.Error)
+		}
+	}
+
+	return status
+}
+
+// withStatus returns a copy of the service with the node status set
+func withStatus(service *registry.Service, status registry.Status) *registry.Service {
+	srv := new(registry.Service)
+	*srv = *service
+	srv.Nodes = make([]*registry.Node, len(service.Nodes))
+
+	for i, n := range service.Nodes {
+		node := &registry.Node{
+			Id:       n.Id,
+			Address:  n.Address,
+			Metadata: metadata.Copy(n.Metadata),
+		}
+		registry.SetNodeStatus(node, status)
+		srv.Nodes[i] = node
+	}
+
+	return srv
+}
+
 func (s *rpcServer) Deregister() error {
 	var err error
 	var advt, host, port string
@@ -779,6 +911,17 @@ func (s *rpcServer) Deregister() error {
 	}
 
 	s.registered = false
+	s.Unlock()
+
+	s.unsubscribe(node.Id)
+
+	return nil
+}
+
+// unsubscribe closes the router and broker subscribers
+func (s *rpcServer) unsubscribe(node string) {
+	s.Lock()
+	defer s.Unlock()
 
 	// close the subscriber
 	if s.subscriber != nil {
@@ -789,15 +932,12 @@ func (s *rpcServer) Deregister() error {
 	for sb, subs := range s.subscribers {
 		for _, sub := range subs {
 			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-				log.Infof("Unsubscribing %s from topic: %s", node.Id, sub.Topic())
+				log.Infof("Unsubscribing %s from topic: %s", node, sub.Topic())
 			}
 			sub.Unsubscribe()
 		}
 		s.subscribers[sb] = nil
 	}
-
-	s.Unlock()
-	return nil
 }
 
 func (s *rpcServer) Start() error {
@@ -808,6 +948,9 @@ func (s *rpcServer) Start() error {
 	}
 	s.RUnlock()
 
+	// no longer draining if restarted
+	atomic.StoreInt32(&s.draining, 0)
+
 	config := s.Options()
 
 	// start listening on the transport
@@ -929,6 +1072,9 @@ func (s *rpcServer) Start() error {
 			// wait for exit
 			case ch = <-s.exit:
 				t.Stop()
+				// drain while still accepting connections so
+				// new requests are told to go elsewhere
+				s.drain()
 				close(exit)
 				break Loop
 			}
@@ -146,6 +146,7 @@ var (
 	DefaultRegisterCheck           = func(context.Context) error { return nil }
 	DefaultRegisterInterval        = time.Second * 30
 	DefaultRegisterTTL             = time.Second * 90
+	DefaultDrainTimeout            = time.Second * 10
 
 	// NewServer creates a new server
 	NewServer func(...Option) Server = newRpcServer
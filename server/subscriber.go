@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 
 	"github.com/asim/go-micro/v3/registry"
 )
@@ -12,6 +13,7 @@ const (
 )
 
 type handler struct {
+	name    string
 	method  reflect.Value
 	reqType reflect.Type
 	ctxType reflect.Type
@@ -41,6 +43,7 @@ func newSubscriber(topic string, sub interface{}, opts ...SubscriberOption) Subs
 
 	if typ := reflect.TypeOf(sub); typ.Kind() == reflect.Func {
 		h := &handler{
+			name:   runtime.FuncForPC(reflect.ValueOf(sub).Pointer()).Name(),
 			method: reflect.ValueOf(sub),
 		}
 
@@ -69,6 +72,7 @@ func newSubscriber(topic string, sub interface{}, opts ...SubscriberOption) Subs
 		for m := 0; m < typ.NumMethod(); m++ {
 			method := typ.Method(m)
 			h := &handler{
+				name:   name + "." + method.Name,
 				method: method.Func,
 			}
 
@@ -119,6 +123,9 @@ func validateSubscriber(sub Subscriber) error {
 		if !isExportedOrBuiltinType(argType) {
 			return fmt.Errorf("subscriber %v argument type not exported: %v", name, argType)
 		}
+		if sub.Options().BatchSize > 0 && argType.Kind() != reflect.Slice {
+			return fmt.Errorf("subscriber %v batches messages but doesn't take a slice of them: %v", name, argType)
+		}
 		if typ.NumOut() != 1 {
 			return fmt.Errorf("subscriber %v has wrong number of outs: %v require signature %s",
 				name, typ.NumOut(), subSig)
@@ -144,6 +151,9 @@ func validateSubscriber(sub Subscriber) error {
 			if !isExportedOrBuiltinType(argType) {
 				return fmt.Errorf("%v argument type not exported: %v", name, argType)
 			}
+			if sub.Options().BatchSize > 0 && argType.Kind() != reflect.Slice {
+				return fmt.Errorf("subscriber %v.%v batches messages but doesn't take a slice of them: %v", name, method.Name, argType)
+			}
 			if method.Type.NumOut() != 1 {
 				return fmt.Errorf(
 					"subscriber %v.%v has wrong number of outs: %v require signature %s",
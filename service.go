@@ -18,6 +18,7 @@ import (
 	plugin "github.com/asim/go-micro/v3/plugins"
 	"github.com/asim/go-micro/v3/server"
 	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/util/cloudevents"
 	signalutil "github.com/asim/go-micro/v3/util/signal"
 	"github.com/asim/go-micro/v3/util/wrapper"
 )
@@ -35,6 +36,9 @@ func newService(opts ...Option) Service {
 	// 服务名称
 	serviceName := options.Server.Options().Name
 
+	// 以服务名称作为 CloudEvents 的 source
+	eventSource(options)
+
 	// 包装客户端以在任何调用中注入From-Service头
 	options.Client = wrapper.FromService(serviceName, options.Client)
 	options.Client = wrapper.TraceCall(serviceName, trace.DefaultTracer, options.Client)
@@ -51,6 +55,13 @@ func newService(opts ...Option) Service {
 	return service
 }
 
+// eventSource 在未设置 source 时以服务名称作为 CloudEvents 的 source
+func eventSource(o Options) {
+	if co := o.Client.Options(); co.CloudEvents != cloudevents.None && len(co.EventSource) == 0 {
+		o.Client.Init(client.CloudEvents(co.CloudEvents, o.Server.Options().Name))
+	}
+}
+
 func (s *service) Name() string {
 	return s.opts.Server.Options().Name
 }
@@ -62,6 +73,8 @@ func (s *service) Init(opts ...Option) {
 		o(&s.opts)
 	}
 
+	eventSource(s.opts)
+
 	s.once.Do(func() {
 		// 设置 plugin
 		for _, p := range strings.Split(os.Getenv("MICRO_PLUGIN"), ",") {
@@ -180,7 +193,7 @@ func (s *service) Run() error {
 	// 注册调试处理
 	s.opts.Server.Handle(
 		s.opts.Server.NewHandler(
-			handler.NewHandler(s.opts.Client),
+			handler.NewHandler(s.opts.Client, handler.Config(s.opts.Config)),
 			server.InternalHandler(true),
 		),
 	)
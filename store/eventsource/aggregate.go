@@ -0,0 +1,135 @@
+package eventsource
+
+import (
+	"encoding/json"
+
+	"github.com/asim/go-micro/v3/store"
+)
+
+// Aggregate is the state rebuilt from the events of a stream
+type Aggregate interface {
+	// Apply changes the state with the event
+	Apply(e *Event) error
+}
+
+// Snapshotter is implemented by aggregates which encode their own snapshots.
+// Other aggregates are snapshotted as json.
+type Snapshotter interface {
+	Snapshot() ([]byte, error)
+	Restore(b []byte) error
+}
+
+type snapshot struct {
+	Version int64  `json:"version"`
+	State   []byte `json:"state"`
+}
+
+// Repository loads and saves aggregates as streams of an event store
+type Repository struct {
+	opts  RepositoryOptions
+	store *EventStore
+}
+
+// NewRepository returns a repository of aggregates in the event store
+func NewRepository(s *EventStore, opts ...RepositoryOption) *Repository {
+	var options RepositoryOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Repository{
+		opts:  options,
+		store: s,
+	}
+}
+
+// Load rebuilds the aggregate from the latest snapshot and the events of the
+// stream after it. The version of the stream is returned to save with.
+func (r *Repository) Load(stream string, a Aggregate) (int64, error) {
+	var version int64
+
+	recs, err := r.store.opts.Store.Read(r.snapshotKey(stream))
+	if err != nil && err != store.ErrNotFound {
+		return 0, err
+	}
+	if len(recs) > 0 {
+		var snap snapshot
+		if err := json.Unmarshal(recs[0].Value, &snap); err != nil {
+			return 0, err
+		}
+		if s, ok := a.(Snapshotter); ok {
+			err = s.Restore(snap.State)
+		} else {
+			err = json.Unmarshal(snap.State, a)
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = snap.Version
+	}
+
+	events, err := r.store.Load(stream, version)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range events {
+		if err := a.Apply(e); err != nil {
+			return 0, err
+		}
+		version = e.Version
+	}
+
+	return version, nil
+}
+
+// Save appends the events to the stream when it's still at the version the
+// aggregate was loaded at and applies them to the aggregate. The new version
+// of the stream is returned.
+func (r *Repository) Save(stream string, a Aggregate, version int64, events ...*Event) (int64, error) {
+	err := r.store.Append(stream, version, events...)
+	if _, ok := err.(*PublishError); err != nil && !ok {
+		return version, err
+	}
+	if len(events) == 0 {
+		return version, err
+	}
+
+	// appended even when publishing failed so the aggregate moves on
+	for _, e := range events {
+		if err := a.Apply(e); err != nil {
+			return e.Version, err
+		}
+	}
+
+	last := events[len(events)-1].Version
+	if n := r.opts.SnapshotEvery; n > 0 && last/n > version/n {
+		if err := r.snapshot(stream, a, last); err != nil {
+			return last, err
+		}
+	}
+
+	return last, err
+}
+
+func (r *Repository) snapshot(stream string, a Aggregate, version int64) error {
+	var state []byte
+	var err error
+	if s, ok := a.(Snapshotter); ok {
+		state, err = s.Snapshot()
+	} else {
+		state, err = json.Marshal(a)
+	}
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(&snapshot{Version: version, State: state})
+	if err != nil {
+		return err
+	}
+	return r.store.opts.Store.Write(&store.Record{Key: r.snapshotKey(stream), Value: b})
+}
+
+func (r *Repository) snapshotKey(stream string) string {
+	return r.store.opts.Prefix + "snapshots/" + stream
+}
@@ -0,0 +1,302 @@
+// Package eventsource is an event sourcing toolkit built on the store and broker.
+//
+// Every aggregate has an append only stream of events kept in a store. Appends
+// are checked against the version of the stream the caller last saw so two
+// writers can't both extend the same version. Committed events are published
+// to the broker where projections consume them.
+package eventsource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/util/keylock"
+	"github.com/google/uuid"
+)
+
+var (
+	DefaultPrefix = "eventsource/"
+	DefaultTopic  = "go.micro.events"
+
+	// AnyVersion appends to a stream whatever its version
+	AnyVersion int64 = -1
+
+	// ErrConcurrency is returned when the stream isn't at the expected version
+	ErrConcurrency = errors.New("stream version conflict")
+
+	// Headers of published events
+	StreamHeader  = "Micro-Stream"
+	VersionHeader = "Micro-Stream-Version"
+	TypeHeader    = "Micro-Event-Type"
+)
+
+// Event is an event of a stream
+type Event struct {
+	Id     string `json:"id"`
+	Stream string `json:"stream"`
+	// Version of the stream the event is, starting at 1
+	Version   int64             `json:"version"`
+	Type      string            `json:"type"`
+	Data      []byte            `json:"data"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// NewEvent returns an event of the type with the value encoded as json
+func NewEvent(typ string, v interface{}) (*Event, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{Type: typ, Data: b}, nil
+}
+
+// Decode decodes the json data of the event into v
+func (e *Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// PublishError is returned when events were appended but not published
+type PublishError struct {
+	Err error
+}
+
+func (e *PublishError) Error() string {
+	return "events appended but not published: " + e.Err.Error()
+}
+
+// EventStore keeps the streams of events in a store
+type EventStore struct {
+	opts  Options
+	locks keylock.Locks
+}
+
+// NewEventStore returns an event store
+func NewEventStore(opts ...Option) *EventStore {
+	options := Options{
+		Store:  store.DefaultStore,
+		Prefix: DefaultPrefix,
+		Topic: func(*Event) string {
+			return DefaultTopic
+		},
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &EventStore{opts: options}
+}
+
+// Options returns the options of the event store
+func (s *EventStore) Options() Options {
+	return s.opts
+}
+
+// Append adds the events to the stream when it's at the expected version,
+// otherwise ErrConcurrency is returned. The id, stream, version and timestamp
+// of the events are set. Events are published once committed so a
+// PublishError leaves them appended. Appends from several instances are
+// only serialised with the Sync option.
+func (s *EventStore) Append(stream string, expected int64, events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	unlock, err := s.lock(stream)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	version, err := s.Version(stream)
+	if err != nil {
+		return err
+	}
+	if expected != AnyVersion && expected != version {
+		return ErrConcurrency
+	}
+
+	now := time.Now()
+	for _, e := range events {
+		version++
+		if len(e.Id) == 0 {
+			e.Id = uuid.New().String()
+		}
+		e.Stream = stream
+		e.Version = version
+		e.Timestamp = now
+
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if err := s.opts.Store.Write(&store.Record{Key: s.eventKey(stream, version), Value: b}); err != nil {
+			return err
+		}
+	}
+
+	// the events are committed once the head is moved past them
+	head := &store.Record{
+		Key:   s.opts.Prefix + "heads/" + stream,
+		Value: []byte(strconv.FormatInt(version, 10)),
+	}
+	if err := s.opts.Store.Write(head); err != nil {
+		return err
+	}
+
+	// the lock is only held within the process without a sync
+	if s.opts.Sync == nil {
+		if err := s.verify(stream, version, events); err != nil {
+			return err
+		}
+	}
+
+	if err := s.publish(events...); err != nil {
+		return &PublishError{err}
+	}
+	return nil
+}
+
+// Load returns the events of the stream after the version
+func (s *EventStore) Load(stream string, after int64) ([]*Event, error) {
+	version, err := s.Version(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*Event
+	for v := after + 1; v <= version; v++ {
+		recs, err := s.opts.Store.Read(s.eventKey(stream, v))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s version %d: %v", stream, v, err)
+		}
+		e := new(Event)
+		if err := json.Unmarshal(recs[0].Value, e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// Version returns the version of the stream, zero when it has no events
+func (s *EventStore) Version(stream string) (int64, error) {
+	recs, err := s.opts.Store.Read(s.opts.Prefix + "heads/" + stream)
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(recs[0].Value), 10, 64)
+}
+
+// Streams returns the names of the streams with events
+func (s *EventStore) Streams() ([]string, error) {
+	prefix := s.opts.Prefix + "heads/"
+	keys, err := s.opts.Store.List(store.ListPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	var streams []string
+	for _, k := range keys {
+		if strings.HasPrefix(k, prefix) {
+			streams = append(streams, strings.TrimPrefix(k, prefix))
+		}
+	}
+	sort.Strings(streams)
+
+	return streams, nil
+}
+
+// Publish publishes the events of the stream after the version again
+// e.g. after publishing failed on append
+func (s *EventStore) Publish(stream string, after int64) error {
+	events, err := s.Load(stream, after)
+	if err != nil {
+		return err
+	}
+	return s.publish(events...)
+}
+
+func (s *EventStore) publish(events ...*Event) error {
+	if s.opts.Broker == nil {
+		return nil
+	}
+
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		msg := &broker.Message{
+			Header: map[string]string{
+				"Micro-Id":    e.Id,
+				StreamHeader:  e.Stream,
+				VersionHeader: strconv.FormatInt(e.Version, 10),
+				TypeHeader:    e.Type,
+			},
+			Body: b,
+		}
+		// events of a stream are delivered in order
+		if err := s.opts.Broker.Publish(s.opts.Topic(e), msg, broker.OrderingKey(e.Stream)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verify reads the stream back after appending the events and returns
+// ErrConcurrency when another instance appended to it at the same time
+func (s *EventStore) verify(stream string, version int64, events []*Event) error {
+	head, err := s.Version(stream)
+	if err != nil {
+		return err
+	}
+	if head != version {
+		return ErrConcurrency
+	}
+
+	for _, e := range events {
+		recs, err := s.opts.Store.Read(s.eventKey(stream, e.Version))
+		if err != nil {
+			return err
+		}
+		saved := new(Event)
+		if err := json.Unmarshal(recs[0].Value, saved); err != nil {
+			return err
+		}
+		if saved.Id != e.Id {
+			return ErrConcurrency
+		}
+	}
+
+	return nil
+}
+
+func (s *EventStore) lock(stream string) (func(), error) {
+	if s.opts.Sync == nil {
+		s.locks.Lock(stream)
+		return func() { s.locks.Unlock(stream) }, nil
+	}
+
+	id := s.opts.Prefix + stream
+	if err := s.opts.Sync.Lock(id); err != nil {
+		return nil, err
+	}
+	return func() { s.opts.Sync.Unlock(id) }, nil
+}
+
+func (s *EventStore) eventKey(stream string, version int64) string {
+	// zero padded so the keys sort by version
+	return fmt.Sprintf("%sevents/%s/%020d", s.opts.Prefix, stream, version)
+}
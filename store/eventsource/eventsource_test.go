@@ -0,0 +1,311 @@
+package eventsource
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/store"
+)
+
+type account struct {
+	Balance int `json:"balance"`
+	// events applied since loaded, not part of the snapshot
+	applied int
+}
+
+type deposited struct {
+	Amount int `json:"amount"`
+}
+
+func (a *account) Apply(e *Event) error {
+	var d deposited
+	if err := e.Decode(&d); err != nil {
+		return err
+	}
+	a.Balance += d.Amount
+	a.applied++
+	return nil
+}
+
+func deposit(t *testing.T, amount int) *Event {
+	e, err := NewEvent("deposited", &deposited{amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestAppend(t *testing.T) {
+	s := NewEventStore(Store(store.NewMemoryStore()))
+
+	if err := s.Append("account-1", 0, deposit(t, 1), deposit(t, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append("account-1", 0, deposit(t, 3)); err != ErrConcurrency {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+	if err := s.Append("account-1", AnyVersion, deposit(t, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append("account-2", 0, deposit(t, 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := s.Load("account-1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Version != 2 || events[1].Version != 3 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if events[0].Stream != "account-1" || len(events[0].Id) == 0 {
+		t.Fatalf("unexpected event %+v", events[0])
+	}
+
+	streams, err := s.Streams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(streams) != "[account-1 account-2]" {
+		t.Fatalf("unexpected streams %v", streams)
+	}
+
+	// only one of the writers at the same version wins
+	var wg sync.WaitGroup
+	var mtx sync.Mutex
+	var ok int
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.Append("account-3", 0, deposit(t, i)); err == nil {
+				mtx.Lock()
+				ok++
+				mtx.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ok != 1 {
+		t.Fatalf("expected one append to succeed got %d", ok)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	s := NewEventStore(Store(store.NewMemoryStore()))
+	r := NewRepository(s, SnapshotEvery(2))
+
+	a := new(account)
+	version, err := r.Load("account-1", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		version, err = r.Save("account-1", a, version, deposit(t, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if version != 5 || a.Balance != 15 {
+		t.Fatalf("expected version 5 balance 15 got %d %d", version, a.Balance)
+	}
+
+	// a stale version is rejected
+	if _, err := r.Save("account-1", a, 3, deposit(t, 1)); err != ErrConcurrency {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+
+	// rebuilt from the snapshot at 4 and the fifth event
+	b := new(account)
+	version, err = r.Load("account-1", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 5 || b.Balance != 15 || b.applied != 1 {
+		t.Fatalf("expected version 5 balance 15 from 1 event got %d %d %d", version, b.Balance, b.applied)
+	}
+}
+
+type balances struct {
+	sync.Mutex
+	values map[string]int
+	seen   []int64
+}
+
+func (b *balances) handle(e *Event) error {
+	var d deposited
+	if err := e.Decode(&d); err != nil {
+		return err
+	}
+	b.Lock()
+	b.values[e.Stream] += d.Amount
+	b.seen = append(b.seen, e.Version)
+	b.Unlock()
+	return nil
+}
+
+func (b *balances) get(stream string) int {
+	b.Lock()
+	defer b.Unlock()
+	return b.values[stream]
+}
+
+func TestProjection(t *testing.T) {
+	br := broker.NewBroker(broker.Registry(registry.NewMemoryRegistry()))
+	if err := br.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer br.Disconnect()
+
+	s := NewEventStore(Store(store.NewMemoryStore()), Broker(br))
+	b := &balances{values: make(map[string]int)}
+	p := NewProjection("balances", s, b.handle, Reset(func() error {
+		b.Lock()
+		b.values = make(map[string]int)
+		b.Unlock()
+		return nil
+	}))
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	for i := 1; i <= 3; i++ {
+		if err := s.Append("account-1", AnyVersion, deposit(t, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for b.get("account-1") != 6 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected balance 6 got %d", b.get("account-1"))
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// redelivered events are skipped
+	events, err := s.Load("account-1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.handle(events[1]); err != nil {
+		t.Fatal(err)
+	}
+	if v := b.get("account-1"); v != 6 {
+		t.Fatalf("expected duplicate to be skipped got balance %d", v)
+	}
+
+	// events which were never published are read from the store
+	p.Stop()
+	s.opts.Broker = nil
+	for i := 0; i < 2; i++ {
+		if err := s.Append("account-1", AnyVersion, deposit(t, 10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err = s.Load("account-1", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.handle(events[0]); err != nil {
+		t.Fatal(err)
+	}
+	if v := b.get("account-1"); v != 26 {
+		t.Fatalf("expected missed events to be projected got balance %d", v)
+	}
+	if v, _ := p.Checkpoint("account-1"); v != 5 {
+		t.Fatalf("expected checkpoint 5 got %d", v)
+	}
+
+	if err := s.Append("account-2", 0, deposit(t, 7)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Rebuild(); err != nil {
+		t.Fatal(err)
+	}
+	if b.get("account-1") != 26 || b.get("account-2") != 7 {
+		t.Fatalf("unexpected balances after rebuild %v", b.values)
+	}
+	if v, _ := p.Checkpoint("account-2"); v != 1 {
+		t.Fatalf("expected checkpoint 1 got %d", v)
+	}
+}
+
+func TestRebuildTopics(t *testing.T) {
+	s := NewEventStore(Store(store.NewMemoryStore()), TopicFunc(func(e *Event) string {
+		return "events." + e.Type
+	}))
+
+	e, err := NewEvent("withdrawn", &deposited{5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append("account-1", 0, deposit(t, 1), e, deposit(t, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &balances{values: make(map[string]int)}
+	p := NewProjection("deposits", s, b.handle, Topics("events.deposited"))
+	if err := p.Rebuild(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the withdrawal is published to another topic
+	if v := b.get("account-1"); v != 3 {
+		t.Fatalf("expected balance 3 got %d", v)
+	}
+	if fmt.Sprint(b.seen) != "[1 3]" {
+		t.Fatalf("expected versions 1 and 3 to be handled got %v", b.seen)
+	}
+	if v, _ := p.Checkpoint("account-1"); v != 3 {
+		t.Fatalf("expected checkpoint 3 got %d", v)
+	}
+}
+
+// raceStore runs race before the first head is written
+type raceStore struct {
+	store.Store
+	once sync.Once
+	race func()
+}
+
+func (r *raceStore) Write(rec *store.Record, opts ...store.WriteOption) error {
+	if strings.HasPrefix(rec.Key, DefaultPrefix+"heads/") {
+		r.once.Do(r.race)
+	}
+	return r.Store.Write(rec, opts...)
+}
+
+func TestAppendInstances(t *testing.T) {
+	st := store.NewMemoryStore()
+	other := NewEventStore(Store(st))
+
+	// another instance appends while the events are written
+	var otherErr error
+	rs := &raceStore{Store: st, race: func() {
+		otherErr = other.Append("account-1", 0, deposit(t, 2))
+	}}
+	s := NewEventStore(Store(rs))
+
+	if err := s.Append("account-1", 0, deposit(t, 1)); err != ErrConcurrency {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+	if otherErr != nil {
+		t.Fatal(otherErr)
+	}
+
+	events, err := s.Load("account-1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d deposited
+	if len(events) != 1 || events[0].Decode(&d) != nil || d.Amount != 2 {
+		t.Fatalf("expected the event of the other instance, got %+v", events)
+	}
+}
@@ -0,0 +1,117 @@
+package eventsource
+
+import (
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/sync"
+)
+
+type Options struct {
+	// Store the events are saved in
+	Store store.Store
+	// Prefix of the keys in the store
+	Prefix string
+	// Sync locks streams across instances while appending. It's
+	// required when several instances append to the same streams.
+	// Without it streams are only locked within the process and
+	// Append reads the stream back to return ErrConcurrency when
+	// another instance wrote to it, which doesn't catch every race.
+	Sync sync.Sync
+	// Broker committed events are published to, none when not set
+	Broker broker.Broker
+	// Topic returns the topic an event is published to
+	Topic func(e *Event) string
+}
+
+type Option func(o *Options)
+
+// Store sets the store the events are saved in
+func Store(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// Prefix sets the prefix of the keys in the store
+func Prefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+// Sync sets the sync used to lock streams across instances. Set it
+// when several instances append to the same streams.
+func Sync(s sync.Sync) Option {
+	return func(o *Options) {
+		o.Sync = s
+	}
+}
+
+// Broker sets the broker committed events are published to
+func Broker(b broker.Broker) Option {
+	return func(o *Options) {
+		o.Broker = b
+	}
+}
+
+// Topic sets the topic events are published to
+func Topic(t string) Option {
+	return func(o *Options) {
+		o.Topic = func(*Event) string {
+			return t
+		}
+	}
+}
+
+// TopicFunc sets a func returning the topic each event is published to
+func TopicFunc(fn func(e *Event) string) Option {
+	return func(o *Options) {
+		o.Topic = fn
+	}
+}
+
+type RepositoryOptions struct {
+	// SnapshotEvery is the number of events between snapshots, none when zero
+	SnapshotEvery int64
+}
+
+type RepositoryOption func(o *RepositoryOptions)
+
+// SnapshotEvery snapshots aggregates every n events
+func SnapshotEvery(n int64) RepositoryOption {
+	return func(o *RepositoryOptions) {
+		o.SnapshotEvery = n
+	}
+}
+
+type ProjectionOptions struct {
+	// Topics the events are consumed from
+	Topics []string
+	// Queue the topic is subscribed with so replicas share the events
+	Queue string
+	// Reset is called before a rebuild to clear the projected state
+	Reset func() error
+}
+
+type ProjectionOption func(o *ProjectionOptions)
+
+// Topics sets the topics the events are consumed from
+func Topics(t ...string) ProjectionOption {
+	return func(o *ProjectionOptions) {
+		o.Topics = t
+	}
+}
+
+// Queue sets the queue the topic is subscribed with
+func Queue(q string) ProjectionOption {
+	return func(o *ProjectionOptions) {
+		o.Queue = q
+	}
+}
+
+// Reset sets a func called before a rebuild to clear the projected state
+func Reset(fn func() error) ProjectionOption {
+	return func(o *ProjectionOptions) {
+		o.Reset = fn
+	}
+}
@@ -0,0 +1,233 @@
+package eventsource
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	gosync "sync"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/util/keylock"
+)
+
+var (
+	// ErrNoBroker is returned when starting a projection of a store without a broker
+	ErrNoBroker = errors.New("event store has no broker")
+)
+
+// Handler projects an event
+type Handler func(e *Event) error
+
+// Projection consumes the published events of an event store. The version of
+// each stream it handled is checkpointed so duplicates are skipped and missed
+// events are read from the store, the events of a stream are handled in order.
+type Projection struct {
+	name    string
+	opts    ProjectionOptions
+	store   *EventStore
+	handler Handler
+
+	// held exclusively while rebuilding
+	gosync.RWMutex
+	locks keylock.Locks
+
+	subs []broker.Subscriber
+}
+
+// NewProjection returns a projection of the events of the store
+func NewProjection(name string, s *EventStore, h Handler, opts ...ProjectionOption) *Projection {
+	options := ProjectionOptions{
+		Topics: []string{DefaultTopic},
+		Queue:  name,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &Projection{
+		name:    name,
+		opts:    options,
+		store:   s,
+		handler: h,
+	}
+}
+
+// Start subscribes to the topics of the projection
+func (p *Projection) Start() error {
+	b := p.store.opts.Broker
+	if b == nil {
+		return ErrNoBroker
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if len(p.subs) > 0 {
+		return nil
+	}
+
+	for _, topic := range p.opts.Topics {
+		sub, err := b.Subscribe(topic, p.consume, broker.Queue(p.opts.Queue), broker.DisableAutoAck())
+		if err != nil {
+			for _, s := range p.subs {
+				s.Unsubscribe()
+			}
+			p.subs = nil
+			return err
+		}
+		p.subs = append(p.subs, sub)
+	}
+
+	return nil
+}
+
+// Stop unsubscribes from the topics of the projection
+func (p *Projection) Stop() error {
+	p.Lock()
+	defer p.Unlock()
+
+	var err error
+	for _, s := range p.subs {
+		if e := s.Unsubscribe(); e != nil {
+			err = e
+		}
+	}
+	p.subs = nil
+	return err
+}
+
+// Rebuild clears the checkpoints and projects every stream of the store from
+// the beginning. Only the events published to the topics of the projection are
+// handled. Published events wait until the rebuild is done.
+func (p *Projection) Rebuild() error {
+	p.Lock()
+	defer p.Unlock()
+
+	prefix := p.checkpointKey("")
+	keys, err := p.store.opts.Store.List(store.ListPrefix(prefix))
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if err := p.store.opts.Store.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	if p.opts.Reset != nil {
+		if err := p.opts.Reset(); err != nil {
+			return err
+		}
+	}
+
+	streams, err := p.store.Streams()
+	if err != nil {
+		return err
+	}
+	for _, stream := range streams {
+		events, err := p.store.Load(stream, 0)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := p.apply(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checkpoint returns the version of the stream the projection handled
+func (p *Projection) Checkpoint(stream string) (int64, error) {
+	recs, err := p.store.opts.Store.Read(p.checkpointKey(stream))
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(recs[0].Value), 10, 64)
+}
+
+func (p *Projection) consume(ev broker.Event) error {
+	e := new(Event)
+	if err := json.Unmarshal(ev.Message().Body, e); err != nil {
+		return err
+	}
+	if err := p.handle(e); err != nil {
+		// not acked so it's redelivered
+		return err
+	}
+	return ev.Ack()
+}
+
+func (p *Projection) handle(e *Event) error {
+	p.RLock()
+	defer p.RUnlock()
+
+	p.locks.Lock(e.Stream)
+	defer p.locks.Unlock(e.Stream)
+
+	version, err := p.Checkpoint(e.Stream)
+	if err != nil {
+		return err
+	}
+
+	// already handled
+	if e.Version <= version {
+		return nil
+	}
+
+	// events were missed so read them from the store
+	if e.Version > version+1 {
+		events, err := p.store.Load(e.Stream, version)
+		if err != nil {
+			return err
+		}
+		for _, m := range events {
+			if m.Version >= e.Version {
+				break
+			}
+			if err := p.apply(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return p.apply(e)
+}
+
+// apply handles the event and checkpoints it. Events read from the store which
+// are published to other topics are only checkpointed.
+func (p *Projection) apply(e *Event) error {
+	if p.consumes(e) {
+		if err := p.handler(e); err != nil {
+			return err
+		}
+	}
+	return p.store.opts.Store.Write(&store.Record{
+		Key:   p.checkpointKey(e.Stream),
+		Value: []byte(strconv.FormatInt(e.Version, 10)),
+	})
+}
+
+// consumes returns true if the event is published to a topic of the projection
+func (p *Projection) consumes(e *Event) bool {
+	topic := p.store.opts.Topic(e)
+	for _, t := range p.opts.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Projection) checkpointKey(stream string) string {
+	return p.store.opts.Prefix + "checkpoints/" + p.name + "/" + stream
+}
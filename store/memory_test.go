@@ -0,0 +1,24 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestMemoryDelete(t *testing.T) {
+	s := NewMemoryStore()
+
+	if err := s.Write(&Record{Key: "foo", Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Read("foo"); err != ErrNotFound {
+		t.Fatalf("expected not found got %v", err)
+	}
+
+	// deleting a missing key isn't an error
+	if err := s.Delete("foo"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,281 @@
+// Package cloudevents encodes messages as CloudEvents 1.0.
+//
+// In binary mode the event attributes are sent as headers prefixed with ce-
+// and the body is the data. In structured mode the body is the JSON event
+// with the data inside and the content type is application/cloudevents+json.
+package cloudevents
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Mode is how an event is encoded in a message
+type Mode int
+
+const (
+	// None sends messages as they are
+	None Mode = iota
+	// Binary sends the attributes in the headers
+	Binary
+	// Structured sends the whole event in the body
+	Structured
+)
+
+const (
+	SpecVersion = "1.0"
+	// ContentType of events in structured mode
+	ContentType = "application/cloudevents+json"
+	// HeaderPrefix of attributes in binary mode
+	HeaderPrefix = "ce-"
+)
+
+var (
+	ErrInvalid = errors.New("invalid cloud event")
+)
+
+// Event is the envelope of a message
+type Event struct {
+	ID              string
+	Source          string
+	SpecVersion     string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	DataSchema      string
+	// Extensions are any other attributes
+	Extensions map[string]string
+}
+
+func (e *Event) attributes() map[string]string {
+	attrs := make(map[string]string, len(e.Extensions)+8)
+	for k, v := range e.Extensions {
+		attrs[strings.ToLower(k)] = v
+	}
+
+	set := func(k, v string) {
+		if len(v) > 0 {
+			attrs[k] = v
+		}
+	}
+	set("id", e.ID)
+	set("source", e.Source)
+	set("specversion", e.SpecVersion)
+	set("type", e.Type)
+	set("subject", e.Subject)
+	set("datacontenttype", e.DataContentType)
+	set("dataschema", e.DataSchema)
+	if !e.Time.IsZero() {
+		attrs["time"] = e.Time.UTC().Format(time.RFC3339Nano)
+	}
+
+	return attrs
+}
+
+func (e *Event) set(k, v string) error {
+	switch k {
+	case "id":
+		e.ID = v
+	case "source":
+		e.Source = v
+	case "specversion":
+		e.SpecVersion = v
+	case "type":
+		e.Type = v
+	case "subject":
+		e.Subject = v
+	case "datacontenttype":
+		e.DataContentType = v
+	case "dataschema":
+		e.DataSchema = v
+	case "time":
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return err
+		}
+		e.Time = t
+	default:
+		if e.Extensions == nil {
+			e.Extensions = make(map[string]string)
+		}
+		e.Extensions[k] = v
+	}
+	return nil
+}
+
+func (e *Event) validate() error {
+	if len(e.ID) == 0 || len(e.Source) == 0 || len(e.Type) == 0 || len(e.SpecVersion) == 0 {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// isJSON returns true if the data is sent as JSON in structured mode
+func isJSON(ct string) bool {
+	ct = strings.TrimSpace(strings.Split(ct, ";")[0])
+	return len(ct) == 0 || ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
+
+// Marshal encodes the event with its data in the header and body of a message.
+// The header is copied and its Content-Type is used if DataContentType isn't set.
+func (e *Event) Marshal(mode Mode, header map[string]string, data []byte) (map[string]string, []byte, error) {
+	hdr := make(map[string]string, len(header)+8)
+	for k, v := range header {
+		hdr[k] = v
+	}
+
+	ev := *e
+	if len(ev.SpecVersion) == 0 {
+		ev.SpecVersion = SpecVersion
+	}
+	if len(ev.DataContentType) == 0 {
+		ev.DataContentType = hdr["Content-Type"]
+	}
+	if err := ev.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	switch mode {
+	case Binary:
+		for k, v := range ev.attributes() {
+			if k == "datacontenttype" {
+				hdr["Content-Type"] = v
+				continue
+			}
+			hdr[HeaderPrefix+k] = v
+		}
+		return hdr, data, nil
+	case Structured:
+		body := make(map[string]interface{})
+		for k, v := range ev.attributes() {
+			body[k] = v
+		}
+		if len(data) > 0 {
+			if isJSON(ev.DataContentType) && json.Valid(data) {
+				body["data"] = json.RawMessage(data)
+			} else {
+				body["data_base64"] = base64.StdEncoding.EncodeToString(data)
+			}
+		}
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		hdr["Content-Type"] = ContentType
+		return hdr, b, nil
+	}
+
+	return hdr, data, nil
+}
+
+// Unmarshal decodes the event in a message. It returns a nil event if the
+// message isn't one, otherwise the header and body of the data it contains.
+func Unmarshal(header map[string]string, body []byte) (*Event, map[string]string, []byte, error) {
+	ct := header["Content-Type"]
+
+	// structured mode
+	if strings.HasPrefix(ct, ContentType) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil, nil, nil, err
+		}
+
+		e := new(Event)
+		var data []byte
+
+		for k, v := range fields {
+			switch k {
+			case "data":
+				data = []byte(v)
+				// data which isn't json is a json string
+				var s string
+				if !isJSON(contentType(fields)) && json.Unmarshal(v, &s) == nil {
+					data = []byte(s)
+				}
+			case "data_base64":
+				var s string
+				if err := json.Unmarshal(v, &s); err != nil {
+					return nil, nil, nil, err
+				}
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				data = b
+			default:
+				var s string
+				if err := json.Unmarshal(v, &s); err != nil {
+					// extensions may be numbers or booleans
+					s = string(v)
+				}
+				if err := e.set(k, s); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+		}
+
+		if err := e.validate(); err != nil {
+			return nil, nil, nil, err
+		}
+
+		hdr := make(map[string]string, len(header))
+		for k, v := range header {
+			hdr[k] = v
+		}
+		hdr["Content-Type"] = e.DataContentType
+		if len(e.DataContentType) == 0 {
+			hdr["Content-Type"] = "application/json"
+		}
+
+		return e, hdr, data, nil
+	}
+
+	// binary mode
+	var e *Event
+	for k, v := range header {
+		if len(k) <= len(HeaderPrefix) || !strings.EqualFold(k[:len(HeaderPrefix)], HeaderPrefix) {
+			continue
+		}
+		if e == nil {
+			e = new(Event)
+		}
+		if err := e.set(strings.ToLower(k[len(HeaderPrefix):]), v); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if e == nil {
+		return nil, header, body, nil
+	}
+
+	e.DataContentType = ct
+	if err := e.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return e, header, body, nil
+}
+
+func contentType(fields map[string]json.RawMessage) string {
+	var ct string
+	json.Unmarshal(fields["datacontenttype"], &ct)
+	return ct
+}
+
+type eventKey struct{}
+
+// NewContext returns a context carrying the event
+func NewContext(ctx context.Context, e *Event) context.Context {
+	return context.WithValue(ctx, eventKey{}, e)
+}
+
+// FromContext returns the event a message was received in
+func FromContext(ctx context.Context) (*Event, bool) {
+	e, ok := ctx.Value(eventKey{}).(*Event)
+	return e, ok
+}
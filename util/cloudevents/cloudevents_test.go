@@ -0,0 +1,149 @@
+package cloudevents_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/transport"
+	"github.com/asim/go-micro/v3/util/cloudevents"
+)
+
+func TestMarshal(t *testing.T) {
+	ev := &cloudevents.Event{
+		ID:         "1",
+		Source:     "greeter",
+		Type:       "greetings",
+		Time:       time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		Extensions: map[string]string{"traceparent": "00-1"},
+	}
+
+	testData := []struct {
+		mode        cloudevents.Mode
+		contentType string
+		data        []byte
+	}{
+		{cloudevents.Binary, "application/json", []byte(`{"name":"john"}`)},
+		{cloudevents.Structured, "application/json", []byte(`{"name":"john"}`)},
+		{cloudevents.Structured, "application/protobuf", []byte{0x0a, 0x04, 'j', 'o', 'h', 'n'}},
+	}
+
+	for _, d := range testData {
+		header := map[string]string{"Content-Type": d.contentType, "Micro-Topic": "greetings"}
+
+		hdr, body, err := ev.Marshal(d.mode, header, d.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.mode == cloudevents.Structured && hdr["Content-Type"] != cloudevents.ContentType {
+			t.Fatalf("expected the structured content type got %s", hdr["Content-Type"])
+		}
+
+		got, hdr, data, err := cloudevents.Unmarshal(hdr, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil {
+			t.Fatal("expected an event")
+		}
+		if got.ID != ev.ID || got.Source != ev.Source || got.Type != ev.Type || !got.Time.Equal(ev.Time) {
+			t.Fatalf("expected %+v got %+v", ev, got)
+		}
+		if got.SpecVersion != cloudevents.SpecVersion || got.DataContentType != d.contentType {
+			t.Fatalf("unexpected spec version or content type %+v", got)
+		}
+		if got.Extensions["traceparent"] != "00-1" {
+			t.Fatalf("expected the extension got %v", got.Extensions)
+		}
+		if hdr["Content-Type"] != d.contentType || hdr["Micro-Topic"] != "greetings" {
+			t.Fatalf("expected the data headers got %v", hdr)
+		}
+		if string(data) != string(d.data) {
+			t.Fatalf("expected data %q got %q", d.data, data)
+		}
+	}
+
+	// other messages pass through
+	got, _, body, err := cloudevents.Unmarshal(map[string]string{"Content-Type": "application/json"}, []byte(`{}`))
+	if err != nil || got != nil || string(body) != `{}` {
+		t.Fatalf("expected a plain message got %v %v", got, err)
+	}
+
+	// required attributes
+	if _, _, _, err := cloudevents.Unmarshal(map[string]string{"ce-id": "1"}, nil); err != cloudevents.ErrInvalid {
+		t.Fatalf("expected an invalid event got %v", err)
+	}
+}
+
+type Greeting struct {
+	Name string `json:"name"`
+}
+
+type received struct {
+	event    *cloudevents.Event
+	greeting *Greeting
+	topic    string
+}
+
+func TestPublish(t *testing.T) {
+	r := registry.NewMemoryRegistry()
+	b := broker.NewBroker(broker.Registry(r))
+
+	ch := make(chan received, 2)
+
+	srv := server.NewServer(
+		server.Name("subscriber"),
+		server.Registry(r),
+		server.Broker(b),
+		server.Transport(transport.NewMemoryTransport()),
+	)
+	if err := srv.Subscribe(srv.NewSubscriber("greetings", func(ctx context.Context, g *Greeting) error {
+		ev, _ := cloudevents.FromContext(ctx)
+		topic, _ := metadata.Get(ctx, "Micro-Topic")
+		ch <- received{ev, g, topic}
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	c := client.NewClient(
+		client.Broker(b),
+		client.Registry(r),
+		client.ContentType("application/json"),
+		client.CloudEvents(cloudevents.Binary, "greeter"),
+	)
+
+	msg := c.NewMessage("greetings", &Greeting{Name: "john"})
+	if err := c.Publish(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Publish(context.Background(), msg, client.WithCloudEvents(cloudevents.Structured)); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case rcv := <-ch:
+			if rcv.event == nil {
+				t.Fatal("expected the event envelope")
+			}
+			if rcv.event.Source != "greeter" || rcv.event.Type != "greetings" || len(rcv.event.ID) == 0 || rcv.event.Time.IsZero() {
+				t.Fatalf("unexpected event %+v", rcv.event)
+			}
+			if rcv.greeting.Name != "john" || rcv.topic != "greetings" {
+				t.Fatalf("unexpected message %+v on %s", rcv.greeting, rcv.topic)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected the event")
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package keylock provides locks held by one caller per key at a time
+package keylock
+
+import (
+	"sync"
+)
+
+// Locks allows one holder of each key at a time. Locks are only kept
+// while they're held or waited for. The zero value is ready to use.
+type Locks struct {
+	sync.Mutex
+	locks map[string]*lock
+}
+
+type lock struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock waits until the key is free and takes it
+func (k *Locks) Lock(key string) {
+	k.Mutex.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*lock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = new(lock)
+		k.locks[key] = l
+	}
+	l.refs++
+	k.Mutex.Unlock()
+
+	l.Lock()
+}
+
+// Unlock releases the key
+func (k *Locks) Unlock(key string) {
+	k.Mutex.Lock()
+	l := k.locks[key]
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+	k.Mutex.Unlock()
+
+	l.Unlock()
+}
@@ -0,0 +1,39 @@
+package keylock
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLocks(t *testing.T) {
+	var k Locks
+	var wg sync.WaitGroup
+	held := make(map[string]bool)
+	var mtx sync.Mutex
+
+	for i := 0; i < 20; i++ {
+		key := []string{"a", "b"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k.Lock(key)
+			defer k.Unlock(key)
+
+			mtx.Lock()
+			if held[key] {
+				t.Errorf("key %s held twice", key)
+			}
+			held[key] = true
+			mtx.Unlock()
+
+			mtx.Lock()
+			held[key] = false
+			mtx.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(k.locks) != 0 {
+		t.Fatalf("expected released locks to be removed, got %d", len(k.locks))
+	}
+}